@@ -39,10 +39,11 @@ func init() {
 		Long:  `Guides you through the process of creating a new D&D character.`,
 		Args:  cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
+			store := storeFrom(cmd)
 			charName := args[0]
 
 			// Check if data is loaded
-			if len(data.AllSpecies) == 0 || len(data.AllClasses) == 0 || len(data.AllBackgrounds) == 0 {
+			if len(store.Species()) == 0 || len(store.Classes()) == 0 || len(store.Backgrounds()) == 0 {
 				fmt.Printf("Hark! The ancient tomes are not loaded. Ensure the data submodule is initialized: git submodule update --init --recursive\n")
 				return
 			}
@@ -61,31 +62,31 @@ func init() {
 			reader := bufio.NewReader(os.Stdin)
 
 			// Species selection
-			fmt.Printf("Choose a species (e.g., Human, Elf, Dwarf). Available: %s\n", getSpeciesNames())
+			fmt.Printf("Choose a species (e.g., Human, Elf, Dwarf). Available: %s\n", getSpeciesNames(store))
 			fmt.Print("Enter Species: ")
 			speciesInput, _ := reader.ReadString('\n')
 			speciesInput = strings.TrimSpace(speciesInput)
-			if _, err := data.GetSpeciesByName(speciesInput); err != nil {
+			if _, err := store.GetSpeciesByName(speciesInput); err != nil {
 				fmt.Printf("Hark! That species is unknown to these lands. %v\n", err)
 				return
 			}
 
 			// Class selection
-			fmt.Printf("Choose a class (e.g., Fighter, Wizard, Rogue). Available: %s\n", getClassNames())
+			fmt.Printf("Choose a class (e.g., Fighter, Wizard, Rogue). Available: %s\n", getClassNames(store))
 			fmt.Print("Enter Class: ")
 			classInput, _ := reader.ReadString('\n')
 			classInput = strings.TrimSpace(classInput)
-			if _, err := data.GetClassByName(classInput); err != nil {
+			if _, err := store.GetClassByName(classInput); err != nil {
 				fmt.Printf("Hark! That class is not in our teachings. %v\n", err)
 				return
 			}
 
 			// Background selection
-			fmt.Printf("Choose a background (e.g., Acolyte, Soldier, Criminal). Available: %s\n", getBackgroundNames())
+			fmt.Printf("Choose a background (e.g., Acolyte, Soldier, Criminal). Available: %s\n", getBackgroundNames(store))
 			fmt.Print("Enter Background: ")
 			backgroundInput, _ := reader.ReadString('\n')
 			backgroundInput = strings.TrimSpace(backgroundInput)
-			if _, err := data.GetBackgroundByName(backgroundInput); err != nil {
+			if _, err := store.GetBackgroundByName(backgroundInput); err != nil {
 				fmt.Printf("Hark! That background is not etched in our lore. %v\n", err)
 				return
 			}
@@ -110,6 +111,7 @@ func init() {
 		},
 	}
 	charCmd.AddCommand(createCharCmd)
+	needsData(createCharCmd)
 
 	// Add 'view' subcommand
 	var viewCharCmd = &cobra.Command{
@@ -205,12 +207,17 @@ func init() {
 	charCmd.AddCommand(viewCharCmd)
 
 	// Add 'levelup' subcommand
+	var subclassName string
 	var levelUpCharCmd = &cobra.Command{
 		Use:   "levelup [name]",
 		Short: "Level up a D&D character",
-		Long:  `Increases the level of a saved D&D character and updates basic stats.`,
-		Args:  cobra.ExactArgs(1),
+		Long: `Increases the level of a saved D&D character and updates basic stats.
+Use --subclass to choose the character's subclass from the loaded data,
+homebrew included. The character gains the features its subclass grants
+up to the new level.`,
+		Args: cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
+			store := storeFrom(cmd)
 			charName := args[0]
 
 			charFilePath, err := character.GetCharacterFilePath(charName)
@@ -225,8 +232,35 @@ func init() {
 				return
 			}
 
+			var subclass *data.Subclass
+			if subclassName != "" {
+				subclass, err = store.GetSubclassByName(subclassName)
+				if err != nil {
+					fmt.Printf("Hark! That subclass is not in our teachings. %v\n", err)
+					return
+				}
+				if !strings.EqualFold(subclass.Class, char.Class) {
+					fmt.Printf("Hark! %s is a path for the %s, not the %s. Available: %s\n", subclass.Name, subclass.Class, char.Class, getSubclassNames(store, char.Class))
+					return
+				}
+			}
+
 			fmt.Printf("\nVerily! '%s' is now level %d.\n", char.Name, char.Level+1)
 			char.LevelUp()
+			if subclass != nil {
+				char.Subclass = subclass.Name
+				fmt.Printf("'%s' follows the path of the %s.\n", char.Name, subclass.Name)
+			} else if s, err := store.GetSubclassByName(char.Subclass); err == nil && strings.EqualFold(s.Class, char.Class) {
+				subclass = s
+			}
+			if subclass != nil {
+				for _, feature := range subclass.FeaturesThrough(char.Level) {
+					if !hasFeature(char, feature.Name) {
+						char.Features = append(char.Features, feature.Name)
+						fmt.Printf("'%s' gains %s.\n", char.Name, feature.Name)
+					}
+				}
+			}
 
 			err = character.SaveCharacter(char, charFilePath)
 			if err != nil {
@@ -238,6 +272,8 @@ func init() {
 		},
 	}
 	charCmd.AddCommand(levelUpCharCmd)
+	needsData(levelUpCharCmd)
+	levelUpCharCmd.Flags().StringVar(&subclassName, "subclass", "", "Choose the character's subclass")
 
 	// Add 'hp' subcommand
 	var hpCmd = &cobra.Command{
@@ -512,28 +548,50 @@ func init() {
 }
 
 // Helper to get available species names
-func getSpeciesNames() string {
-	names := make([]string, len(data.AllSpecies))
-	for i, s := range data.AllSpecies {
+func getSpeciesNames(store *data.Store) string {
+	species := store.Species()
+	names := make([]string, len(species))
+	for i, s := range species {
 		names[i] = s.Name
 	}
 	return strings.Join(names, ", ")
 }
 
 // Helper to get available background names
-func getBackgroundNames() string {
-	names := make([]string, len(data.AllBackgrounds))
-	for i, b := range data.AllBackgrounds {
+func getBackgroundNames(store *data.Store) string {
+	backgrounds := store.Backgrounds()
+	names := make([]string, len(backgrounds))
+	for i, b := range backgrounds {
 		names[i] = b.Name
 	}
 	return strings.Join(names, ", ")
 }
 
 // Helper to get available class names
-func getClassNames() string {
-	names := make([]string, len(data.AllClasses))
-	for i, c := range data.AllClasses {
+func getClassNames(store *data.Store) string {
+	classes := store.Classes()
+	names := make([]string, len(classes))
+	for i, c := range classes {
 		names[i] = c.Name
 	}
 	return strings.Join(names, ", ")
 }
+
+// hasFeature reports whether the character has the named feature.
+func hasFeature(char *character.Character, name string) bool {
+	for _, f := range char.Features {
+		if strings.EqualFold(f, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func getSubclassNames(store *data.Store, class string) string {
+	subclasses := store.SubclassesOf(class)
+	names := make([]string, len(subclasses))
+	for i, s := range subclasses {
+		names[i] = s.Name
+	}
+	return strings.Join(names, ", ")
+}
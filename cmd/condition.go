@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// conditionCmd represents the condition command
+var conditionCmd = &cobra.Command{
+	Use:   "condition [condition name]",
+	Short: "Looks up details for a D&D condition",
+	Long: `Provides detailed information about a specified D&D condition: its
+effects as written and a summary of its mechanics, such as a speed of 0,
+advantage or disadvantage on attack rolls and saving throws it fails.
+Without a name, lists every condition.
+
+Examples:
+  dnd condition Grappled
+  dnd condition`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		store := storeFrom(cmd)
+		if len(args) == 0 {
+			fmt.Printf("\n--- Conditions ---\n")
+			for _, condition := range store.Conditions() {
+				fmt.Printf("%s: %s\n", condition.Name, condition.Description)
+			}
+			fmt.Print("------------------\n")
+			return
+		}
+		conditionName := strings.Join(args, " ")
+
+		condition, err := store.GetConditionByName(conditionName)
+		if name, ok := closestName(err); ok {
+			condition, err = store.GetConditionByName(name)
+		}
+		if err != nil {
+			printLookupError(err)
+			return
+		}
+
+		fmt.Printf("\n--- %s ---\n", condition.Name)
+		fmt.Printf("%s\n", condition.Card())
+		fmt.Print("-------------------\n")
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(conditionCmd)
+	needsData(conditionCmd)
+	conditionCmd.Flags().BoolVar(&closest, "closest", false, "Show the closest match when the name has a small typo")
+}
@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"dnd-cli/internal/data"
+
+	"github.com/spf13/cobra"
+)
+
+// dataCmd represents the data command
+var dataCmd = &cobra.Command{
+	Use:   "data",
+	Short: "Inspects the D&D data the other commands use",
+	Long: `Commands for inspecting the loaded D&D data. A starter set of SRD 5.1
+content is built into dnd (source "srd"); the files of a data directory
+(source "core", see --data-dir) are layered on top of it, and homebrew
+overlays (see --homebrew) on top of both. Each layer replaces entries of
+the same name from the layers below it.`,
+}
+
+var dataInfoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "Shows where each category of data came from",
+	Long: `Shows the data directory in use and, for each category, how many entries
+were loaded and which sources they came from, with their versions. Later
+sources are layered over earlier ones.
+
+Examples:
+  dnd data info
+  dnd data info --data-dir ~/srd
+  dnd data info --homebrew ~/campaign/homebrew --disable-source srd`,
+	Run: func(cmd *cobra.Command, args []string) {
+		store := storeFrom(cmd)
+		embedded := data.EmbeddedManifest()
+		fmt.Printf("\n--- Data ---\n")
+		fmt.Printf("Embedded: %s %s\n", embedded.Name, embedded.Version)
+		if dataDirSource.Path == "" {
+			fmt.Printf("Directory: none; using the embedded data alone\n")
+		} else {
+			fmt.Printf("Directory: %s (from %s)\n", dataDirSource.Path, dataDirSource.Name)
+		}
+		fmt.Println()
+		for _, info := range store.Loaded() {
+			fmt.Printf("%-12s %5d\n", info.Kind.Title(), info.Count)
+			for _, source := range info.Sources {
+				version := source.Version
+				if version == "" {
+					version = "unversioned"
+				}
+				where := source.Tag
+				if source.Path != "" {
+					where += " " + source.Path
+				}
+				fmt.Printf("    %s (%s): %d\n", where, version, source.Entries)
+			}
+		}
+		fmt.Printf("------------\n")
+	},
+}
+
+// dataStrict is the --strict flag of data validate.
+var dataStrict bool
+
+var dataValidateCmd = &cobra.Command{
+	Use:   "validate [dir]",
+	Short: "Checks content files for mistakes",
+	Long: `Checks every content file for entries that don't parse or decode, missing
+required fields, unknown fields, names defined twice, entries overriding
+another source's and references to spells, classes and conditions that no
+source defines. Each problem is reported with its file, line and entry.
+
+Without a directory, the data directory and homebrew overlays in use are
+checked. With one, a complete data directory, holding every category's
+data file, is checked on its own; any other directory is checked as an
+overlay over the built-in data, as a homebrew repository's CI would.
+
+Exits with status 1 if there are errors, or with --strict warnings.
+
+Examples:
+  dnd data validate
+  dnd data validate ~/campaign/homebrew --strict`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var report *data.Report
+		var err error
+		if len(args) == 1 {
+			report, err = data.ValidateDir(args[0])
+		} else {
+			var opts data.LoadOptions
+			if opts, err = loadOptions(); err == nil {
+				report, err = data.Validate(opts)
+			}
+		}
+		if err != nil {
+			fmt.Printf("Hark! The ancient scrolls of knowledge are sealed! %v\n", err)
+			os.Exit(1)
+		}
+
+		for _, p := range report.Problems {
+			fmt.Println(p)
+		}
+		errs, warnings := report.Count(data.Error), report.Count(data.Warning)
+		if errs == 0 && warnings == 0 {
+			fmt.Printf("Verily! All %d entries in %d files are in good order.\n", report.Entries, report.Files)
+			return
+		}
+		fmt.Printf("Checked %d entries in %d files: %s, %s.\n", report.Entries, report.Files, plural(errs, "error"), plural(warnings, "warning"))
+		if errs > 0 || (dataStrict && warnings > 0) {
+			os.Exit(1)
+		}
+	},
+}
+
+// Flags of data import.
+var (
+	importFormat string
+	importKind   string
+	importOut    string
+)
+
+var dataImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Converts an Open5e or 5e-SRD-API dump into homebrew content",
+	Long: `Converts spells, monsters and items from a JSON dump of the Open5e API
+(--format open5e) or of the 5e-SRD-API and its 5e-database (--format
+5e-srd) into dnd's own format, and saves them into a homebrew overlay as
+spells/<file>.json, monsters/<file>.json and items/<file>.json. The dump
+may be a list of entries, a single entry or an API page with its entries
+under "results". Each entry's kind is worked out from its fields unless
+--kind is given.
+
+Fields dnd has no place for are left out and listed in a report, with how
+many entries had them. Ids, links and page numbers are left out silently.
+
+The overlay defaults to the homebrew overlay with the highest precedence;
+without one, --out is required.
+
+Examples:
+  dnd data import --format open5e spells.json
+  dnd data import --format 5e-srd 5e-SRD-Monsters.json --out ~/campaign/homebrew`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		out := importOut
+		if out == "" {
+			opts, err := loadOptions()
+			if err != nil {
+				fmt.Printf("Hark! The ancient scrolls of knowledge are sealed! %v\n", err)
+				os.Exit(1)
+			}
+			overlays := opts.Overlays
+			sort.SliceStable(overlays, func(i, j int) bool { return overlays[i].Priority < overlays[j].Priority })
+			if len(overlays) == 0 {
+				fmt.Printf("Hark! Whither shall the scrolls be stored? Name a homebrew directory with --out.\n")
+				os.Exit(1)
+			}
+			out = overlays[len(overlays)-1].Path
+		}
+
+		raw, err := os.ReadFile(args[0])
+		if err != nil {
+			fmt.Printf("Hark! The scroll cannot be read! %v\n", err)
+			os.Exit(1)
+		}
+		imported, err := data.Import(data.ImportFormat(strings.ToLower(importFormat)), raw, data.Kind(strings.ToLower(importKind)))
+		if err != nil {
+			fmt.Printf("Hark! The scroll is writ in a tongue I cannot fathom! %v\n", err)
+			os.Exit(1)
+		}
+		name := strings.TrimSuffix(filepath.Base(args[0]), filepath.Ext(args[0]))
+		written, err := imported.Save(out, name)
+		if err != nil {
+			fmt.Printf("Hark! The scrolls could not be stored! %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("\n--- Import ---\n")
+		fmt.Printf("Spells:   %d\n", len(imported.Spells))
+		fmt.Printf("Monsters: %d\n", len(imported.Monsters))
+		fmt.Printf("Items:    %d\n", len(imported.Items))
+		for _, path := range written {
+			fmt.Printf("Wrote %s\n", path)
+		}
+		for _, kind := range data.Kinds {
+			if skipped := imported.SkippedFields(kind); len(skipped) > 0 {
+				fmt.Printf("\nLeft out of %s entries:\n", kind)
+				for _, field := range skipped {
+					fmt.Printf("    %s\n", field)
+				}
+			}
+		}
+		if len(imported.Failed) > 0 {
+			fmt.Printf("\nNot imported:\n")
+			for _, failure := range imported.Failed {
+				fmt.Printf("    %s\n", failure)
+			}
+		}
+		fmt.Printf("--------------\n")
+		if len(written) > 0 {
+			fmt.Printf("Verily! Check the new scrolls with: dnd data validate %s\n", out)
+		}
+	},
+}
+
+// plural renders a count of things, e.g. "1 error" or "2 errors".
+func plural(n int, thing string) string {
+	if n == 1 {
+		return "1 " + thing
+	}
+	return fmt.Sprintf("%d %ss", n, thing)
+}
+
+func init() {
+	RootCmd.AddCommand(dataCmd)
+	dataCmd.AddCommand(dataInfoCmd)
+	needsData(dataInfoCmd)
+	dataCmd.AddCommand(dataValidateCmd)
+	dataValidateCmd.Flags().BoolVar(&dataStrict, "strict", false, "Fail on warnings too")
+	dataCmd.AddCommand(dataImportCmd)
+	dataImportCmd.Flags().StringVar(&importFormat, "format", "", "Format of the dump: open5e or 5e-srd")
+	dataImportCmd.Flags().StringVar(&importKind, "kind", "", "Import every entry as one kind: spell, monster or item")
+	dataImportCmd.Flags().StringVar(&importOut, "out", "", "Homebrew directory to save into (default: the homebrew overlay in use)")
+	dataImportCmd.MarkFlagRequired("format")
+}
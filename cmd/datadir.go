@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"dnd-cli/internal/config"
+	"dnd-cli/internal/data"
+
+	"github.com/spf13/cobra"
+)
+
+// Values of the global data flags.
+var (
+	dataDir         string   // --data-dir
+	homebrewDirs    []string // --homebrew
+	disabledSources []string // --disable-source
+)
+
+// needsDataAnnotation marks the commands that load the D&D data before
+// they run. Subcommands of a marked command need it too.
+const needsDataAnnotation = "needs-data"
+
+// needsData marks cmd as one that needs the D&D data.
+func needsData(cmd *cobra.Command) {
+	if cmd.Annotations == nil {
+		cmd.Annotations = make(map[string]string)
+	}
+	cmd.Annotations[needsDataAnnotation] = "true"
+}
+
+// requiresData reports whether cmd or one of its parents needs the D&D data.
+func requiresData(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Annotations[needsDataAnnotation] == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+// dataDirSource is where the loaded data directory was found. Its Path is
+// empty when only the embedded data is loaded.
+var dataDirSource data.DataDirSource
+
+// loadData finds the data directory and loads the D&D data into
+// store, layering the directory over the embedded data and the homebrew
+// overlays over both.
+func loadData(store *data.Store) error {
+	opts, err := loadOptions()
+	if err != nil {
+		return err
+	}
+	return store.Load(opts)
+}
+
+// loadOptions gathers the data sources from the flags and config. Without
+// a data directory the embedded data is used alone. Overlays given with
+// --homebrew take precedence over the configured ones, and sources
+// disabled by flag or config are left out.
+func loadOptions() (data.LoadOptions, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return data.LoadOptions{}, err
+	}
+	source, err := data.ResolveDataDir(data.DataDirSources(dataDir, cfg.DataDir))
+	if err != nil && !errors.Is(err, data.ErrNoDataDir) {
+		return data.LoadOptions{}, err
+	}
+	dataDirSource = source
+
+	overlays := append([]data.Overlay(nil), cfg.Homebrew...)
+	top := 0
+	for _, o := range overlays {
+		top = max(top, o.Priority)
+	}
+	for _, dir := range homebrewDirs {
+		overlays = append(overlays, data.Overlay{Path: dir, Priority: top})
+	}
+	return data.LoadOptions{
+		DataDir:  source.Path,
+		Overlays: overlays,
+		Disabled: append(append([]string(nil), cfg.DisabledSources...), disabledSources...),
+		CacheDir: data.DefaultCacheDir(),
+	}, nil
+}
+
+// printLoadStats reports, on stderr, how long each step of loading the
+// data took, for --verbose.
+func printLoadStats(stats data.LoadStats) {
+	cache := string(stats.Cache)
+	if stats.CachePath != "" {
+		cache += ", " + stats.CachePath
+	}
+	fmt.Fprintf(os.Stderr, "Loaded the D&D data in %v (cache %s)\n", stats.Total.Round(time.Microsecond), cache)
+	steps := []struct {
+		name string
+		took time.Duration
+	}{
+		{"checking sources", stats.Check},
+		{"reading cache", stats.Read},
+		{"parsing files", stats.Parse},
+		{"building indexes", stats.Index},
+		{"writing cache", stats.Write},
+	}
+	for _, step := range steps {
+		fmt.Fprintf(os.Stderr, "  %-17s %v\n", step.name, step.took.Round(time.Microsecond))
+	}
+	if stats.CacheErr != nil {
+		fmt.Fprintf(os.Stderr, "Hark! The cache could not be used: %v\n", stats.CacheErr)
+	}
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// featCmd represents the feat command
+var featCmd = &cobra.Command{
+	Use:   "feat [feat name]",
+	Short: "Looks up details for a D&D feat",
+	Long: `Provides detailed information about a specified D&D feat: its
+prerequisites, description and benefits. Without a name, lists every
+feat with its prerequisites.
+
+Examples:
+  dnd feat Grappler
+  dnd feat`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		store := storeFrom(cmd)
+		if len(args) == 0 {
+			fmt.Printf("\n--- Feats ---\n")
+			feats := store.Feats()
+			for i := range feats {
+				feat := &feats[i]
+				fmt.Printf("%s%s\n", feat.Name, parenthesised(feat.PrerequisiteText()))
+			}
+			fmt.Print("-------------\n")
+			return
+		}
+		featName := strings.Join(args, " ")
+
+		feat, err := store.GetFeatByName(featName)
+		if name, ok := closestName(err); ok {
+			feat, err = store.GetFeatByName(name)
+		}
+		if err != nil {
+			printLookupError(err)
+			return
+		}
+
+		fmt.Printf("\n--- %s ---\n", feat.Name)
+		fmt.Printf("%s\n", feat.Card())
+		fmt.Print("-------------------\n")
+	},
+}
+
+// parenthesised returns " (s)", or "" if s is empty.
+func parenthesised(s string) string {
+	if s == "" {
+		return ""
+	}
+	return " (" + s + ")"
+}
+
+func init() {
+	RootCmd.AddCommand(featCmd)
+	needsData(featCmd)
+	featCmd.Flags().BoolVar(&closest, "closest", false, "Show the closest match when the name has a small typo")
+}
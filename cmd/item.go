@@ -4,8 +4,6 @@ import (
 	"fmt"
 	"strings"
 
-	"dnd-cli/internal/data"
-
 	"github.com/spf13/cobra"
 )
 
@@ -13,28 +11,36 @@ import (
 var itemCmd = &cobra.Command{
 	Use:   "item [item name]",
 	Short: "Looks up details for a D&D item",
-	Long: `Provides detailed information about a specified D&D item, 
-including its name and a brief description. 
+	Long: `Provides detailed information about a specified D&D item: its
+category, rarity and attunement, cost and weight, weapon damage and
+properties, armor class, Strength requirement and stealth penalty, and
+its description.
 
 Examples:
   dnd item "Potion of Healing"
   dnd item "Longsword"`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
+		store := storeFrom(cmd)
 		itemName := strings.Join(args, " ")
 
-		item, err := data.GetItemByName(itemName)
+		item, err := store.GetItemByName(itemName)
+		if name, ok := closestName(err); ok {
+			item, err = store.GetItemByName(name)
+		}
 		if err != nil {
-			fmt.Printf("Hark! Thy query, good sir or madam, doth bewilder my arcane senses. Pray tell, couldst thou rephrase thy plea, for its meaning doth elude my understanding: %v\n", err)
+			printLookupError(err)
 			return
 		}
 
 		fmt.Printf("\n--- %s ---\n", item.Name)
-		fmt.Printf("Description: %s\n", item.Description)
+		fmt.Printf("%s\n", item.Card())
 		fmt.Print("-------------------\n")
 	},
 }
 
 func init() {
 	RootCmd.AddCommand(itemCmd)
+	needsData(itemCmd)
+	itemCmd.Flags().BoolVar(&closest, "closest", false, "Show the closest match when the name has a small typo")
 }
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"dnd-cli/internal/config"
+	"dnd-cli/internal/dice"
+
+	"github.com/spf13/cobra"
+)
+
+// macroCmd represents the macro command
+var macroCmd = &cobra.Command{
+	Use:   "macro",
+	Short: "Saves and rolls named roll macros",
+	Long: `Macros save the rolls you make every session under a short name. Each
+macro holds one or more rolls, optionally labeled with "label: notation",
+which are rolled together in order.
+
+Macros are stored in ~/.dnd-cli/config.json and can also be rolled from the
+TUI prompt with 'roll @<name>'.
+
+Examples:
+  dnd macro add longsword "attack: 1d20+7" "slashing: 1d8+4"
+  dnd macro list
+  dnd macro run longsword
+  dnd macro remove longsword`,
+}
+
+var macroAddCmd = &cobra.Command{
+	Use:   "add <name> <roll>...",
+	Short: "Adds or replaces a macro",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := strings.ToLower(args[0])
+		if err := config.ValidateMacroName(name); err != nil {
+			fmt.Printf("Hark! That name shall not be inscribed: %v\n", err)
+			return
+		}
+		var macro config.Macro
+		for _, spec := range args[1:] {
+			roll, err := config.ParseMacroRoll(spec)
+			if err != nil {
+				fmt.Printf("Hark! Thy query, good sir or madam, doth bewilder my arcane senses: %v\n", err)
+				return
+			}
+			macro = append(macro, roll)
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			fmt.Printf("Hark! Thy tome of settings cannot be read: %v\n", err)
+			return
+		}
+		_, replaced := cfg.Macros[name]
+		if cfg.Macros == nil {
+			cfg.Macros = make(map[string]config.Macro)
+		}
+		cfg.Macros[name] = macro
+		if err := config.Save(cfg); err != nil {
+			fmt.Printf("Hark! The scribe's quill hath failed: %v\n", err)
+			return
+		}
+		if replaced {
+			fmt.Printf("Macro '%s' updated: %s\n", name, macro)
+		} else {
+			fmt.Printf("Macro '%s' added: %s\n", name, macro)
+		}
+	},
+}
+
+var macroListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the saved macros",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.Load()
+		if err != nil {
+			fmt.Printf("Hark! Thy tome of settings cannot be read: %v\n", err)
+			return
+		}
+		if len(cfg.Macros) == 0 {
+			fmt.Println("No macros yet. Add one with 'dnd macro add <name> <roll>...'.")
+			return
+		}
+		fmt.Printf("\n--- Macros ---\n")
+		for _, name := range cfg.MacroNames() {
+			fmt.Printf("%s: %s\n", name, cfg.Macros[name])
+		}
+		fmt.Print("--------------\n")
+	},
+}
+
+var macroRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Removes a macro",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := strings.ToLower(args[0])
+		cfg, err := config.Load()
+		if err != nil {
+			fmt.Printf("Hark! Thy tome of settings cannot be read: %v\n", err)
+			return
+		}
+		if _, ok := cfg.Macros[name]; !ok {
+			fmt.Printf("Hark! No macro named '%s' is written in thy tome.\n", name)
+			return
+		}
+		delete(cfg.Macros, name)
+		if err := config.Save(cfg); err != nil {
+			fmt.Printf("Hark! The scribe's quill hath failed: %v\n", err)
+			return
+		}
+		fmt.Printf("Macro '%s' removed.\n", name)
+	},
+}
+
+var macroRunCmd = &cobra.Command{
+	Use:   "run <name>",
+	Short: "Rolls every roll of a macro",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := strings.TrimPrefix(strings.ToLower(args[0]), "@")
+		cfg, err := config.Load()
+		if err != nil {
+			fmt.Printf("Hark! Thy tome of settings cannot be read: %v\n", err)
+			return
+		}
+		macro, ok := cfg.Macros[name]
+		if !ok {
+			fmt.Printf("Hark! No macro named '%s' is written in thy tome.\n", name)
+			return
+		}
+		content, entries, err := macro.Roll(name, dice.Default())
+		if err != nil {
+			fmt.Printf("Hark! The fates cannot grant thy request: %v\n", err)
+			return
+		}
+		fmt.Printf("\n--- %s ---\n%s\n", name, content)
+		for _, e := range entries {
+			e.Character = macroChar
+			recordRoll(e)
+		}
+	},
+}
+
+// macroChar is the character recorded with the rolls of macro run.
+var macroChar string
+
+func init() {
+	RootCmd.AddCommand(macroCmd)
+	macroCmd.AddCommand(macroAddCmd, macroListCmd, macroRemoveCmd, macroRunCmd)
+
+	macroRunCmd.Flags().StringVarP(&macroChar, "char", "c", "", "Character recorded with the rolls")
+}
@@ -4,8 +4,6 @@ import (
 	"fmt"
 	"strings"
 
-	"dnd-cli/internal/data"
-
 	"github.com/spf13/cobra"
 )
 
@@ -13,28 +11,35 @@ import (
 var monsterCmd = &cobra.Command{
 	Use:   "monster [monster name]",
 	Short: "Looks up details for a D&D monster",
-	Long: `Provides detailed information about a specified D&D monster, 
-including its name and a brief description. 
+	Long: `Shows the stat block of a specified D&D monster: armor class, hit points,
+speed, ability scores, saving throws, skills, senses, challenge rating,
+traits, actions, reactions and legendary actions.
 
 Examples:
   dnd monster "Goblin"
   dnd monster "Ancient Red Dragon"`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
+		store := storeFrom(cmd)
 		monsterName := strings.Join(args, " ")
 
-		monster, err := data.GetMonsterByName(monsterName)
+		monster, err := store.GetMonsterByName(monsterName)
+		if name, ok := closestName(err); ok {
+			monster, err = store.GetMonsterByName(name)
+		}
 		if err != nil {
-			fmt.Printf("Hark! Thy query, good sir or madam, doth bewilder my arcane senses. Pray tell, couldst thou rephrase thy plea, for its meaning doth elude my understanding: %v\n", err)
+			printLookupError(err)
 			return
 		}
 
 		fmt.Printf("\n--- %s ---\n", monster.Name)
-		fmt.Printf("Description: %s\n", monster.Description)
+		fmt.Printf("%s\n", monster.StatBlock())
 		fmt.Print("-------------------\n")
 	},
 }
 
 func init() {
 	RootCmd.AddCommand(monsterCmd)
+	needsData(monsterCmd)
+	monsterCmd.Flags().BoolVar(&closest, "closest", false, "Show the closest match when the name has a small typo")
 }
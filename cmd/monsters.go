@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"dnd-cli/internal/data"
+
+	"github.com/spf13/cobra"
+)
+
+// monstersCmd represents the monsters command
+var monstersCmd = &cobra.Command{
+	Use:   "monsters",
+	Short: "Searches the bestiary",
+	Long: `Commands for searching all loaded monsters. Use 'dnd monster <name>' to
+see a single stat block.`,
+}
+
+// Flags of monsters list.
+var (
+	monstersCR          string
+	monstersType        string
+	monstersSize        string
+	monstersEnvironment string
+	monstersSort        string
+	monstersPage        int
+	monstersPerPage     int
+)
+
+var monstersListCmd = &cobra.Command{
+	Use:   "list [name words]",
+	Short: "Lists monsters by challenge rating, type, size and environment",
+	Long: `Lists the monsters matching every given filter, one per line with their
+challenge rating, XP, size and type. Words after 'list' must appear in the
+monster's name.
+
+--cr accepts a rating or a range: 2, 1/4..2, ..1/2 or 5..
+--type matches a monster's type or subtype (undead, goblinoid).
+--size accepts a size or the start of one (med).
+
+Examples:
+  dnd monsters list --cr 1/4..2 --type undead
+  dnd monsters list --size medium --environment swamp --sort xp
+  dnd monsters list dragon --cr 10.. --page 2`,
+	Run: func(cmd *cobra.Command, args []string) {
+		store := storeFrom(cmd)
+		query := data.MonsterQuery{Text: strings.Join(args, " "), Type: monstersType, Environment: monstersEnvironment}
+		var err error
+		if monstersCR != "" {
+			err = query.SetCR(monstersCR)
+		}
+		if err == nil && monstersSize != "" {
+			err = query.SetSize(monstersSize)
+		}
+		if err == nil {
+			err = query.SetSort(monstersSort)
+		}
+		if err != nil {
+			fmt.Printf("Hark! Thy query, good sir or madam, doth bewilder my arcane senses: %v\n", err)
+			return
+		}
+
+		matched := store.FindMonsters(query)
+		if len(matched) == 0 {
+			fmt.Println("No creature in all the realms answers to that description.")
+			return
+		}
+		page, pages := data.Page(matched, monstersPage, monstersPerPage)
+		if len(page) == 0 {
+			fmt.Printf("Hark! There is no page %d; the list ends at page %d.\n", monstersPage, pages)
+			return
+		}
+
+		fmt.Printf("\n--- Monsters ---\n")
+		fmt.Printf("%-28s %-5s %7s  %s\n", "Name", "CR", "XP", "Type")
+		for _, m := range page {
+			line := fmt.Sprintf("%-28s %-5s %7d  %s", m.Name, m.ChallengeRating, m.XP, strings.TrimSpace(m.Size+" "+m.Type))
+			fmt.Println(strings.TrimRight(line, " "))
+		}
+		fmt.Printf("----------------\n")
+		fmt.Printf("Page %d of %d, %d matching\n", monstersPage, pages, len(matched))
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(monstersCmd)
+	needsData(monstersCmd)
+	monstersCmd.AddCommand(monstersListCmd)
+
+	monstersListCmd.Flags().StringVar(&monstersCR, "cr", "", "Only monsters of this challenge rating or range (1/4..2)")
+	monstersListCmd.Flags().StringVar(&monstersType, "type", "", "Only monsters of this type or subtype")
+	monstersListCmd.Flags().StringVar(&monstersSize, "size", "", "Only monsters of this size")
+	monstersListCmd.Flags().StringVar(&monstersEnvironment, "environment", "", "Only monsters found in this environment")
+	monstersListCmd.Flags().StringVar(&monstersSort, "sort", "cr", "Sort by cr, xp or name")
+	monstersListCmd.Flags().IntVar(&monstersPage, "page", 1, "Page of results to show")
+	monstersListCmd.Flags().IntVar(&monstersPerPage, "per-page", 20, "Monsters per page (0 for all)")
+}
@@ -3,8 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"dnd-cli/internal/data"
-
 	"github.com/spf13/cobra"
 )
 
@@ -19,9 +17,10 @@ Examples:
   dnd npc generate
   dnd npc`, // 'dnd npc' will default to generate
 	Run: func(cmd *cobra.Command, args []string) {
+		store := storeFrom(cmd)
 		// If no subcommand is given, default to generate
 		if len(args) == 0 || args[0] == "generate" {
-			npc := data.GenerateNPC()
+			npc := store.GenerateNPC()
 			fmt.Printf("\n--- Generated NPC ---\n")
 			fmt.Printf("Name: %s\n", npc.Name)
 			fmt.Printf("Species: %s\n", npc.Species)
@@ -40,6 +39,7 @@ Examples:
 
 func init() {
 	RootCmd.AddCommand(npcCmd)
+	needsData(npcCmd)
 
 	// Add a 'generate' subcommand explicitly for clarity, though 'dnd npc' will default to it.
 	var generateCmd = &cobra.Command{
@@ -47,7 +47,8 @@ func init() {
 		Short: "Generates a random NPC",
 		Long:  `Generates a random non-player character (NPC) with a name, species, background, personality traits, ideals, bonds, flaws, and a backstory snippet.`,
 		Run: func(cmd *cobra.Command, args []string) {
-			npc := data.GenerateNPC()
+			store := storeFrom(cmd)
+			npc := store.GenerateNPC()
 			fmt.Printf("\n--- Generated NPC ---\n")
 			fmt.Printf("Name: %s\n", npc.Name)
 			fmt.Printf("Species: %s\n", npc.Species)
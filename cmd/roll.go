@@ -1,10 +1,13 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
 
 	"dnd-cli/internal/dice"
+	"dnd-cli/internal/rolllog"
 
 	"github.com/spf13/cobra"
 )
@@ -12,6 +15,10 @@ import (
 var (
 	advantage    bool
 	disadvantage bool
+	crit         bool
+	critMode     string
+	rollLabel    string
+	rollChar     string
 )
 
 // rollCmd represents the roll command
@@ -20,63 +27,323 @@ var rollCmd = &cobra.Command{
 	Short: "Rolls dice using standard D&D notation (e.g., 2d6, 1d20+5)",
 	Long: `Rolls dice based on the provided notation. 
 
+Expressions may combine any number of dice groups and constants with
++, -, * and / (division rounds down), grouped with parentheses. Dice
+groups accept keep/drop modifiers: kh/kl keep the highest/lowest N dice,
+dh/dl drop the highest/lowest N (e.g. 4d6kh3, 2d20kl1, 4d6dl1).
+
+Other dice group modifiers:
+  !          explode: roll another die on the highest face (!>=5 for a custom threshold)
+  r1, r<3    reroll while the face matches
+  ro<3       reroll once (Great Weapon Fighting)
+  min2, max5 treat lower/higher faces as the given value (Elemental Adept)
+
+Dice pools count dice instead of adding them up:
+  >=5, >4, =6  count each die meeting the target as a success (10d6>=5)
+  f1, f<3      count each matching die as a failure (8d10f1); with a
+               success target, failures are subtracted (6d10>=8f1)
+
+In the breakdown, "1→4" marks a rerolled die, "6!" a die that exploded,
+"1↑2" or "6↓5" a die raised by min or lowered by max, and "5✓" or "1✗"
+a success or failure in a pool.
+
+End the roll with "vs <target>" to see whether it hits and by how much.
+A natural 20 always hits and a natural 1 always misses; both are flagged
+on any roll with a single deciding d20.
+
+Advantage and disadvantage roll the first d20 twice and keep the
+higher or lower die (1d20+5 becomes 2d20kh1+5). A roll without a single
+d20, such as 4d6, has no die to roll twice and is refused.
+
+--crit rolls critical hit damage: the dice are doubled but modifiers are
+not (2d6+3 becomes 4d6+3). --crit-mode picks a house rule instead:
+  double-dice    roll twice the dice (default)
+  max-plus-roll  one set of dice counts as maximum, plus a normal roll
+  double-total   roll the dice once and double what they show
+
 Examples:
   dnd roll 1d20
   dnd roll 2d6+3
-  dnd roll 1d20 --advantage
-  dnd roll 4d6 --disadvantage`,
-	Args: cobra.ExactArgs(1),
+  dnd roll 1d8+2d6+3
+  dnd roll "1d20 + 1d4 - 1"
+  dnd roll "(1d6+2)*2"
+  dnd roll 4d6kh3
+  dnd roll "2d6ro<3+4"
+  dnd roll 8d6min2
+  dnd roll 3d6!
+  dnd roll "10d6>=5"
+  dnd roll 8d10f1
+  dnd roll "1d20+7 vs 15"
+  dnd roll 1d20+5 --advantage
+  dnd roll 1d20 --disadvantage
+  dnd roll 2d6+3 --crit
+  dnd roll 1d8+4 --crit --crit-mode max-plus-roll
+
+Every roll is recorded in ~/.dnd-cli/rolls.jsonl; tag it with --label and
+--char, and use 'dnd roll history' to look back.
+
+Use 'dnd roll stats <notation>' to see the odds of a roll.`,
+	Args: cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		notation := strings.ToLower(args[0])
+		dr, label, ok := parseRollArgs(args)
+		if !ok {
+			return
+		}
+
+		total, terms := dr.Roll()
+		fmt.Printf("Rolling %s%s:\n%s\n-> Total: %d\n", dr.Notation, label, dice.FormatTerms(terms), total)
+		if check := dr.Check(total, terms).String(); check != "" {
+			fmt.Printf("-> %s\n", check)
+		}
 
-		if advantage && disadvantage {
-			fmt.Println("Hark! Thou canst not have both advantage and disadvantage, for the fates are fickle but not contradictory!")
+		entry := rolllog.NewEntry(dr, total, terms)
+		entry.Label, entry.Character = rollLabel, rollChar
+		recordRoll(entry)
+	},
+}
+
+// rollStatsCmd represents the roll stats command
+var rollStatsCmd = &cobra.Command{
+	Use:   "stats [notation]",
+	Short: "Shows the probability distribution of a dice roll",
+	Long: `Computes the exact probability distribution of a dice expression and shows
+its minimum, maximum, mean and standard deviation along with a histogram.
+Keep/drop, reroll, clamp and exploding modifiers are all taken into account.
+
+Use --dc (or end the roll with "vs <target>") to see the chance of meeting
+or beating a target number, and --advantage or --disadvantage to see how
+they shift the odds.
+
+Examples:
+  dnd roll stats 2d6+3
+  dnd roll stats 1d20+5 --dc 15
+  dnd roll stats 1d20+5 --dc 15 --advantage
+  dnd roll stats 4d6kh3`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dr, label, ok := parseRollArgs(args)
+		if !ok {
 			return
 		}
 
-		dr, err := dice.ParseDiceNotation(notation)
+		dist, err := dr.Distribution()
 		if err != nil {
-			fmt.Printf("Hark! Thy query, good sir or madam, doth bewilder my arcane senses. Pray tell, couldst thou rephrase thy plea, for its meaning doth elude my understanding: %v\n", err)
+			fmt.Printf("Hark! Even the wisest sages cannot foresee this: %v\n", err)
 			return
 		}
 
-		var total int
-		var rolls []int
-
-		if advantage {
-			roll1, _ := dr.Roll()
-			roll2, _ := dr.Roll()
-			total = max(roll1, roll2)
-			fmt.Printf("Rolling %s with Advantage: (Roll 1: %d, Roll 2: %d) -> Total: %d\n", dr.Notation, roll1, roll2, total)
-		} else if disadvantage {
-			roll1, _ := dr.Roll()
-			roll2, _ := dr.Roll()
-			total = min(roll1, roll2)
-			fmt.Printf("Rolling %s with Disadvantage: (Roll 1: %d, Roll 2: %d) -> Total: %d\n", dr.Notation, roll1, roll2, total)
-		} else {
-			total, rolls = dr.Roll()
-			fmt.Printf("Rolling %s: %v -> Total: %d\n", dr.Notation, rolls, total)
+		fmt.Printf("\n--- Odds for %s%s ---\n", dr.Notation, label)
+		fmt.Printf("Min: %d  Max: %d  Mean: %.2f  Std Dev: %.2f\n", dist.Min, dist.Max(), dist.Mean(), dist.StdDev())
+		if cmd.Flags().Changed("dc") {
+			fmt.Printf("P(≥ %d): %.2f%%\n", statsDC, dist.AtLeast(statsDC)*100)
+		} else if dr.HasTarget {
+			fmt.Printf("P(≥ %d): %.2f%%\n", dr.Target, dist.AtLeast(dr.Target)*100)
 		}
+		fmt.Printf("\n%s\n", dist.Histogram(40, 40))
+		fmt.Print("-------------------\n")
 	},
 }
 
-func init() {
-	RootCmd.AddCommand(rollCmd)
+// statsDC is the target number for roll stats.
+var statsDC int
+
+// rollHistoryCmd represents the roll history command
+var rollHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Shows previously recorded rolls",
+	Long: `Shows the rolls recorded in ~/.dnd-cli/rolls.jsonl, newest first.
+
+A session is a run of rolls with no gap longer than four hours between them.
 
-	rollCmd.Flags().BoolVarP(&advantage, "advantage", "a", false, "Roll with advantage (roll twice, take higher)")
-	rollCmd.Flags().BoolVarP(&disadvantage, "disadvantage", "d", false, "Roll with disadvantage (roll twice, take lower)")
+Examples:
+  dnd roll history
+  dnd roll history --session last
+  dnd roll history --session 3
+  dnd roll history --date 2025-06-14
+  dnd roll history --char Thorin --last 50`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		entries, ok := loadRollHistory(historyLast)
+		if !ok {
+			return
+		}
+		if len(entries) == 0 {
+			fmt.Println("No rolls recorded yet that match.")
+			return
+		}
+		fmt.Printf("\n--- Roll History ---\n")
+		for i := len(entries) - 1; i >= 0; i-- {
+			fmt.Println(entries[i])
+		}
+		fmt.Print("--------------------\n")
+	},
 }
 
-func max(a, b int) int {
-	if a > b {
-		return a
+// rollReportCmd represents the roll report command
+var rollReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Checks whether your dice are cursed",
+	Long: `Aggregates the recorded rolls by die type and compares how often each face
+came up with how often it should have. A chi-squared test judges each die
+as fair, suspicious (p < 0.05) or cursed (p < 0.01); dice need at least 5
+expected rolls per face for a verdict. The report also counts natural 20s
+and 1s and ranks characters by luck: how far their dice ran above or below
+the middle of each die.
+
+Use the same --session, --date and --char filters as 'dnd roll history',
+and --json for machine-readable output.
+
+Examples:
+  dnd roll report
+  dnd roll report --session last
+  dnd roll report --char Thorin --json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		entries, ok := loadRollHistory(0)
+		if !ok {
+			return
+		}
+		report := rolllog.BuildReport(entries)
+		if reportJSON {
+			out, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				fmt.Printf("Hark! The scribe's quill hath failed: %v\n", err)
+				return
+			}
+			fmt.Println(string(out))
+			return
+		}
+		if len(entries) == 0 {
+			fmt.Println("No rolls recorded yet that match.")
+			return
+		}
+		fmt.Printf("\n--- Roll Report ---\n%s\n-------------------\n", report)
+	},
+}
+
+// Filters for roll history and roll report.
+var (
+	historySession string
+	historyDate    string
+	historyChar    string
+	historyLast    int
+	reportJSON     bool
+)
+
+// loadRollHistory loads the roll log and applies the history filters,
+// keeping at most last entries (all when last is 0). It prints a message
+// and returns false if the log cannot be read or a filter is invalid.
+func loadRollHistory(last int) ([]rolllog.Entry, bool) {
+	path, err := rolllog.DefaultPath()
+	if err != nil {
+		fmt.Printf("Hark! A parchment error: %v\n", err)
+		return nil, false
 	}
-	return b
+	entries, bad, err := rolllog.Load(path)
+	if err != nil {
+		fmt.Printf("Hark! The chronicle of rolls is smudged beyond reading: %v\n", err)
+		return nil, false
+	}
+	for _, e := range bad {
+		fmt.Fprintf(os.Stderr, "Hark! A smudged line of the chronicle was passed over: %v\n", e)
+	}
+
+	filter := rolllog.Filter{Character: historyChar, Last: last}
+	if historySession != "" {
+		if filter.Session, err = rolllog.ParseSession(historySession, entries); err != nil {
+			fmt.Printf("Hark! '%s' is no session I know. Use a session number or 'last'.\n", historySession)
+			return nil, false
+		}
+	}
+	if historyDate != "" {
+		if filter.Since, filter.Until, err = rolllog.ParseDay(historyDate); err != nil {
+			fmt.Printf("Hark! '%s' is no date in any calendar I know. Use YYYY-MM-DD.\n", historyDate)
+			return nil, false
+		}
+	}
+	return filter.Apply(entries), true
 }
 
-func min(a, b int) int {
-	if a < b {
-		return a
+// recordRoll appends a roll made from the CLI to the roll log. A failure is
+// reported but does not undo the roll.
+func recordRoll(e rolllog.Entry) {
+	path, err := rolllog.DefaultPath()
+	if err == nil {
+		e.Source = "cli"
+		err = rolllog.Append(path, e)
 	}
-	return b
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Hark! This roll could not be written in the chronicle: %v\n", err)
+	}
+}
+
+// parseRollArgs parses the notation given to roll and its subcommands and
+// applies advantage, disadvantage and critical hits. It prints a message and
+// returns false if the roll cannot be made.
+func parseRollArgs(args []string) (*dice.DiceRoll, string, bool) {
+	notation := strings.ToLower(strings.Join(args, " "))
+
+	if advantage && disadvantage {
+		fmt.Println("Hark! Thou canst not have both advantage and disadvantage, for the fates are fickle but not contradictory!")
+		return nil, "", false
+	}
+
+	dr, err := dice.ParseDiceNotation(notation)
+	if err != nil {
+		fmt.Printf("Hark! Thy query, good sir or madam, doth bewilder my arcane senses. Pray tell, couldst thou rephrase thy plea, for its meaning doth elude my understanding: %v\n", err)
+		return nil, "", false
+	}
+
+	label := ""
+	if advantage {
+		dr, err = dr.WithAdvantage()
+		label = " with Advantage"
+	} else if disadvantage {
+		dr, err = dr.WithDisadvantage()
+		label = " with Disadvantage"
+	}
+	if err == nil && (crit || critMode != "") {
+		mode := dice.CritDoubleDice
+		if critMode != "" {
+			mode, err = dice.ParseCritMode(critMode)
+		}
+		if err == nil {
+			dr, err = dr.WithCrit(mode)
+			label += fmt.Sprintf(" (Critical Hit: %s)", mode)
+		}
+	}
+	if err != nil {
+		fmt.Printf("Hark! The fates cannot grant thy request: %v\n", err)
+		return nil, "", false
+	}
+	return dr, label, true
+}
+
+func init() {
+	RootCmd.AddCommand(rollCmd)
+	rollCmd.AddCommand(rollStatsCmd)
+	rollCmd.AddCommand(rollHistoryCmd)
+	rollCmd.AddCommand(rollReportCmd)
+
+	rollCmd.Flags().BoolVarP(&advantage, "advantage", "a", false, "Roll with advantage (roll the d20 twice, keep the higher)")
+	rollCmd.Flags().BoolVarP(&disadvantage, "disadvantage", "d", false, "Roll with disadvantage (roll the d20 twice, keep the lower)")
+	rollCmd.Flags().BoolVar(&crit, "crit", false, "Roll critical hit damage (double the dice, not the modifiers)")
+	rollCmd.Flags().StringVar(&critMode, "crit-mode", "", "Critical hit rule: double-dice, max-plus-roll or double-total (implies --crit)")
+	rollCmd.Flags().StringVarP(&rollLabel, "label", "l", "", "Label recorded with the roll (e.g. stealth)")
+	rollCmd.Flags().StringVarP(&rollChar, "char", "c", "", "Character recorded with the roll")
+
+	rollStatsCmd.Flags().IntVar(&statsDC, "dc", 0, "Show the chance of rolling at least this total")
+	rollStatsCmd.Flags().BoolVarP(&advantage, "advantage", "a", false, "Analyse the roll with advantage")
+	rollStatsCmd.Flags().BoolVarP(&disadvantage, "disadvantage", "d", false, "Analyse the roll with disadvantage")
+	rollStatsCmd.Flags().BoolVar(&crit, "crit", false, "Analyse critical hit damage")
+	rollStatsCmd.Flags().StringVar(&critMode, "crit-mode", "", "Critical hit rule: double-dice, max-plus-roll or double-total (implies --crit)")
+	rollHistoryCmd.Flags().StringVar(&historySession, "session", "", "Only show a session: a number, or 'last'")
+	rollHistoryCmd.Flags().StringVar(&historyDate, "date", "", "Only show rolls made on this day (YYYY-MM-DD)")
+	rollHistoryCmd.Flags().StringVarP(&historyChar, "char", "c", "", "Only show rolls made for this character")
+	rollHistoryCmd.Flags().IntVarP(&historyLast, "last", "n", 20, "Show at most this many of the most recent rolls (0 for all)")
+
+	rollReportCmd.Flags().StringVar(&historySession, "session", "", "Only include a session: a number, or 'last'")
+	rollReportCmd.Flags().StringVar(&historyDate, "date", "", "Only include rolls made on this day (YYYY-MM-DD)")
+	rollReportCmd.Flags().StringVarP(&historyChar, "char", "c", "", "Only include rolls made for this character")
+	rollReportCmd.Flags().BoolVar(&reportJSON, "json", false, "Print the report as JSON")
 }
@@ -4,11 +4,12 @@ Copyright © 2025
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"path/filepath"
 
 	"dnd-cli/internal/data"
+	"dnd-cli/internal/dice"
 
 	"github.com/spf13/cobra"
 )
@@ -19,36 +20,64 @@ var RootCmd = &cobra.Command{
 	Short: "A CLI companion for Dungeons & Dragons",
 	Long: `dnd is a command-line companion for Dungeons & Dragons players and Dungeon Masters.
 It provides tools for rolling dice, looking up rules, spells, monsters, and more, including an interactive TUI for character creation and content browsing.`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// A fixed seed makes every dice roll and random generator reproducible.
+		if cmd.Flags().Changed("seed") {
+			dice.SetDefault(dice.NewSeededRoller(seed))
+		}
+		// Only the commands that use the D&D data load it, so the rest work
+		// without it.
+		if requiresData(cmd) {
+			store := storeFrom(cmd)
+			if err := loadData(store); err != nil {
+				fmt.Printf("Hark! The ancient scrolls of knowledge are sealed! Failed to load D&D data: %v\n", err)
+				os.Exit(1)
+			}
+			// An entry that doesn't decode is left out; say so, but let
+			// the rest of the data serve.
+			for _, p := range store.Problems() {
+				fmt.Fprintf(os.Stderr, "Hark! A scroll is smudged and was set aside: %s\n", p)
+			}
+			if verbose {
+				printLoadStats(store.Stats())
+			}
+		}
+	},
+}
+
+// seed is the value of the global --seed flag.
+var seed int64
+
+// verbose is the value of the global --verbose flag.
+var verbose bool
+
+// storeKey is the context key of the store the commands look the D&D data
+// up in.
+type storeKey struct{}
+
+// storeFrom returns the store of the context cmd runs in. The commands that
+// need the D&D data load it before they run.
+func storeFrom(cmd *cobra.Command) *data.Store {
+	return cmd.Context().Value(storeKey{}).(*data.Store)
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
-func Execute() {
-	err := RootCmd.Execute()
+// The commands that need the D&D data load it into s and read it from there.
+func Execute(s *data.Store) {
+	ctx := context.WithValue(context.Background(), storeKey{}, s)
+	err := RootCmd.ExecuteContext(ctx)
 	if err != nil {
 		os.Exit(1)
 	}
 }
 
 func init() {
-	// Determine the path to the data directory.
-	// This assumes the 'data' repository is cloned directly into the project root
-	// and contains a 'data' subdirectory with the JSON files.
-	currentDir, err := os.Getwd()
-	if err != nil {
-		fmt.Printf("Hark! A shadow falls upon our path: Failed to get current working directory: %v\n", err)
-		os.Exit(1)
-	}
-
-	// The data files are located in dnd/data/data/ (relative to project root)
-	dataPath := filepath.Join(currentDir, "data", "data")
-
-	// Load the D&D data
-	err = data.LoadData(dataPath)
-	if err != nil {
-		fmt.Printf("Hark! The ancient scrolls of knowledge are sealed! Failed to load D&D data: %v\n", err)
-		os.Exit(1)
-	}
+	RootCmd.PersistentFlags().Int64Var(&seed, "seed", 0, "Seed the dice roller so rolls and generated content are reproducible")
+	RootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Report how long loading the D&D data took and whether the cache was used")
+	RootCmd.PersistentFlags().StringVar(&dataDir, "data-dir", "", "Directory holding the D&D data files (default: $"+data.DataDirEnv+", config data_dir, XDG data dirs, next to the executable)")
+	RootCmd.PersistentFlags().StringArrayVar(&homebrewDirs, "homebrew", nil, "Directory of homebrew content to layer over the data (repeatable; later wins)")
+	RootCmd.PersistentFlags().StringSliceVar(&disabledSources, "disable-source", nil, "Leave out a data source: srd, core or a homebrew overlay's name")
 
 	// Add commands
 	RootCmd.AddCommand(charCmd)
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// ruleCmd represents the rule command
+var ruleCmd = &cobra.Command{
+	Use:   "rule [section]",
+	Short: "Looks up a section of the D&D rules",
+	Long: `Shows a section of the rules, such as combat, initiative or cover.
+Without a section, lists every section by category.
+
+Examples:
+  dnd rule "opportunity attacks"
+  dnd rule`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		store := storeFrom(cmd)
+		if len(args) == 0 {
+			fmt.Printf("\n--- Rules ---\n")
+			var categories []string
+			sections := make(map[string][]string)
+			for _, rule := range store.Rules() {
+				if _, ok := sections[rule.Category]; !ok {
+					categories = append(categories, rule.Category)
+				}
+				sections[rule.Category] = append(sections[rule.Category], rule.Name)
+			}
+			for _, category := range categories {
+				label := category
+				if label == "" {
+					label = "Other"
+				}
+				fmt.Printf("%s: %s\n", label, strings.Join(sections[category], ", "))
+			}
+			fmt.Print("-------------\n")
+			return
+		}
+		section := strings.Join(args, " ")
+
+		rule, err := store.GetRuleByName(section)
+		if name, ok := closestName(err); ok {
+			rule, err = store.GetRuleByName(name)
+		}
+		if err != nil {
+			printLookupError(err)
+			return
+		}
+
+		fmt.Printf("\n--- %s ---\n", rule.Name)
+		fmt.Printf("%s\n", rule.Card())
+		fmt.Print("-------------------\n")
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(ruleCmd)
+	needsData(ruleCmd)
+	ruleCmd.Flags().BoolVar(&closest, "closest", false, "Show the closest match when the name has a small typo")
+}
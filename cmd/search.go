@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"dnd-cli/internal/data"
+
+	"github.com/spf13/cobra"
+)
+
+// Flags of search.
+var (
+	searchKind  string
+	searchLimit int
+)
+
+// searchCmd represents the search command
+var searchCmd = &cobra.Command{
+	Use:   "search <words>",
+	Short: "Searches all spells, monsters, items, classes, subclasses, species, backgrounds, feats, conditions and rules",
+	Long: `Searches the names and full text of all loaded content for entries
+containing every given word, best matches first, each with the passage
+where it matched. Words of three or more letters also match longer words
+they begin, so 'fright' finds 'frightened'.
+
+Examples:
+  dnd search frightened
+  dnd search "fire resistance" --kind monster
+  dnd search poison --limit 5`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		store := storeFrom(cmd)
+		query := strings.Join(args, " ")
+		kind := data.Kind(strings.ToLower(searchKind))
+		if kind != "" && !isKind(kind) {
+			fmt.Printf("Hark! Thy query, good sir or madam, doth bewilder my arcane senses: unknown kind %q\n", searchKind)
+			return
+		}
+
+		var hits []data.Hit
+		for _, hit := range store.Search(query) {
+			if kind == "" || hit.Kind == kind {
+				hits = append(hits, hit)
+			}
+		}
+		if len(hits) == 0 {
+			fmt.Printf("The scrolls are silent; nothing speaks of '%s'.\n", query)
+			return
+		}
+		total := len(hits)
+		if searchLimit > 0 && len(hits) > searchLimit {
+			hits = hits[:searchLimit]
+		}
+
+		fmt.Printf("\n--- Search: %s ---\n", query)
+		for _, hit := range hits {
+			fmt.Printf("%s: %s\n    %s\n", hit.Kind.Title(), hit.Name, hit.Snippet)
+		}
+		fmt.Printf("-------------------\n")
+		fmt.Printf("Showing %d of %d matching\n", len(hits), total)
+	},
+}
+
+// isKind reports whether kind is one of the indexed kinds.
+func isKind(kind data.Kind) bool {
+	for _, k := range data.Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	RootCmd.AddCommand(searchCmd)
+	needsData(searchCmd)
+
+	searchCmd.Flags().StringVar(&searchKind, "kind", "", "Only search one kind: spell, monster, item, species, background, class, subclass, feat, condition or rule")
+	searchCmd.Flags().IntVar(&searchLimit, "limit", 10, "Show at most this many results (0 for all)")
+}
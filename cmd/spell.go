@@ -4,8 +4,6 @@ import (
 	"fmt"
 	"strings"
 
-	"dnd-cli/internal/data"
-
 	"github.com/spf13/cobra"
 )
 
@@ -13,32 +11,35 @@ import (
 var spellCmd = &cobra.Command{
 	Use:   "spell [spell name]",
 	Short: "Looks up details for a D&D spell",
-	Long: `Provides detailed information about a specified D&D spell, 
-including its description, properties, and source. 
+	Long: `Shows the spell card of a specified D&D spell: its level and school,
+casting time, range, components, duration, classes, description, scaling
+at higher levels, and source.
 
 Examples:
   dnd spell "Fireball"
   dnd spell "eldritch blast"`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
+		store := storeFrom(cmd)
 		spellName := strings.Join(args, " ")
 
-		spell, err := data.GetSpellByName(spellName)
+		spell, err := store.GetSpellByName(spellName)
+		if name, ok := closestName(err); ok {
+			spell, err = store.GetSpellByName(name)
+		}
 		if err != nil {
-			fmt.Printf("Hark! Thy query, good sir or madam, doth bewilder my arcane senses. Pray tell, couldst thou rephrase thy plea, for its meaning doth elude my understanding: %v\n", err)
+			printLookupError(err)
 			return
 		}
 
 		fmt.Printf("\n--- %s ---\n", spell.Name)
-		fmt.Printf("Description: %s\n", spell.Description)
-		for key, value := range spell.Properties {
-			fmt.Printf("%s: %v\n", key, value)
-		}
-		fmt.Printf("Source: %s (%s)\n", spell.Book, spell.Publisher)
+		fmt.Printf("%s\n", spell.Card())
 		fmt.Print("-------------------\n")
 	},
 }
 
 func init() {
 	RootCmd.AddCommand(spellCmd)
+	needsData(spellCmd)
+	spellCmd.Flags().BoolVar(&closest, "closest", false, "Show the closest match when the name has a small typo")
 }
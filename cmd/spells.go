@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"dnd-cli/internal/data"
+
+	"github.com/spf13/cobra"
+)
+
+// spellsCmd represents the spells command
+var spellsCmd = &cobra.Command{
+	Use:   "spells",
+	Short: "Searches the spell list",
+	Long: `Commands for searching all loaded spells. Use 'dnd spell <name>' to see
+a single spell card.`,
+}
+
+// Flags of spells list.
+var (
+	spellsClass         string
+	spellsLevel         string
+	spellsSchool        string
+	spellsRitual        bool
+	spellsConcentration bool
+	spellsSort          string
+	spellsPage          int
+	spellsPerPage       int
+)
+
+var spellsListCmd = &cobra.Command{
+	Use:   "list [name words]",
+	Short: "Lists spells by class, level, school, ritual and concentration",
+	Long: `Lists the spells matching every given filter, one per line with their
+level, school, casting time and whether they are rituals (R) or need
+concentration (C). Words after 'list' must appear in the spell's name.
+
+--level accepts a level, a range or a list: 3, 1-3, cantrip or 0,1,2.
+--school accepts a school of magic or the start of one (evoc).
+--ritual and --concentration filter only when given, so
+--concentration=false lists the spells that don't need concentration.
+
+Examples:
+  dnd spells list --class wizard --level 3 --ritual
+  dnd spells list --school evocation --concentration=false --sort level
+  dnd spells list fire --page 2 --per-page 10`,
+	Run: func(cmd *cobra.Command, args []string) {
+		store := storeFrom(cmd)
+		query := data.SpellQuery{Text: strings.Join(args, " "), Class: spellsClass}
+		var err error
+		if spellsLevel != "" {
+			err = query.SetLevels(spellsLevel)
+		}
+		if err == nil && spellsSchool != "" {
+			err = query.SetSchool(spellsSchool)
+		}
+		if err == nil {
+			err = query.SetSort(spellsSort)
+		}
+		if err != nil {
+			fmt.Printf("Hark! Thy query, good sir or madam, doth bewilder my arcane senses: %v\n", err)
+			return
+		}
+		if cmd.Flags().Changed("ritual") {
+			query.Ritual = &spellsRitual
+		}
+		if cmd.Flags().Changed("concentration") {
+			query.Concentration = &spellsConcentration
+		}
+
+		matched := store.FindSpells(query)
+		if len(matched) == 0 {
+			fmt.Println("No spell in all the realms answers to that description.")
+			return
+		}
+		page, pages := data.Page(matched, spellsPage, spellsPerPage)
+		if len(page) == 0 {
+			fmt.Printf("Hark! There is no page %d; the list ends at page %d.\n", spellsPage, pages)
+			return
+		}
+
+		fmt.Printf("\n--- Spells ---\n")
+		fmt.Printf("%-28s %-8s %-14s %s\n", "Name", "Level", "School", "Casting Time")
+		for _, s := range page {
+			line := fmt.Sprintf("%-28s %-8s %-14s %-20s %s", s.Name, spellLevelLabel(s.Level), s.School, s.CastingTime, spellTags(&s))
+			fmt.Println(strings.TrimRight(line, " "))
+		}
+		fmt.Printf("--------------\n")
+		fmt.Printf("Page %d of %d, %d matching\n", spellsPage, pages, len(matched))
+	},
+}
+
+// spellLevelLabel renders a spell level for the list: "Cantrip" or "3rd".
+func spellLevelLabel(level int) string {
+	if level == 0 {
+		return "Cantrip"
+	}
+	return data.Ordinal(level)
+}
+
+// spellTags marks rituals with R and concentration spells with C.
+func spellTags(s *data.Spell) string {
+	var tags []string
+	if s.Ritual {
+		tags = append(tags, "R")
+	}
+	if s.Concentration {
+		tags = append(tags, "C")
+	}
+	return strings.Join(tags, " ")
+}
+
+func init() {
+	RootCmd.AddCommand(spellsCmd)
+	needsData(spellsCmd)
+	spellsCmd.AddCommand(spellsListCmd)
+
+	spellsListCmd.Flags().StringVar(&spellsClass, "class", "", "Only spells on this class's list")
+	spellsListCmd.Flags().StringVar(&spellsLevel, "level", "", "Only spells of these levels (3, 1-3, cantrip, 0,1,2)")
+	spellsListCmd.Flags().StringVar(&spellsSchool, "school", "", "Only spells of this school of magic")
+	spellsListCmd.Flags().BoolVar(&spellsRitual, "ritual", false, "Only rituals (or, with =false, only non-rituals)")
+	spellsListCmd.Flags().BoolVar(&spellsConcentration, "concentration", false, "Only concentration spells (or, with =false, only spells without)")
+	spellsListCmd.Flags().StringVar(&spellsSort, "sort", "name", "Sort by name, level or school")
+	spellsListCmd.Flags().IntVar(&spellsPage, "page", 1, "Page of results to show")
+	spellsListCmd.Flags().IntVar(&spellsPerPage, "per-page", 20, "Spells per page (0 for all)")
+}
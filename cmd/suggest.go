@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"dnd-cli/internal/data"
+)
+
+// closest is the value of the --closest flag of the lookup commands.
+var closest bool
+
+// closestName returns the name to retry a failed lookup with when
+// --closest is set.
+func closestName(err error) (string, bool) {
+	if !closest {
+		return "", false
+	}
+	return data.ClosestMatch(err)
+}
+
+// printLookupError reports a failed lookup with any suggestions.
+func printLookupError(err error) {
+	fmt.Printf("Hark! Thy query, good sir or madam, doth bewilder my arcane senses. Pray tell, couldst thou rephrase thy plea, for its meaning doth elude my understanding: %v\n", err)
+	var notFound *data.NotFoundError
+	if errors.As(err, &notFound) && len(notFound.Suggestions) > 0 {
+		fmt.Println(notFound.DidYouMean())
+	}
+}
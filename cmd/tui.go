@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"dnd-cli/internal/dice"
 	"dnd-cli/internal/tui"
 
 	"github.com/spf13/cobra"
@@ -15,12 +16,14 @@ text-based user interface for the D&D CLI companion.
 
 Use this to browse content, roll dice, and create characters interactively.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		tui.StartTUI()
+		store := storeFrom(cmd)
+		tui.StartTUI(store, dice.Default())
 	},
 }
 
 func init() {
 	RootCmd.AddCommand(tuiCmd)
+	needsData(tuiCmd)
 
 	// Here you will define your flags and configuration settings.
 
@@ -8,7 +8,7 @@ import (
 )
 
 func TestNewCharacter(t *testing.T) {
-	char := NewCharacter("TestChar", "Human", "Fighter", "Soldier", 10, 10, 10, 10, 10, 10)
+	char := NewCharacter("TestChar", "Human", "Fighter", "Soldier", "", 1, 10, 10, 10, 10, 10, 10)
 
 	if char.Name != "TestChar" {
 		t.Errorf("Expected name TestChar, got %s", char.Name)
@@ -27,7 +27,7 @@ func TestSaveLoadCharacter(t *testing.T) {
 	}
 	defer os.RemoveAll(testDir) // Clean up after test
 
-	originalChar := NewCharacter("SaveLoadChar", "Elf", "Rogue", "Criminal", 10, 10, 10, 10, 10, 10)
+	originalChar := NewCharacter("SaveLoadChar", "Elf", "Rogue", "Criminal", "", 1, 10, 10, 10, 10, 10, 10)
 	charFilePath := filepath.Join(testDir, "SaveLoadChar.json")
 
 	// Save character
@@ -50,7 +50,7 @@ func TestSaveLoadCharacter(t *testing.T) {
 }
 
 func TestLevelUp(t *testing.T) {
-	char := NewCharacter("LevelUpChar", "Dwarf", "Cleric", "Acolyte", 10, 10, 10, 10, 10, 10)
+	char := NewCharacter("LevelUpChar", "Dwarf", "Cleric", "Acolyte", "", 1, 10, 10, 10, 10, 10, 10)
 
 	initialLevel := char.Level
 	initialHP := char.HitPoints
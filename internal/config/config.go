@@ -0,0 +1,119 @@
+// Package config reads and writes the user's settings: the TUI theme, where
+// the D&D data comes from and the roll macros shared by the CLI and the TUI.
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"dnd-cli/internal/data"
+)
+
+// Theme represents a color theme for the TUI.
+type Theme struct {
+	FocusedColor    string `json:"focused_color"`
+	BlurColor       string `json:"blur_color"`
+	CursorColor     string `json:"cursor_color"`
+	PromptColor     string `json:"prompt_color"`
+	QuitColor       string `json:"quit_color"`
+	OutputColor     string `json:"output_color"`
+	ErrorColor      string `json:"error_color"`
+	SelectedColor   string `json:"selected_color"`
+	UnselectedColor string `json:"unselected_color"`
+	InfoCardColor   string `json:"info_card_color"`
+	RollColor       string `json:"roll_color"`
+	HeaderColor     string `json:"header_color"`
+}
+
+// Config holds the TUI theme, the data sources and the roll macros shared
+// by the CLI and the TUI.
+type Config struct {
+	Theme      Theme            `json:"theme"`
+	Macros     map[string]Macro `json:"macros,omitempty"`
+	AutoSelect bool             `json:"auto_select,omitempty"` // show the closest match of a lookup with a small typo
+	DataDir    string           `json:"data_dir,omitempty"`    // where the D&D data files are
+
+	Homebrew        []data.Overlay `json:"homebrew,omitempty"`         // homebrew content layered over the data
+	DisabledSources []string       `json:"disabled_sources,omitempty"` // data sources to leave out
+}
+
+// DefaultTheme returns the default theme.
+func DefaultTheme() Theme {
+	return Theme{
+		FocusedColor:    "#87CEEB", // Sky Blue
+		BlurColor:       "#ADD8E6", // Light Blue
+		CursorColor:     "#FFC0CB", // Pink
+		PromptColor:     "#87CEEB", // Sky Blue
+		QuitColor:       "#D3D3D3", // Light Gray
+		OutputColor:     "#F8F9FA", // Off-White
+		ErrorColor:      "#F8BBD9", // Pastel Rose
+		SelectedColor:   "#87CEEB", // Sky Blue
+		UnselectedColor: "#D3D3D3", // Light Gray
+		InfoCardColor:   "#FFC0CB", // Pink
+		RollColor:       "#87CEEB", // Sky Blue
+		HeaderColor:     "#ADD8E6", // Light Blue
+	}
+}
+
+// Path returns where the config is kept, ~/.dnd-cli/config.json.
+func Path() string {
+	return filepath.Join(os.Getenv("HOME"), ".dnd-cli", "config.json")
+}
+
+// Load loads the config from file. A missing file gives the
+// defaults; a file that can't be read or decoded is an error, along with
+// the defaults so that a caller may still run with them.
+func Load() (Config, error) {
+	config := Config{Theme: DefaultTheme()}
+	file, err := os.Open(Path())
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return config, fmt.Errorf("failed to read config: %w", err)
+	}
+	defer file.Close()
+
+	if err := json.NewDecoder(file).Decode(&config); err != nil {
+		return Config{Theme: DefaultTheme()}, fmt.Errorf("failed to read config %s: %w", Path(), err)
+	}
+	return config, nil
+}
+
+// Save saves the config to file. It refuses to replace a config
+// that can't be read, so that a typo in it doesn't cost the user their
+// theme and macros. The config is written to a temporary file first and
+// renamed over the old one, so that a crash midway leaves the old config
+// whole.
+func Save(config Config) (err error) {
+	if _, err := Load(); err != nil {
+		return fmt.Errorf("%w; fix or remove it before saving", err)
+	}
+	path := Path()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			file.Close()
+			os.Remove(file.Name())
+		}
+	}()
+	if err := json.NewEncoder(file).Encode(config); err != nil {
+		return err
+	}
+	if err := file.Chmod(0644); err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
@@ -0,0 +1,179 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"dnd-cli/internal/dice"
+)
+
+func TestValidateMacroName(t *testing.T) {
+	tests := []struct {
+		name      string
+		macro     string
+		expectErr bool
+	}{
+		{name: "lowercase", macro: "longsword", expectErr: false},
+		{name: "digits-dash-underscore", macro: "fire_bolt-2", expectErr: false},
+		{name: "leading-digit", macro: "2handed", expectErr: false},
+		{name: "empty", macro: "", expectErr: true},
+		{name: "uppercase", macro: "Longsword", expectErr: true},
+		{name: "space", macro: "long sword", expectErr: true},
+		{name: "at-sign", macro: "@longsword", expectErr: true},
+		{name: "leading-dash", macro: "-sword", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateMacroName(tt.macro)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("ValidateMacroName(%q) error = %v, expectErr %v", tt.macro, err, tt.expectErr)
+			}
+		})
+	}
+}
+
+func TestParseMacroRoll(t *testing.T) {
+	tests := []struct {
+		name      string
+		spec      string
+		expect    MacroRoll
+		expectErr bool
+	}{
+		{name: "labeled", spec: "attack: 1d20+7", expect: MacroRoll{Label: "attack", Notation: "1d20+7"}},
+		{name: "bare", spec: "2d6+3", expect: MacroRoll{Notation: "2d6+3"}},
+		{name: "spaces", spec: "  slashing :  1d8 + 4 ", expect: MacroRoll{Label: "slashing", Notation: "1d8 + 4"}},
+		{name: "invalid-notation", spec: "attack: 1d", expectErr: true},
+		{name: "empty-notation", spec: "attack:", expectErr: true},
+		{name: "empty", spec: "", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roll, err := ParseMacroRoll(tt.spec)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("ParseMacroRoll(%q) error = %v, expectErr %v", tt.spec, err, tt.expectErr)
+				return
+			}
+			if !tt.expectErr && roll != tt.expect {
+				t.Errorf("ParseMacroRoll(%q) = %+v, want %+v", tt.spec, roll, tt.expect)
+			}
+		})
+	}
+}
+
+func TestMacroRoll(t *testing.T) {
+	tests := []struct {
+		name         string
+		macro        Macro
+		expectLabels []string
+		expectTitles []string
+		expectErr    bool
+	}{
+		{
+			name:         "labeled",
+			macro:        Macro{{Label: "attack", Notation: "1d20+7"}, {Label: "slashing", Notation: "1d8+4"}},
+			expectLabels: []string{"longsword attack", "longsword slashing"},
+			expectTitles: []string{"attack (1d20+7)", "slashing (1d8+4)"},
+		},
+		{
+			name:         "bare",
+			macro:        Macro{{Notation: "2d6"}},
+			expectLabels: []string{"longsword"},
+			expectTitles: []string{"2d6"},
+		},
+		{
+			name:      "invalid",
+			macro:     Macro{{Notation: "1d20"}, {Notation: "d"}},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, entries, err := tt.macro.Roll("longsword", dice.NewSeededRoller(1))
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("Roll() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if tt.expectErr {
+				return
+			}
+			var labels []string
+			for _, e := range entries {
+				labels = append(labels, e.Label)
+			}
+			if !reflect.DeepEqual(labels, tt.expectLabels) {
+				t.Errorf("Roll() entry labels = %v, want %v", labels, tt.expectLabels)
+			}
+			for _, title := range tt.expectTitles {
+				if !strings.Contains(content, title) {
+					t.Errorf("Roll() content missing %q:\n%s", title, content)
+				}
+			}
+			if got := strings.Count(content, "Total: "); got != len(tt.macro) {
+				t.Errorf("Roll() rendered %d totals, want %d", got, len(tt.macro))
+			}
+		})
+	}
+}
+
+func TestLoadSave(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	config, err := Load()
+	if err != nil || config.Theme != DefaultTheme() || len(config.Macros) != 0 {
+		t.Fatalf("Load() without a file = %+v, %v; want the defaults", config, err)
+	}
+
+	config.Theme.FocusedColor = "#000000"
+	config.Macros = map[string]Macro{"longsword": {{Label: "attack", Notation: "1d20+7"}}, "bolt": {{Notation: "1d10"}}}
+	if err := Save(config); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	loaded, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, config) {
+		t.Errorf("Load() = %+v, want %+v", loaded, config)
+	}
+	if names := loaded.MacroNames(); !reflect.DeepEqual(names, []string{"bolt", "longsword"}) {
+		t.Errorf("MacroNames() = %v, want [bolt longsword]", names)
+	}
+	config.AutoSelect = true
+	if err := Save(config); err != nil {
+		t.Fatalf("Save() over a config failed: %v", err)
+	}
+	if loaded, err := Load(); err != nil || !loaded.AutoSelect {
+		t.Errorf("Load() after a second Save() = %+v, %v", loaded, err)
+	}
+	files, err := os.ReadDir(filepath.Dir(Path()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Name() != filepath.Base(Path()) {
+		t.Errorf("config dir holds %v, want only %s", files, filepath.Base(Path()))
+	}
+	if info, err := os.Stat(Path()); err != nil {
+		t.Error(err)
+	} else if info.Mode().Perm() != 0644 {
+		t.Errorf("config file mode = %v, want 0644", info.Mode())
+	}
+
+	broken := []byte(`{"theme": {"focused_color": "#000000"},}`)
+	if err := os.WriteFile(Path(), broken, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if config, err := Load(); err == nil || config.Theme != DefaultTheme() {
+		t.Errorf("Load() of a broken file = %+v, %v; want the defaults and an error", config, err)
+	}
+	if err := Save(Config{Theme: DefaultTheme()}); err == nil {
+		t.Errorf("Save() over a broken file should fail")
+	}
+	if raw, _ := os.ReadFile(Path()); string(raw) != string(broken) {
+		t.Errorf("Save() replaced the broken file with %s", raw)
+	}
+}
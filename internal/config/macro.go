@@ -0,0 +1,105 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"dnd-cli/internal/dice"
+	"dnd-cli/internal/rolllog"
+)
+
+// MacroRoll is one labeled roll of a macro, such as "attack: 1d20+7".
+type MacroRoll struct {
+	Label    string `json:"label,omitempty"`
+	Notation string `json:"notation"`
+}
+
+// Macro is a named sequence of rolls made together, such as an attack roll
+// followed by its damage.
+type Macro []MacroRoll
+
+// macroNamePattern restricts macro names to what can follow "@" at the prompt.
+var macroNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ValidateMacroName checks that name can be used as a macro name.
+func ValidateMacroName(name string) error {
+	if !macroNamePattern.MatchString(name) {
+		return fmt.Errorf("macro name %q must be lowercase letters, digits, '-' or '_'", name)
+	}
+	return nil
+}
+
+// ParseMacroRoll parses "label: notation" (or a bare notation) and checks
+// that the notation can be rolled.
+func ParseMacroRoll(spec string) (MacroRoll, error) {
+	var roll MacroRoll
+	if label, notation, found := strings.Cut(spec, ":"); found {
+		roll = MacroRoll{Label: strings.TrimSpace(label), Notation: strings.TrimSpace(notation)}
+	} else {
+		roll = MacroRoll{Notation: strings.TrimSpace(spec)}
+	}
+	if _, err := dice.ParseDiceNotation(roll.Notation); err != nil {
+		return MacroRoll{}, err
+	}
+	return roll, nil
+}
+
+// String renders the roll as "label: notation".
+func (r MacroRoll) String() string {
+	if r.Label == "" {
+		return r.Notation
+	}
+	return r.Label + ": " + r.Notation
+}
+
+// String renders the macro's rolls separated by commas.
+func (m Macro) String() string {
+	parts := make([]string, len(m))
+	for i, r := range m {
+		parts[i] = r.String()
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Roll makes every roll of the macro with roller and renders each breakdown.
+// It also returns a roll log entry per roll, labeled with the roll's label
+// or, failing that, the macro's name.
+func (m Macro) Roll(name string, roller *dice.Roller) (string, []rolllog.Entry, error) {
+	var blocks []string
+	var entries []rolllog.Entry
+	for _, r := range m {
+		dr, err := dice.ParseDiceNotation(r.Notation)
+		if err != nil {
+			return "", nil, err
+		}
+		total, terms := dr.RollWith(roller)
+		entry := rolllog.NewEntry(dr, total, terms)
+		entry.Label = name
+		if r.Label != "" {
+			entry.Label = name + " " + r.Label
+		}
+		entries = append(entries, entry)
+		title := dr.Notation
+		if r.Label != "" {
+			title = fmt.Sprintf("%s (%s)", r.Label, dr.Notation)
+		}
+		block := fmt.Sprintf("%s\n%s\nTotal: %d", title, dice.FormatTerms(terms), total)
+		if check := dr.Check(total, terms).String(); check != "" {
+			block += "\n" + check
+		}
+		blocks = append(blocks, block)
+	}
+	return strings.Join(blocks, "\n\n"), entries, nil
+}
+
+// MacroNames returns the names of the configured macros in sorted order.
+func (c Config) MacroNames() []string {
+	names := make([]string, 0, len(c.Macros))
+	for name := range c.Macros {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,322 @@
+package data
+
+import (
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+)
+
+// cacheVersion is the version of the cache format. Bump it when the way
+// the cache is written changes; changes to the cached types are caught by
+// schemaFingerprint.
+const cacheVersion = 1
+
+// cacheFile returns the name of the cache of the layers in the cache
+// directory: "data-" and a short hash of their tags and paths, so that each
+// combination of data directory and overlays keeps a cache of its own.
+func cacheFile(layers []layer) string {
+	h := sha256.New()
+	for _, l := range layers {
+		fmt.Fprintf(h, "%q %q\n", l.tag, l.path)
+	}
+	return fmt.Sprintf("data-%x.gob", h.Sum(nil)[:6])
+}
+
+// DefaultCacheDir returns the directory the parsed data is cached in,
+// dnd-cli under the user's cache directory ($XDG_CACHE_HOME or
+// ~/.cache on Linux), or "" if there is none.
+func DefaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "dnd-cli")
+}
+
+// CacheResult says how the cache was used by a load.
+type CacheResult string
+
+// The cache results.
+const (
+	CacheOff  CacheResult = "off"  // no cache directory was given
+	CacheHit  CacheResult = "hit"  // the data was read from the cache
+	CacheMiss CacheResult = "miss" // the data was parsed and the cache rewritten
+)
+
+// LoadStats records how long each step of a load took.
+type LoadStats struct {
+	Cache     CacheResult
+	CachePath string
+	CacheErr  error         // a cache that could not be read or written; the data loads regardless
+	Check     time.Duration // keying the cache on the source files
+	Read      time.Duration // reading the cache
+	Parse     time.Duration // reading and decoding the data files
+	Index     time.Duration // building the indexes
+	Write     time.Duration // writing the cache
+	Total     time.Duration
+}
+
+// cacheHeader starts the cache. A cache whose header doesn't match is
+// rebuilt without decoding the rest.
+type cacheHeader struct {
+	Version int
+	Key     string
+}
+
+// snapshot is the parsed and indexed data as it is cached.
+type snapshot struct {
+	Loaded      []CategoryInfo
+	Problems    []Problem
+	Spells      []Spell
+	Monsters    []Monster
+	Items       []Item
+	Species     []Species
+	Backgrounds []Background
+	Classes     []Class
+	Subclasses  []Subclass
+	Feats       []Feat
+	Conditions  []Condition
+	Rules       []Rule
+	Names       []cachedNameIndex // in Kinds order
+	Text        cachedTextIndex
+}
+
+// cachedNameIndex is a nameIndex with its fields exported for gob.
+type cachedNameIndex struct {
+	Names    []string
+	Exact    map[string]int
+	Singular map[string]int
+	Compact  map[string]int
+}
+
+// cachedTextIndex is a textIndex with its fields exported for gob.
+type cachedTextIndex struct {
+	Docs     []cachedDocument
+	Postings map[string][]cachedPosting
+	Terms    []string
+}
+
+type cachedDocument struct {
+	Kind Kind
+	Name string
+	Text string
+}
+
+type cachedPosting struct {
+	Doc    int
+	Count  int
+	InName bool
+}
+
+// nameIndexes returns the data set's name indexes in Kinds order.
+func (d *dataset) nameIndexes() []*nameIndex {
+	return []*nameIndex{
+		&d.spellIndex, &d.monsterIndex, &d.itemIndex, &d.speciesIndex, &d.backgroundIndex,
+		&d.classIndex, &d.subclassIndex, &d.featIndex, &d.conditionIndex, &d.ruleIndex,
+	}
+}
+
+// takeSnapshot captures the data set and its indexes.
+func takeSnapshot(d *dataset) snapshot {
+	s := snapshot{
+		Loaded: d.loaded, Problems: d.problems, Spells: d.spells, Monsters: d.monsters, Items: d.items,
+		Species: d.species, Backgrounds: d.backgrounds, Classes: d.classes,
+		Subclasses: d.subclasses, Feats: d.feats, Conditions: d.conditions, Rules: d.rules,
+	}
+	for _, idx := range d.nameIndexes() {
+		s.Names = append(s.Names, cachedNameIndex{Names: idx.names, Exact: idx.exact, Singular: idx.singular, Compact: idx.compact})
+	}
+	s.Text.Terms = d.fullText.terms
+	for _, doc := range d.fullText.docs {
+		s.Text.Docs = append(s.Text.Docs, cachedDocument{Kind: doc.kind, Name: doc.name, Text: doc.text})
+	}
+	s.Text.Postings = make(map[string][]cachedPosting, len(d.fullText.postings))
+	for term, postings := range d.fullText.postings {
+		cached := make([]cachedPosting, len(postings))
+		for i, p := range postings {
+			cached[i] = cachedPosting{Doc: p.doc, Count: p.count, InName: p.inName}
+		}
+		s.Text.Postings[term] = cached
+	}
+	return s
+}
+
+// restore rebuilds the data set the snapshot captured.
+func (s snapshot) restore() (*dataset, error) {
+	d := &dataset{
+		loaded: s.Loaded, problems: s.Problems, spells: s.Spells, monsters: s.Monsters, items: s.Items,
+		species: s.Species, backgrounds: s.Backgrounds, classes: s.Classes,
+		subclasses: s.Subclasses, feats: s.Feats, conditions: s.Conditions, rules: s.Rules,
+	}
+	indexes := d.nameIndexes()
+	if len(s.Names) != len(indexes) {
+		return nil, fmt.Errorf("cache holds %d name indexes, want %d", len(s.Names), len(indexes))
+	}
+	for i, idx := range indexes {
+		*idx = nameIndex{names: s.Names[i].Names, exact: s.Names[i].Exact, singular: s.Names[i].Singular, compact: s.Names[i].Compact}
+		if idx.exact == nil {
+			idx.exact = make(map[string]int)
+		}
+		if idx.singular == nil {
+			idx.singular = make(map[string]int)
+		}
+		if idx.compact == nil {
+			idx.compact = make(map[string]int)
+		}
+	}
+	text := &textIndex{terms: s.Text.Terms, postings: make(map[string][]posting, len(s.Text.Postings))}
+	for _, doc := range s.Text.Docs {
+		text.docs = append(text.docs, document{kind: doc.Kind, name: doc.Name, text: doc.Text})
+	}
+	for term, cached := range s.Text.Postings {
+		postings := make([]posting, len(cached))
+		for i, p := range cached {
+			postings[i] = posting{doc: p.Doc, count: p.Count, inName: p.InName}
+		}
+		text.postings[term] = postings
+	}
+	d.fullText = text
+	return d, nil
+}
+
+// cacheKey identifies the data the layers hold: the files of the embedded
+// data by their contents and those on disk by size and modification time,
+// along with each layer's tag, path and version, the cache version and
+// the shape of the cached types.
+func cacheKey(layers []layer) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "v%d %s\n", cacheVersion, schemaFingerprint())
+	for _, l := range layers {
+		fmt.Fprintf(h, "layer %q %q %q %t\n", l.tag, l.path, l.version, l.overlay)
+		for _, c := range categories() {
+			for _, path := range c.files(l) {
+				fmt.Fprintf(h, "%q ", path)
+				writeFileStamp(h, l, path)
+			}
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeFileStamp writes what identifies a version of the file to h: the
+// hash of an embedded file and the size and modification time of one on
+// disk.
+func writeFileStamp(h hash.Hash, l layer, path string) {
+	if l.path == "" {
+		raw, err := srdFiles.ReadFile(path)
+		if err != nil {
+			fmt.Fprintln(h, "missing")
+			return
+		}
+		fmt.Fprintf(h, "%x\n", sha256.Sum256(raw))
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		fmt.Fprintln(h, "missing")
+		return
+	}
+	fmt.Fprintf(h, "%d %d\n", info.Size(), info.ModTime().UnixNano())
+}
+
+// schemaFingerprint describes the cached types, field by field, so that a
+// build whose types differ from the one that wrote the cache rebuilds it.
+func schemaFingerprint() string {
+	h := sha256.New()
+	seen := make(map[reflect.Type]bool)
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		fmt.Fprintf(h, "%s;", t)
+		if seen[t] {
+			return
+		}
+		seen[t] = true
+		switch t.Kind() {
+		case reflect.Struct:
+			for i := 0; i < t.NumField(); i++ {
+				fmt.Fprintf(h, "%s:", t.Field(i).Name)
+				walk(t.Field(i).Type)
+			}
+		case reflect.Slice, reflect.Array, reflect.Pointer:
+			walk(t.Elem())
+		case reflect.Map:
+			walk(t.Key())
+			walk(t.Elem())
+		}
+	}
+	walk(reflect.TypeOf(snapshot{}))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// errStaleCache is returned by readCache for a cache of other data.
+var errStaleCache = errors.New("cache is out of date")
+
+// readCache reads the data set from the cache at path if it was written
+// for key. A missing or stale cache returns an error wrapping
+// os.ErrNotExist or errStaleCache.
+func readCache(path, key string) (*dataset, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	dec := gob.NewDecoder(f)
+	var header cacheHeader
+	if err := dec.Decode(&header); err != nil {
+		return nil, fmt.Errorf("failed to read cache %s: %w", path, err)
+	}
+	if header.Version != cacheVersion || header.Key != key {
+		return nil, errStaleCache
+	}
+	var s snapshot
+	if err := dec.Decode(&s); err != nil {
+		return nil, fmt.Errorf("failed to read cache %s: %w", path, err)
+	}
+	return s.restore()
+}
+
+// writeCache saves the data set to the cache at path under key. The cache
+// is written to a temporary file first, so that a reader never sees half
+// of one.
+func writeCache(path, key string, d *dataset) (err error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+	if err := encodeCache(f, key, d); err != nil {
+		return fmt.Errorf("failed to write cache %s: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write cache %s: %w", path, err)
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("failed to write cache %s: %w", path, err)
+	}
+	return nil
+}
+
+// encodeCache writes the header and the snapshot of the data set.
+func encodeCache(w io.Writer, key string, d *dataset) error {
+	enc := gob.NewEncoder(w)
+	if err := enc.Encode(cacheHeader{Version: cacheVersion, Key: key}); err != nil {
+		return err
+	}
+	return enc.Encode(takeSnapshot(d))
+}
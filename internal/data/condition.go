@@ -0,0 +1,92 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Condition represents the structure of a condition from conditions.json.
+// Effects holds its rules as written; the other fields restate the common
+// mechanical effects in a form tools can act on.
+type Condition struct {
+	Name           string   `json:"name"`
+	Description    string   `json:"description"`
+	Effects        []string `json:"effects"`
+	Includes       []string `json:"includes,omitempty"` // conditions it imposes as well, e.g. Incapacitated
+	SpeedZero      bool     `json:"speed_zero,omitempty"`
+	AttackRolls    RollMode `json:"attack_rolls,omitempty"`    // on the creature's attack rolls
+	AttacksAgainst RollMode `json:"attacks_against,omitempty"` // on attack rolls against the creature
+	AbilityChecks  RollMode `json:"ability_checks,omitempty"`  // on the creature's ability checks
+	FailsSaves     []string `json:"fails_saves,omitempty"`     // abilities whose saving throws it automatically fails
+	Publisher      string   `json:"publisher,omitempty"`
+	Book           string   `json:"book,omitempty"`
+	Source         string   `json:"-"` // tag of the source it was loaded from
+}
+
+// RollMode says whether a d20 roll is made with advantage or disadvantage.
+type RollMode string
+
+// The roll modes. The zero value leaves the roll alone.
+const (
+	Advantage    RollMode = "advantage"
+	Disadvantage RollMode = "disadvantage"
+)
+
+// UnmarshalJSON accepts "advantage" or "disadvantage" in any case.
+func (r *RollMode) UnmarshalJSON(b []byte) error {
+	var text string
+	if err := json.Unmarshal(b, &text); err != nil {
+		return err
+	}
+	switch mode := RollMode(strings.ToLower(strings.TrimSpace(text))); mode {
+	case "", Advantage, Disadvantage:
+		*r = mode
+		return nil
+	}
+	return fmt.Errorf("invalid roll mode %q; use advantage or disadvantage", text)
+}
+
+// Mechanics summarises the condition's mechanical effects as lines such
+// as "Speed: 0" and "Attacks against: advantage", or nil if it has none.
+func (c *Condition) Mechanics() []string {
+	var lines []string
+	if len(c.Includes) > 0 {
+		lines = append(lines, "Also: "+strings.Join(c.Includes, ", "))
+	}
+	if c.SpeedZero {
+		lines = append(lines, "Speed: 0")
+	}
+	add := func(label string, mode RollMode) {
+		if mode != "" {
+			lines = append(lines, label+": "+string(mode))
+		}
+	}
+	add("Attack rolls", c.AttackRolls)
+	add("Attacks against", c.AttacksAgainst)
+	add("Ability checks", c.AbilityChecks)
+	if len(c.FailsSaves) > 0 {
+		lines = append(lines, "Fails saves: "+strings.Join(c.FailsSaves, ", "))
+	}
+	return lines
+}
+
+// Card renders the condition for display: its description, effects and a
+// summary of its mechanics.
+func (c *Condition) Card() string {
+	var b strings.Builder
+	if c.Description != "" {
+		fmt.Fprintf(&b, "%s\n", c.Description)
+	}
+	if len(c.Effects) > 0 {
+		b.WriteString("\n")
+		for _, effect := range c.Effects {
+			fmt.Fprintf(&b, "- %s\n", effect)
+		}
+	}
+	if mechanics := c.Mechanics(); len(mechanics) > 0 {
+		fmt.Fprintf(&b, "\n%s\n", strings.Join(mechanics, "\n"))
+	}
+	writeSource(&b, c.Book, c.Publisher, c.Source)
+	return strings.TrimSpace(b.String())
+}
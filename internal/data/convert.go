@@ -0,0 +1,886 @@
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ImportFormat is a format content can be imported from.
+type ImportFormat string
+
+// The formats Import understands.
+const (
+	FormatOpen5e ImportFormat = "open5e" // the Open5e API
+	Format5eSRD  ImportFormat = "5e-srd" // the 5e-SRD-API and its 5e-database
+)
+
+// ImportFormats lists the formats Import understands.
+var ImportFormats = []ImportFormat{FormatOpen5e, Format5eSRD}
+
+// Imported holds the entries converted from a file and what was lost.
+type Imported struct {
+	Spells   []Spell
+	Monsters []Monster
+	Items    []Item
+	Skipped  map[Kind]map[string]int // fields left out, with how many entries had them
+	Failed   []string                // entries that could not be converted, and why
+}
+
+// Import converts a dump in the given format into spells, monsters and
+// items. raw may hold a list of entries, a single entry or an API page
+// with the entries under "results". Each entry's kind is worked out from
+// its fields unless kind is set. Every converted entry is decoded as if
+// it had been loaded, so what Import returns is what a load would see.
+func Import(format ImportFormat, raw []byte, kind Kind) (*Imported, error) {
+	converters, ok := importers[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q; use %s or %s", format, FormatOpen5e, Format5eSRD)
+	}
+	if kind != "" && converters[kind].convert == nil {
+		return nil, fmt.Errorf("cannot import %s entries; only spells, monsters and items", kind)
+	}
+	entries, err := dumpEntries(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	im := &Imported{Skipped: make(map[Kind]map[string]int)}
+	for i, e := range entries {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(e, &fields); err != nil {
+			im.Failed = append(im.Failed, fmt.Sprintf("entry %d: not an object", i+1))
+			continue
+		}
+		label := fmt.Sprintf("entry %d", i+1)
+		var head struct {
+			Name string `json:"name"`
+		}
+		if json.Unmarshal(e, &head) == nil && head.Name != "" {
+			label = head.Name
+		}
+
+		k := kind
+		if k == "" {
+			k = sniffKind(fields)
+		}
+		c, ok := converters[k]
+		if !ok {
+			im.Failed = append(im.Failed, fmt.Sprintf("%s: cannot tell whether it is a spell, monster or item", label))
+			continue
+		}
+		converted, err := c.convert(e)
+		if err == nil {
+			err = im.add(k, converted)
+		}
+		if err != nil {
+			im.Failed = append(im.Failed, fmt.Sprintf("%s: %v", label, err))
+			continue
+		}
+		for field, value := range fields {
+			if !c.fields[field] && !c.ignored[field] && !isEmptyJSON(value) {
+				if im.Skipped[k] == nil {
+					im.Skipped[k] = make(map[string]int)
+				}
+				im.Skipped[k][field]++
+			}
+		}
+	}
+	return im, nil
+}
+
+// add decodes a converted entry as the loader would and keeps it if it
+// decodes.
+func (im *Imported) add(kind Kind, e entry) error {
+	raw, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	switch kind {
+	case KindSpell:
+		var s Spell
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return err
+		}
+		im.Spells = append(im.Spells, s)
+	case KindMonster:
+		var m Monster
+		if err := json.Unmarshal(raw, &m); err != nil {
+			return err
+		}
+		im.Monsters = append(im.Monsters, m)
+	case KindItem:
+		var it Item
+		if err := json.Unmarshal(raw, &it); err != nil {
+			return err
+		}
+		im.Items = append(im.Items, it)
+	}
+	return nil
+}
+
+// Save writes the entries into an overlay directory, one file per kind
+// named after the imported file: spells/<name>.json, monsters/<name>.json
+// and items/<name>.json. It returns the files written.
+func (im *Imported) Save(dir, name string) ([]string, error) {
+	dir = expandHome(dir)
+	var written []string
+	save := func(kind string, n int, entries interface{}) error {
+		if n == 0 {
+			return nil
+		}
+		path := filepath.Join(dir, kind, name+".json")
+		raw, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, append(raw, '\n'), 0644); err != nil {
+			return err
+		}
+		written = append(written, path)
+		return nil
+	}
+	if err := save("spells", len(im.Spells), im.Spells); err != nil {
+		return written, err
+	}
+	if err := save("monsters", len(im.Monsters), im.Monsters); err != nil {
+		return written, err
+	}
+	if err := save("items", len(im.Items), im.Items); err != nil {
+		return written, err
+	}
+	return written, nil
+}
+
+// SkippedFields lists the fields left out of entries of the given kind,
+// most common first, as "field (n)".
+func (im *Imported) SkippedFields(kind Kind) []string {
+	var fields []string
+	for field := range im.Skipped[kind] {
+		fields = append(fields, field)
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		a, b := im.Skipped[kind][fields[i]], im.Skipped[kind][fields[j]]
+		if a != b {
+			return a > b
+		}
+		return fields[i] < fields[j]
+	})
+	for i, field := range fields {
+		fields[i] = fmt.Sprintf("%s (%d)", field, im.Skipped[kind][field])
+	}
+	return fields
+}
+
+// dumpEntries splits a dump into its entries.
+func dumpEntries(raw []byte) ([]json.RawMessage, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) > 0 && raw[0] == '[' {
+		var entries []json.RawMessage
+		if err := json.Unmarshal(raw, &entries); err != nil {
+			return nil, err
+		}
+		return entries, nil
+	}
+	var page struct {
+		Results []json.RawMessage `json:"results"`
+	}
+	if err := json.Unmarshal(raw, &page); err != nil {
+		return nil, err
+	}
+	if page.Results != nil {
+		return page.Results, nil
+	}
+	return []json.RawMessage{raw}, nil
+}
+
+// sniffKind works out from its fields whether an entry is a spell, a
+// monster or an item, or returns "".
+func sniffKind(fields map[string]json.RawMessage) Kind {
+	has := func(names ...string) bool {
+		for _, name := range names {
+			if _, ok := fields[name]; ok {
+				return true
+			}
+		}
+		return false
+	}
+	switch {
+	case has("school") && has("casting_time", "level", "level_int"):
+		return KindSpell
+	case has("challenge_rating", "hit_points"):
+		return KindMonster
+	case has("rarity", "equipment_category", "damage_dice", "base_ac", "armor_category", "weapon_category", "cost"):
+		return KindItem
+	}
+	return ""
+}
+
+// isEmptyJSON reports whether a value carries no information: null, false,
+// zero, "" or an empty list or object.
+func isEmptyJSON(raw json.RawMessage) bool {
+	switch string(bytes.TrimSpace(raw)) {
+	case "null", "false", "0", `""`, "[]", "{}":
+		return true
+	}
+	return false
+}
+
+// converter turns one entry of a foreign format into an entry.
+type converter struct {
+	fields  map[string]bool // the source fields the conversion uses
+	ignored map[string]bool // fields not worth reporting: ids, links, duplicates
+	convert func(raw []byte) (entry, error)
+}
+
+// newConverter builds a converter from source type S. Fields of S are the
+// ones used; ignored lists the others that are not worth reporting.
+func newConverter[S any](convert func(*S) (entry, error), ignored ...string) converter {
+	c := converter{
+		fields:  make(map[string]bool),
+		ignored: make(map[string]bool),
+		convert: func(raw []byte) (entry, error) {
+			var s S
+			if err := json.Unmarshal(raw, &s); err != nil {
+				return nil, err
+			}
+			return convert(&s)
+		},
+	}
+	t := reflect.TypeOf((*S)(nil)).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		c.fields[name] = true
+	}
+	for _, field := range ignored {
+		c.ignored[field] = true
+	}
+	return c
+}
+
+// Fields of every entry of a format that are not worth reporting.
+var (
+	open5eIgnored = []string{"slug", "page", "page_no", "img_main", "document__slug", "document__url", "document__license_url", "document__title_url"}
+	srdIgnored    = []string{"index", "url", "_id", "updated_at", "image"}
+)
+
+// importers holds the converters of each format by kind.
+var importers = map[ImportFormat]map[Kind]converter{
+	FormatOpen5e: {
+		KindSpell: newConverter(convertOpen5eSpell, append(open5eIgnored, "level_int", "spell_level", "can_be_cast_as_ritual", "requires_concentration",
+			"requires_verbal_components", "requires_somatic_components", "requires_material_components", "target_range_sort", "spell_lists")...),
+		KindMonster: newConverter(convertOpen5eMonster, append(open5eIgnored, "cr")...),
+		KindItem:    newConverter(convertOpen5eItem, append(open5eIgnored, "ac_string")...),
+	},
+	Format5eSRD: {
+		KindSpell:   newConverter(convertSRDSpell, srdIgnored...),
+		KindMonster: newConverter(convertSRDMonster, append(srdIgnored, "proficiency_bonus")...),
+		KindItem:    newConverter(convertSRDItem, append(srdIgnored, "weapon_range", "category_range", "gear_category")...),
+	},
+}
+
+// open5eSpell is a spell from the Open5e API.
+type open5eSpell struct {
+	Name          string `json:"name"`
+	Desc          string `json:"desc"`
+	HigherLevel   string `json:"higher_level"`
+	Range         string `json:"range"`
+	Components    string `json:"components"`
+	Material      string `json:"material"`
+	Ritual        string `json:"ritual"` // "yes" or "no"
+	Duration      string `json:"duration"`
+	Concentration string `json:"concentration"` // "yes" or "no"
+	CastingTime   string `json:"casting_time"`
+	Level         string `json:"level"` // "Cantrip", "3rd-level"
+	School        string `json:"school"`
+	DndClass      string `json:"dnd_class"` // "Sorcerer, Wizard"
+	DocumentTitle string `json:"document__title"`
+}
+
+func convertOpen5eSpell(o *open5eSpell) (entry, error) {
+	level, err := ParseSpellLevel(o.Level)
+	if err != nil {
+		return nil, err
+	}
+	s := &Spell{
+		Name:          o.Name,
+		Description:   o.Desc,
+		Level:         level,
+		School:        o.School,
+		CastingTime:   o.CastingTime,
+		Range:         o.Range,
+		Duration:      o.Duration,
+		Concentration: isYes(o.Concentration),
+		Ritual:        isYes(o.Ritual),
+		Classes:       splitList(o.DndClass),
+		HigherLevels:  o.HigherLevel,
+		Book:          o.DocumentTitle,
+	}
+	if o.Components != "" {
+		if s.Components, err = ParseComponents(o.Components); err != nil {
+			return nil, err
+		}
+	}
+	if o.Material != "" {
+		s.Components.Text = strings.TrimSuffix(o.Material, ".")
+	}
+	return s, nil
+}
+
+// open5eAction is a trait, action, reaction or legendary action from the
+// Open5e API.
+type open5eAction struct {
+	Name string `json:"name"`
+	Desc string `json:"desc"`
+}
+
+// open5eActions is a list of Open5e actions, which the API gives as ""
+// when there are none.
+type open5eActions []open5eAction
+
+func (a *open5eActions) UnmarshalJSON(raw []byte) error {
+	if string(bytes.TrimSpace(raw)) == `""` {
+		*a = nil
+		return nil
+	}
+	return json.Unmarshal(raw, (*[]open5eAction)(a))
+}
+
+// open5eMonster is a monster from the Open5e API.
+type open5eMonster struct {
+	Name                  string                 `json:"name"`
+	Desc                  string                 `json:"desc"`
+	Size                  string                 `json:"size"`
+	Type                  string                 `json:"type"`
+	Subtype               string                 `json:"subtype"`
+	Alignment             string                 `json:"alignment"`
+	ArmorClass            int                    `json:"armor_class"`
+	ArmorDesc             string                 `json:"armor_desc"`
+	HitPoints             int                    `json:"hit_points"`
+	HitDice               string                 `json:"hit_dice"`
+	Speed                 map[string]interface{} `json:"speed"`
+	Strength              int                    `json:"strength"`
+	Dexterity             int                    `json:"dexterity"`
+	Constitution          int                    `json:"constitution"`
+	Intelligence          int                    `json:"intelligence"`
+	Wisdom                int                    `json:"wisdom"`
+	Charisma              int                    `json:"charisma"`
+	StrengthSave          *int                   `json:"strength_save"`
+	DexteritySave         *int                   `json:"dexterity_save"`
+	ConstitutionSave      *int                   `json:"constitution_save"`
+	IntelligenceSave      *int                   `json:"intelligence_save"`
+	WisdomSave            *int                   `json:"wisdom_save"`
+	CharismaSave          *int                   `json:"charisma_save"`
+	Skills                map[string]int         `json:"skills"`
+	DamageVulnerabilities string                 `json:"damage_vulnerabilities"`
+	DamageResistances     string                 `json:"damage_resistances"`
+	DamageImmunities      string                 `json:"damage_immunities"`
+	ConditionImmunities   string                 `json:"condition_immunities"`
+	Senses                string                 `json:"senses"`
+	Languages             string                 `json:"languages"`
+	ChallengeRating       ChallengeRating        `json:"challenge_rating"`
+	SpecialAbilities      open5eActions          `json:"special_abilities"`
+	Actions               open5eActions          `json:"actions"`
+	BonusActions          open5eActions          `json:"bonus_actions"`
+	Reactions             open5eActions          `json:"reactions"`
+	LegendaryActions      open5eActions          `json:"legendary_actions"`
+	Environments          []string               `json:"environments"`
+	DocumentTitle         string                 `json:"document__title"`
+}
+
+func convertOpen5eMonster(o *open5eMonster) (entry, error) {
+	m := &Monster{
+		Name:                o.Name,
+		Description:         o.Desc,
+		Size:                o.Size,
+		Type:                o.Type,
+		Subtype:             o.Subtype,
+		Alignment:           o.Alignment,
+		ArmorClass:          o.ArmorClass,
+		ArmorDesc:           o.ArmorDesc,
+		HitPoints:           o.HitPoints,
+		HitDice:             o.HitDice,
+		Speed:               formatSpeed(o.Speed),
+		AbilityScores:       AbilityScores{o.Strength, o.Dexterity, o.Constitution, o.Intelligence, o.Wisdom, o.Charisma},
+		Vulnerabilities:     o.DamageVulnerabilities,
+		Resistances:         o.DamageResistances,
+		Immunities:          o.DamageImmunities,
+		ConditionImmunities: o.ConditionImmunities,
+		Senses:              o.Senses,
+		Languages:           o.Languages,
+		ChallengeRating:     o.ChallengeRating,
+		Environments:        o.Environments,
+		Traits:              open5eFeatures(o.SpecialAbilities, ""),
+		Actions:             append(open5eFeatures(o.Actions, ""), open5eFeatures(o.BonusActions, " (Bonus Action)")...),
+		Reactions:           open5eFeatures(o.Reactions, ""),
+		LegendaryActions:    open5eFeatures(o.LegendaryActions, ""),
+		Book:                o.DocumentTitle,
+	}
+	saves := []*int{o.StrengthSave, o.DexteritySave, o.ConstitutionSave, o.IntelligenceSave, o.WisdomSave, o.CharismaSave}
+	for i, save := range saves {
+		if save != nil {
+			if m.SavingThrows == nil {
+				m.SavingThrows = make(map[string]int)
+			}
+			m.SavingThrows[capitalize(strings.ToLower(abilityNames[i]))] = *save
+		}
+	}
+	for skill, bonus := range o.Skills {
+		if m.Skills == nil {
+			m.Skills = make(map[string]int)
+		}
+		m.Skills[capitalize(skill)] = bonus
+	}
+	return m, nil
+}
+
+// open5eFeatures converts Open5e actions, adding suffix to each name.
+func open5eFeatures(actions open5eActions, suffix string) []Feature {
+	var features []Feature
+	for _, a := range actions {
+		features = append(features, Feature{Name: a.Name + suffix, Description: a.Desc})
+	}
+	return features
+}
+
+// open5eItem is a magic item, weapon or suit of armor from the Open5e API,
+// which serves each from its own endpoint.
+type open5eItem struct {
+	Name                string   `json:"name"`
+	Desc                string   `json:"desc"`
+	Type                string   `json:"type"` // magic items: "Wondrous item", "Armor (plate)"
+	Rarity              string   `json:"rarity"`
+	RequiresAttunement  string   `json:"requires_attunement"` // "requires attunement by a cleric"
+	Category            string   `json:"category"`            // "Simple Melee Weapons", "Light Armor"
+	Cost                string   `json:"cost"`
+	Weight              string   `json:"weight"` // "3 lb."
+	DamageDice          string   `json:"damage_dice"`
+	DamageType          string   `json:"damage_type"`
+	Properties          []string `json:"properties"`
+	BaseAC              int      `json:"base_ac"`
+	PlusDexMod          bool     `json:"plus_dex_mod"`
+	PlusMax             int      `json:"plus_max"`
+	StrengthRequirement *int     `json:"strength_requirement"`
+	StealthDisadvantage bool     `json:"stealth_disadvantage"`
+	DocumentTitle       string   `json:"document__title"`
+}
+
+func convertOpen5eItem(o *open5eItem) (entry, error) {
+	it := &Item{
+		Name:        o.Name,
+		Description: o.Desc,
+		Category:    o.Type,
+		Rarity:      o.Rarity,
+		Book:        o.DocumentTitle,
+	}
+	it.Attunement, it.AttunedBy = parseAttunement(o.RequiresAttunement)
+	var err error
+	if o.Cost != "" {
+		if it.Cost, err = ParseCost(o.Cost); err != nil {
+			return nil, err
+		}
+	}
+	if it.Weight, err = parseWeight(o.Weight); err != nil {
+		return nil, err
+	}
+
+	category := strings.TrimSpace(o.Category)
+	switch {
+	case o.DamageDice != "" || strings.HasSuffix(category, "Weapons"):
+		it.Weapon = &Weapon{
+			Category:   strings.TrimSuffix(category, " Weapons"),
+			Damage:     o.DamageDice,
+			DamageType: o.DamageType,
+			Properties: o.Properties,
+		}
+	case o.BaseAC > 0 || strings.HasSuffix(category, "Armor") || category == "Shield":
+		a := &Armor{Category: strings.TrimSuffix(category, " Armor"), BaseAC: o.BaseAC, StealthDisadvantage: o.StealthDisadvantage}
+		if a.Category == "Shield" && a.BaseAC > 10 {
+			a.BaseAC -= 10 // listed as the AC it gives an unarmored wearer
+		}
+		if a.Category != "Shield" {
+			switch {
+			case !o.PlusDexMod:
+				a.DexCap = new(int)
+			case o.PlusMax > 0:
+				a.DexCap = &o.PlusMax
+			}
+		}
+		if o.StrengthRequirement != nil {
+			a.StrRequirement = *o.StrengthRequirement
+		}
+		it.Armor = a
+	}
+	return it, nil
+}
+
+// parseAttunement reads "requires attunement" or "requires attunement by
+// a spellcaster", anywhere in text.
+func parseAttunement(text string) (attunement bool, by string) {
+	const phrase = "requires attunement"
+	i := strings.Index(strings.ToLower(text), phrase)
+	if i < 0 {
+		return false, ""
+	}
+	rest := strings.TrimSpace(strings.TrimRight(text[i+len(phrase):], ").; "))
+	if len(rest) > 3 && strings.EqualFold(rest[:3], "by ") {
+		return true, strings.TrimSpace(rest[3:])
+	}
+	return true, ""
+}
+
+// srdRef is a link to another resource of the 5e-SRD-API.
+type srdRef struct {
+	Name string `json:"name"`
+}
+
+// srdNames returns the names of refs.
+func srdNames(refs []srdRef) []string {
+	var names []string
+	for _, r := range refs {
+		names = append(names, r.Name)
+	}
+	return names
+}
+
+// srdSpell is a spell from the 5e-SRD-API.
+type srdSpell struct {
+	Name          string   `json:"name"`
+	Desc          []string `json:"desc"`
+	HigherLevel   []string `json:"higher_level"`
+	Range         string   `json:"range"`
+	Components    []string `json:"components"` // "V", "S", "M"
+	Material      string   `json:"material"`
+	Ritual        bool     `json:"ritual"`
+	Duration      string   `json:"duration"`
+	Concentration bool     `json:"concentration"`
+	CastingTime   string   `json:"casting_time"`
+	Level         int      `json:"level"`
+	School        srdRef   `json:"school"`
+	Classes       []srdRef `json:"classes"`
+}
+
+func convertSRDSpell(o *srdSpell) (entry, error) {
+	s := &Spell{
+		Name:          o.Name,
+		Description:   strings.Join(o.Desc, "\n\n"),
+		Level:         o.Level,
+		School:        o.School.Name,
+		CastingTime:   o.CastingTime,
+		Range:         o.Range,
+		Duration:      o.Duration,
+		Concentration: o.Concentration,
+		Ritual:        o.Ritual,
+		Classes:       srdNames(o.Classes),
+		HigherLevels:  strings.Join(o.HigherLevel, "\n\n"),
+	}
+	if len(o.Components) > 0 {
+		var err error
+		if s.Components, err = ParseComponents(strings.Join(o.Components, ", ")); err != nil {
+			return nil, err
+		}
+	}
+	s.Components.Text = strings.TrimSuffix(o.Material, ".")
+	return s, nil
+}
+
+// srdAction is a trait, action, reaction or legendary action from the
+// 5e-SRD-API.
+type srdAction struct {
+	Name string `json:"name"`
+	Desc string `json:"desc"`
+}
+
+// srdMonster is a monster from the 5e-SRD-API.
+type srdMonster struct {
+	Name          string                 `json:"name"`
+	Desc          string                 `json:"desc"`
+	Size          string                 `json:"size"`
+	Type          string                 `json:"type"`
+	Subtype       string                 `json:"subtype"`
+	Alignment     string                 `json:"alignment"`
+	ArmorClass    json.RawMessage        `json:"armor_class"` // a number, or a list of {type, value}
+	HitPoints     int                    `json:"hit_points"`
+	HitDice       string                 `json:"hit_dice"`
+	HitPointsRoll string                 `json:"hit_points_roll"`
+	Speed         map[string]interface{} `json:"speed"`
+	Strength      int                    `json:"strength"`
+	Dexterity     int                    `json:"dexterity"`
+	Constitution  int                    `json:"constitution"`
+	Intelligence  int                    `json:"intelligence"`
+	Wisdom        int                    `json:"wisdom"`
+	Charisma      int                    `json:"charisma"`
+	Proficiencies []struct {
+		Value       int    `json:"value"`
+		Proficiency srdRef `json:"proficiency"` // "Saving Throw: DEX", "Skill: Stealth"
+	} `json:"proficiencies"`
+	DamageVulnerabilities []string               `json:"damage_vulnerabilities"`
+	DamageResistances     []string               `json:"damage_resistances"`
+	DamageImmunities      []string               `json:"damage_immunities"`
+	ConditionImmunities   []srdRef               `json:"condition_immunities"`
+	Senses                map[string]interface{} `json:"senses"`
+	Languages             string                 `json:"languages"`
+	ChallengeRating       ChallengeRating        `json:"challenge_rating"`
+	XP                    int                    `json:"xp"`
+	SpecialAbilities      []srdAction            `json:"special_abilities"`
+	Actions               []srdAction            `json:"actions"`
+	Reactions             []srdAction            `json:"reactions"`
+	LegendaryActions      []srdAction            `json:"legendary_actions"`
+}
+
+func convertSRDMonster(o *srdMonster) (entry, error) {
+	m := &Monster{
+		Name:                o.Name,
+		Description:         o.Desc,
+		Size:                o.Size,
+		Type:                o.Type,
+		Subtype:             o.Subtype,
+		Alignment:           o.Alignment,
+		HitPoints:           o.HitPoints,
+		HitDice:             o.HitDice,
+		Speed:               formatSpeed(o.Speed),
+		AbilityScores:       AbilityScores{o.Strength, o.Dexterity, o.Constitution, o.Intelligence, o.Wisdom, o.Charisma},
+		Vulnerabilities:     strings.Join(o.DamageVulnerabilities, ", "),
+		Resistances:         strings.Join(o.DamageResistances, ", "),
+		Immunities:          strings.Join(o.DamageImmunities, ", "),
+		ConditionImmunities: strings.ToLower(strings.Join(srdNames(o.ConditionImmunities), ", ")),
+		Senses:              formatSenses(o.Senses),
+		Languages:           o.Languages,
+		ChallengeRating:     o.ChallengeRating,
+		XP:                  o.XP,
+		Traits:              srdFeatures(o.SpecialAbilities),
+		Actions:             srdFeatures(o.Actions),
+		Reactions:           srdFeatures(o.Reactions),
+		LegendaryActions:    srdFeatures(o.LegendaryActions),
+	}
+	if m.HitDice == "" {
+		m.HitDice = o.HitPointsRoll
+	}
+	var err error
+	if m.ArmorClass, m.ArmorDesc, err = srdArmorClass(o.ArmorClass); err != nil {
+		return nil, err
+	}
+	for _, p := range o.Proficiencies {
+		kind, name, _ := strings.Cut(p.Proficiency.Name, ":")
+		name = strings.TrimSpace(name)
+		switch strings.TrimSpace(kind) {
+		case "Saving Throw":
+			if m.SavingThrows == nil {
+				m.SavingThrows = make(map[string]int)
+			}
+			m.SavingThrows[capitalize(strings.ToLower(name))] = p.Value
+		case "Skill":
+			if m.Skills == nil {
+				m.Skills = make(map[string]int)
+			}
+			m.Skills[name] = p.Value
+		}
+	}
+	return m, nil
+}
+
+// srdArmorClass reads a 5e-SRD-API armor class: a number or, in newer
+// dumps, a list of {type, value, armor} of which the first is used.
+func srdArmorClass(raw json.RawMessage) (int, string, error) {
+	if isEmptyJSON(raw) {
+		return 0, "", nil
+	}
+	var ac int
+	if err := json.Unmarshal(raw, &ac); err == nil {
+		return ac, "", nil
+	}
+	var list []struct {
+		Type  string   `json:"type"`
+		Value int      `json:"value"`
+		Armor []srdRef `json:"armor"`
+		Desc  string   `json:"desc"`
+	}
+	if err := json.Unmarshal(raw, &list); err != nil || len(list) == 0 {
+		return 0, "", fmt.Errorf("invalid armor class %s", raw)
+	}
+	first := list[0]
+	desc := strings.ToLower(strings.Join(srdNames(first.Armor), ", "))
+	switch {
+	case desc != "":
+	case first.Desc != "":
+		desc = first.Desc
+	case first.Type != "" && first.Type != "dex":
+		desc = first.Type + " armor"
+	}
+	return first.Value, desc, nil
+}
+
+// srdFeatures converts 5e-SRD-API actions.
+func srdFeatures(actions []srdAction) []Feature {
+	var features []Feature
+	for _, a := range actions {
+		features = append(features, Feature{Name: a.Name, Description: a.Desc})
+	}
+	return features
+}
+
+// srdItem is a piece of equipment or a magic item from the 5e-SRD-API.
+type srdItem struct {
+	Name              string   `json:"name"`
+	Desc              []string `json:"desc"`
+	EquipmentCategory srdRef   `json:"equipment_category"`
+	Rarity            *srdRef  `json:"rarity"`
+	Cost              *struct {
+		Quantity int    `json:"quantity"`
+		Unit     string `json:"unit"`
+	} `json:"cost"`
+	Weight         float64 `json:"weight"`
+	WeaponCategory string  `json:"weapon_category"` // "Simple"
+	WeaponRange    string  `json:"weapon_range"`    // "Melee"
+	Damage         *struct {
+		DamageDice string `json:"damage_dice"`
+		DamageType srdRef `json:"damage_type"`
+	} `json:"damage"`
+	TwoHandedDamage *struct {
+		DamageDice string `json:"damage_dice"`
+	} `json:"two_handed_damage"`
+	Range      *srdRange `json:"range"`
+	ThrowRange *srdRange `json:"throw_range"`
+	Properties []srdRef  `json:"properties"`
+
+	ArmorCategory string `json:"armor_category"` // "Light", "Shield"
+	ArmorClass    *struct {
+		Base     int  `json:"base"`
+		DexBonus bool `json:"dex_bonus"`
+		MaxBonus *int `json:"max_bonus"`
+	} `json:"armor_class"`
+	StrMinimum          int  `json:"str_minimum"`
+	StealthDisadvantage bool `json:"stealth_disadvantage"`
+}
+
+// srdRange is a weapon's range in feet.
+type srdRange struct {
+	Normal int `json:"normal"`
+	Long   int `json:"long"`
+}
+
+func convertSRDItem(o *srdItem) (entry, error) {
+	it := &Item{
+		Name:     o.Name,
+		Category: strings.TrimSuffix(o.EquipmentCategory.Name, "s"), // "Wondrous Items"
+		Weight:   o.Weight,
+	}
+	desc := o.Desc
+	if o.Rarity != nil {
+		it.Rarity = o.Rarity.Name
+		// Magic items open with a line such as "Wondrous item, uncommon
+		// (requires attunement)".
+		if len(desc) > 0 && strings.Contains(strings.ToLower(desc[0]), strings.ToLower(it.Rarity)) {
+			kind, _, _ := strings.Cut(desc[0], ",")
+			it.Category = strings.TrimSpace(kind)
+			it.Attunement, it.AttunedBy = parseAttunement(desc[0])
+			desc = desc[1:]
+		}
+	}
+	it.Description = strings.Join(desc, "\n\n")
+	if o.Cost != nil && o.Cost.Unit != "" {
+		it.Cost = Cost{Amount: o.Cost.Quantity, Unit: o.Cost.Unit}
+	}
+
+	if o.WeaponCategory != "" || o.Damage != nil {
+		w := &Weapon{Category: strings.TrimSpace(o.WeaponCategory + " " + o.WeaponRange)}
+		if o.Damage != nil {
+			w.Damage, w.DamageType = o.Damage.DamageDice, strings.ToLower(o.Damage.DamageType.Name)
+		}
+		for _, p := range o.Properties {
+			property := strings.ToLower(p.Name)
+			switch {
+			case property == "versatile" && o.TwoHandedDamage != nil:
+				property += fmt.Sprintf(" (%s)", o.TwoHandedDamage.DamageDice)
+			case property == "thrown" && o.ThrowRange != nil:
+				property += fmt.Sprintf(" (range %d/%d)", o.ThrowRange.Normal, o.ThrowRange.Long)
+			case property == "ammunition" && o.Range != nil:
+				property += fmt.Sprintf(" (range %d/%d)", o.Range.Normal, o.Range.Long)
+			}
+			w.Properties = append(w.Properties, property)
+		}
+		it.Weapon = w
+	}
+	if o.ArmorCategory != "" || o.ArmorClass != nil {
+		a := &Armor{Category: o.ArmorCategory, StrRequirement: o.StrMinimum, StealthDisadvantage: o.StealthDisadvantage}
+		if o.ArmorClass != nil {
+			a.BaseAC = o.ArmorClass.Base
+			if a.Category != "Shield" {
+				switch {
+				case !o.ArmorClass.DexBonus:
+					a.DexCap = new(int)
+				case o.ArmorClass.MaxBonus != nil:
+					a.DexCap = o.ArmorClass.MaxBonus
+				}
+			}
+		}
+		it.Armor = a
+	}
+	return it, nil
+}
+
+// formatSpeed renders a speed map such as {"walk": 30, "fly": 60,
+// "hover": true} as "30 ft., fly 60 ft. (hover)". Values may be numbers
+// of feet or strings such as "30 ft.".
+func formatSpeed(speed map[string]interface{}) string {
+	feet := func(v interface{}) string {
+		switch v := v.(type) {
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64) + " ft."
+		case string:
+			return v
+		}
+		return ""
+	}
+	var parts []string
+	if walk := feet(speed["walk"]); walk != "" {
+		parts = append(parts, walk)
+	}
+	var modes []string
+	for mode := range speed {
+		if mode != "walk" && mode != "hover" {
+			modes = append(modes, mode)
+		}
+	}
+	sort.Strings(modes)
+	for _, mode := range modes {
+		if value := feet(speed[mode]); value != "" {
+			part := mode + " " + value
+			if mode == "fly" && speed["hover"] == true {
+				part += " (hover)"
+			}
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatSenses renders a 5e-SRD-API senses map such as {"darkvision":
+// "60 ft.", "passive_perception": 9} as "darkvision 60 ft., passive
+// Perception 9".
+func formatSenses(senses map[string]interface{}) string {
+	var names []string
+	for name := range senses {
+		if name != "passive_perception" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	var parts []string
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s %v", strings.ReplaceAll(name, "_", " "), senses[name]))
+	}
+	if passive, ok := senses["passive_perception"]; ok {
+		parts = append(parts, fmt.Sprintf("passive Perception %v", passive))
+	}
+	return strings.Join(parts, ", ")
+}
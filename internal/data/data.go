@@ -3,184 +3,91 @@ package data
 import (
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"math/rand"
-	"os"
-	"path/filepath"
 	"strings"
-	"time"
-)
-
-// Spell represents the structure of a spell from spells.json
-type Spell struct {
-	Name        string                 `json:"name"`
-	Description string                 `json:"description"`
-	Properties  map[string]interface{} `json:"properties"`
-	Publisher   string                 `json:"publisher"`
-	Book        string                 `json:"book"`
-}
-
-// Monster represents a simplified structure of a monster from monsters.json
-type Monster struct {
-	Name        string `json:"name"`
-	Description string `json:"description"` // This might need to be inferred or constructed from other fields
-}
 
-// Item represents a simplified structure of an item from items.json
-type Item struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-}
+	"dnd-cli/internal/dice"
+)
 
 // Species represents the structure of a species from species.json
 type Species struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
+	Source      string `json:"-"` // tag of the source it was loaded from
 }
 
 // Background represents the structure of a background from backgrounds.json
 type Background struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
+	Source      string `json:"-"` // tag of the source it was loaded from
 }
 
 // Class represents the structure of a class from classes.json
 type Class struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Spells      []string `json:"spells,omitempty"` // the class's spell list
+	Source      string   `json:"-"`                // tag of the source it was loaded from
+}
+
+// Subclass represents the structure of a subclass from subclasses.json
+type Subclass struct {
+	Name        string            `json:"name"`
+	Class       string            `json:"class"` // the class it belongs to
+	Description string            `json:"description"`
+	Features    []SubclassFeature `json:"features,omitempty"` // in level order
+	Publisher   string            `json:"publisher,omitempty"`
+	Book        string            `json:"book,omitempty"`
+	Source      string            `json:"-"` // tag of the source it was loaded from
+}
+
+// SubclassFeature is a feature a subclass grants at a class level.
+type SubclassFeature struct {
+	Level       int    `json:"level"`
 	Name        string `json:"name"`
 	Description string `json:"description"`
 }
 
-// Global store for loaded data
-var (
-	AllSpells      []Spell
-	AllMonsters    []Monster
-	AllItems       []Item
-	AllSpecies     []Species
-	AllBackgrounds []Background
-	AllClasses     []Class
-)
-
-// LoadData loads all necessary JSON data into memory
-func LoadData(dataPath string) error {
-	// Load Spells
-	err := loadJSONFile(filepath.Join(dataPath, "spells.json"), &AllSpells)
-	if err != nil {
-		return fmt.Errorf("failed to load spells data: %w", err)
+// UnmarshalJSON checks that the feature's level is a character level.
+func (f *SubclassFeature) UnmarshalJSON(b []byte) error {
+	type plain SubclassFeature
+	if err := json.Unmarshal(b, (*plain)(f)); err != nil {
+		return err
 	}
-
-	// Load Monsters (simplified)
-	err = loadJSONFile(filepath.Join(dataPath, "monsters.json"), &AllMonsters)
-	if err != nil {
-		return fmt.Errorf("failed to load monsters data: %w", err)
-	}
-
-	// Load Items (simplified)
-	err = loadJSONFile(filepath.Join(dataPath, "items.json"), &AllItems)
-	if err != nil {
-		return fmt.Errorf("failed to load items data: %w", err)
+	if f.Level < 1 || f.Level > 20 {
+		return fmt.Errorf("feature %q: invalid level %d", f.Name, f.Level)
 	}
-
-	// Load Species
-	err = loadJSONFile(filepath.Join(dataPath, "species.json"), &AllSpecies)
-	if err != nil {
-		return fmt.Errorf("failed to load species data: %w", err)
-	}
-
-	// Load Backgrounds
-	err = loadJSONFile(filepath.Join(dataPath, "backgrounds.json"), &AllBackgrounds)
-	if err != nil {
-		return fmt.Errorf("failed to load backgrounds data: %w", err)
-	}
-
-	// Load Classes
-	err = loadJSONFile(filepath.Join(dataPath, "classes.json"), &AllClasses)
-	if err != nil {
-		return fmt.Errorf("failed to load classes data: %w", err)
-	}
-
 	return nil
 }
 
-// Helper function to load and unmarshal JSON files
-func loadJSONFile(filePath string, target interface{}) error {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to open file %s: %w", filePath, err)
-	}
-	defer file.Close()
-
-	byteValue, _ := ioutil.ReadAll(file)
-	err = json.Unmarshal(byteValue, target)
-	if err != nil {
-		return fmt.Errorf("failed to unmarshal file %s: %w", filePath, err)
-	}
-	return nil
-}
-
-// GetSpellByName searches for a spell by its name (case-insensitive)
-func GetSpellByName(name string) (*Spell, error) {
-	lowerName := strings.ToLower(name)
-	for _, spell := range AllSpells {
-		if strings.ToLower(spell.Name) == lowerName {
-			return &spell, nil
-		}
-	}
-	return nil, fmt.Errorf("spell '%s' not found", name)
-}
-
-// GetMonsterByName searches for a monster by its name (case-insensitive)
-func GetMonsterByName(name string) (*Monster, error) {
-	lowerName := strings.ToLower(name)
-	for _, monster := range AllMonsters {
-		if strings.ToLower(monster.Name) == lowerName {
-			return &monster, nil
-		}
-	}
-	return nil, fmt.Errorf("monster '%s' not found", name)
-}
-
-// GetItemByName searches for an item by its name (case-insensitive)
-func GetItemByName(name string) (*Item, error) {
-	lowerName := strings.ToLower(name)
-	for _, item := range AllItems {
-		if strings.ToLower(item.Name) == lowerName {
-			return &item, nil
+// FeaturesThrough returns the features the subclass grants from 1st level
+// up to and including level.
+func (s *Subclass) FeaturesThrough(level int) []SubclassFeature {
+	var out []SubclassFeature
+	for _, f := range s.Features {
+		if f.Level <= level {
+			out = append(out, f)
 		}
 	}
-	return nil, fmt.Errorf("item '%s' not found", name)
+	return out
 }
 
-// GetSpeciesByName searches for a species by its name (case-insensitive)
-func GetSpeciesByName(name string) (*Species, error) {
-	lowerName := strings.ToLower(name)
-	for _, species := range AllSpecies {
-		if strings.ToLower(species.Name) == lowerName {
-			return &species, nil
-		}
+// Card renders the subclass for display: its class, description and
+// features by level.
+func (s *Subclass) Card() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s subclass\n", s.Class)
+	if s.Description != "" {
+		fmt.Fprintf(&b, "\n%s\n", s.Description)
 	}
-	return nil, fmt.Errorf("species '%s' not found", name)
-}
-
-// GetBackgroundByName searches for a background by its name (case-insensitive)
-func GetBackgroundByName(name string) (*Background, error) {
-	lowerName := strings.ToLower(name)
-	for _, background := range AllBackgrounds {
-		if strings.ToLower(background.Name) == lowerName {
-			return &background, nil
+	if len(s.Features) > 0 {
+		b.WriteString("\nFeatures\n")
+		for _, f := range s.Features {
+			fmt.Fprintf(&b, "%s level: %s. %s\n", Ordinal(f.Level), f.Name, f.Description)
 		}
 	}
-	return nil, fmt.Errorf("background '%s' not found", name)
-}
-
-// GetClassByName searches for a class by its name (case-insensitive)
-func GetClassByName(name string) (*Class, error) {
-	lowerName := strings.ToLower(name)
-	for _, class := range AllClasses {
-		if strings.ToLower(class.Name) == lowerName {
-			return &class, nil
-		}
-	}
-	return nil, fmt.Errorf("class '%s' not found", name)
+	writeSource(&b, s.Book, s.Publisher, s.Source)
+	return strings.TrimSpace(b.String())
 }
 
 // NPC represents a generated non-player character
@@ -195,25 +102,31 @@ type NPC struct {
 	Backstory        string
 }
 
-// GenerateNPC generates a random NPC with expanded details
-func GenerateNPC() NPC {
-	rand.Seed(time.Now().UnixNano())
+// GenerateNPC generates a random NPC with expanded details using the default dice roller
+func (s *Store) GenerateNPC() NPC {
+	return s.GenerateNPCWith(dice.Default())
+}
+
+// GenerateNPCWith generates a random NPC drawing from the given dice roller,
+// of one of the store's species and backgrounds
+func (s *Store) GenerateNPCWith(r *dice.Roller) NPC {
+	d := s.current()
 
 	// Random Name
 	firstNames := []string{"Elara", "Borin", "Lyra", "Gareth", "Seraphina", "Kaelen", "Thrain", "Mira", "Dorian", "Lirael"}
 	lastNames := []string{"Stonehand", "Brightwood", "Shadowbrook", "Ironhide", "Whisperwind", "Darkwater", "Stormforge", "Moonshadow", "Fireheart", "Oakenshield"}
-	name := fmt.Sprintf("%s %s", firstNames[rand.Intn(len(firstNames))], lastNames[rand.Intn(len(lastNames))])
+	name := fmt.Sprintf("%s %s", firstNames[r.Intn(len(firstNames))], lastNames[r.Intn(len(lastNames))])
 
 	// Random Species
 	species := "Human"
-	if len(AllSpecies) > 0 {
-		species = AllSpecies[rand.Intn(len(AllSpecies))].Name
+	if len(d.species) > 0 {
+		species = d.species[r.Intn(len(d.species))].Name
 	}
 
 	// Random Background
 	background := "Commoner"
-	if len(AllBackgrounds) > 0 {
-		background = AllBackgrounds[rand.Intn(len(AllBackgrounds))].Name
+	if len(d.backgrounds) > 0 {
+		background = d.backgrounds[r.Intn(len(d.backgrounds))].Name
 	}
 
 	// Random Personality Traits
@@ -225,7 +138,7 @@ func GenerateNPC() NPC {
 		"I've enjoyed fine food, drink, and high society among my temple's elite.",
 		"I've spent so long in the temple that I have little practical experience dealing with people in the outside world.",
 	}
-	personalityTrait := personalityTraits[rand.Intn(len(personalityTraits))]
+	personalityTrait := personalityTraits[r.Intn(len(personalityTraits))]
 
 	// Random Ideals
 	ideals := []string{
@@ -236,7 +149,7 @@ func GenerateNPC() NPC {
 		"Faith: I trust that my deity will guide my actions. I have faith that if I work hard, things will go well.",
 		"Aspiration: I seek to prove myself worthy of my god's favor by matching my actions against his or her teachings.",
 	}
-	ideal := ideals[rand.Intn(len(ideals))]
+	ideal := ideals[r.Intn(len(ideals))]
 
 	// Random Bonds
 	bonds := []string{
@@ -247,7 +160,7 @@ func GenerateNPC() NPC {
 		"I protect those who cannot protect themselves.",
 		"My temple is my home, and I will defend it with my life.",
 	}
-	bond := bonds[rand.Intn(len(bonds))]
+	bond := bonds[r.Intn(len(bonds))]
 
 	// Random Flaws
 	flaws := []string{
@@ -267,7 +180,7 @@ func GenerateNPC() NPC {
 		"I have a secret that could ruin me if it got out.",
 		"I am overly trusting of others.",
 	}
-	flaw := flaws[rand.Intn(len(flaws))]
+	flaw := flaws[r.Intn(len(flaws))]
 
 	// Random Backstory Snippet
 	backstorySnippets := []string{
@@ -278,7 +191,7 @@ func GenerateNPC() NPC {
 		"Exiled from my homeland for heretical beliefs, I now wander as a pilgrim, seeking truth in distant lands.",
 		"Raised by devout parents, I inherited their faith and now carry on their legacy as a humble servant of the divine.",
 	}
-	backstory := backstorySnippets[rand.Intn(len(backstorySnippets))]
+	backstory := backstorySnippets[r.Intn(len(backstorySnippets))]
 
 	return NPC{
 		Name:             name,
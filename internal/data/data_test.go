@@ -1,12 +1,23 @@
 package data
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+	"unicode/utf8"
+
+	"dnd-cli/internal/dice"
 )
 
-func TestLoadDataAndLookup(t *testing.T) {
+func TestLoadAndLookup(t *testing.T) {
+	store := NewStore()
 	// Create a temporary directory for test data
 	testDir, err := os.MkdirTemp("", "dnd-data-test")
 	if err != nil {
@@ -69,44 +80,1309 @@ func TestLoadDataAndLookup(t *testing.T) {
 	}
 
 	// Load data from the temporary directory
-	err = LoadData(testDir)
+	err = store.Load(LoadOptions{DataDir: testDir})
 	if err != nil {
-		t.Fatalf("LoadData failed: %v", err)
+		t.Fatalf("Load failed: %v", err)
 	}
 
 	// Test spell lookup
-	spell, err := GetSpellByName("Test Spell 1")
+	spell, err := store.GetSpellByName("Test Spell 1")
 	if err != nil || spell.Name != "Test Spell 1" {
 		t.Errorf("GetSpellByName failed for 'Test Spell 1': %v, got %v", err, spell)
 	}
-	_, err = GetSpellByName("Non Existent Spell")
+	_, err = store.GetSpellByName("Non Existent Spell")
 	if err == nil {
 		t.Errorf("GetSpellByName expected error for non-existent spell, got nil")
 	}
 
 	// Test monster lookup
-	monster, err := GetMonsterByName("Test Monster 1")
+	monster, err := store.GetMonsterByName("Test Monster 1")
 	if err != nil || monster.Name != "Test Monster 1" {
 		t.Errorf("GetMonsterByName failed for 'Test Monster 1': %v, got %v", err, monster)
 	}
-	_, err = GetMonsterByName("Non Existent Monster")
+	_, err = store.GetMonsterByName("Non Existent Monster")
 	if err == nil {
 		t.Errorf("GetMonsterByName expected error for non-existent monster, got nil")
 	}
 
 	// Test item lookup
-	item, err := GetItemByName("Test Item 1")
+	item, err := store.GetItemByName("Test Item 1")
 	if err != nil || item.Name != "Test Item 1" {
 		t.Errorf("GetItemByName failed for 'Test Item 1': %v, got %v", err, item)
 	}
-	_, err = GetItemByName("Non Existent Item")
+	_, err = store.GetItemByName("Non Existent Item")
 	if err == nil {
 		t.Errorf("GetItemByName expected error for non-existent item, got nil")
 	}
 
 	// Test NPC generation
-	npc := GenerateNPC()
+	npc := store.GenerateNPC()
 	if npc.Name == "" || npc.Species == "" || npc.Background == "" {
 		t.Errorf("GenerateNPC returned empty string: Name='%s', Species='%s', Background='%s'", npc.Name, npc.Species, npc.Background)
 	}
 }
+
+func TestGenerateNPCWithSeededRoller(t *testing.T) {
+	store := NewStore()
+	if err := store.Load(LoadOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	first := store.GenerateNPCWith(dice.NewSeededRoller(7))
+	second := store.GenerateNPCWith(dice.NewSeededRoller(7))
+	if first != second {
+		t.Errorf("GenerateNPCWith() with the same seed differed: %+v vs %+v", first, second)
+	}
+}
+
+func TestMonsterStatBlock(t *testing.T) {
+	typed := `{
+		"name": "Goblin", "size": "Small", "type": "humanoid", "subtype": "goblinoid", "alignment": "neutral evil",
+		"armor_class": 15, "armor_desc": "leather armor, shield", "hit_points": 7, "hit_dice": "2d6", "speed": "30 ft.",
+		"ability_scores": {"str": 8, "dex": 14, "con": 10, "int": 10, "wis": 8, "cha": 8},
+		"skills": {"stealth": 6}, "senses": "darkvision 60 ft., passive Perception 9", "languages": "Common, Goblin",
+		"challenge_rating": "1/4",
+		"traits": [{"name": "Nimble Escape", "description": "The goblin can take the Disengage or Hide action as a bonus action."}],
+		"actions": [{"name": "Scimitar", "description": "Melee Weapon Attack: +4 to hit, reach 5 ft., one target. Hit: 5 (1d6 + 2) slashing damage."}]
+	}`
+	printed := `{
+		"name": "Goblin", "description": "A small, black-hearted humanoid.",
+		"properties": {
+			"Size": "Small", "Type": "humanoid", "Armor Class": "15 (leather armor, shield)", "Hit Points": "7 (2d6)",
+			"Speed": "30 ft.", "STR": "8 (-1)", "DEX": "14 (+2)", "CON": 10, "INT": "10 (+0)", "WIS": "8 (-1)", "CHA": "8 (-1)",
+			"Skills": "Stealth +6", "Challenge": "1/4 (50 XP)"
+		}
+	}`
+
+	for name, raw := range map[string]string{"typed": typed, "printed": printed} {
+		t.Run(name, func(t *testing.T) {
+			var m Monster
+			if err := json.Unmarshal([]byte(raw), &m); err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+			if m.ArmorClass != 15 || m.HitPoints != 7 || m.HitDice != "2d6" {
+				t.Errorf("AC/HP = %d/%d (%s), want 15/7 (2d6)", m.ArmorClass, m.HitPoints, m.HitDice)
+			}
+			if m.AbilityScores.Dex != 14 || m.AbilityScores.Con != 10 || m.Initiative() != 2 {
+				t.Errorf("ability scores = %+v, want Dex 14, Con 10", m.AbilityScores)
+			}
+			if m.ChallengeRating != 0.25 || m.XP != 50 {
+				t.Errorf("CR = %v (%d XP), want 1/4 (50 XP)", m.ChallengeRating, m.XP)
+			}
+			block := m.StatBlock()
+			for _, want := range []string{"Armor Class 15 (leather armor, shield)", "Hit Points 7 (2d6)", "14 (+2)", "Skills Stealth +6", "Challenge 1/4 (50 XP)"} {
+				if !strings.Contains(block, want) {
+					t.Errorf("StatBlock() missing %q:\n%s", want, block)
+				}
+			}
+		})
+	}
+
+	// A challenge rating of 0 is still a rating, worth 10 XP.
+	for _, tt := range []struct {
+		raw       string
+		challenge string
+	}{
+		{`{"name": "Rat", "challenge_rating": 0}`, "Challenge 0 (10 XP)"},
+		{`{"name": "Rat", "challenge_rating": "0"}`, "Challenge 0 (10 XP)"},
+		{`{"name": "Rat", "properties": {"Challenge": "0 (10 XP)"}}`, "Challenge 0 (10 XP)"},
+		{`{"name": "Rat", "properties": {"Challenge": "0"}}`, "Challenge 0 (10 XP)"},
+		{`{"name": "Rat", "challenge_rating": 0, "xp": 0}`, "Challenge 0 (10 XP)"},
+		{`{"name": "Rat"}`, ""},
+		{`{"name": "Rat", "challenge_rating": null}`, ""},
+	} {
+		var m Monster
+		if err := json.Unmarshal([]byte(tt.raw), &m); err != nil {
+			t.Fatalf("Unmarshal(%s) failed: %v", tt.raw, err)
+		}
+		block := m.StatBlock()
+		if tt.challenge == "" && strings.Contains(block, "Challenge") {
+			t.Errorf("Unmarshal(%s): StatBlock() has a Challenge line without a rating:\n%s", tt.raw, block)
+		}
+		if tt.challenge != "" && !strings.Contains(block, tt.challenge) {
+			t.Errorf("Unmarshal(%s): StatBlock() missing %q:\n%s", tt.raw, tt.challenge, block)
+		}
+	}
+
+	var bad Monster
+	if err := json.Unmarshal([]byte(`{"name": "Bad", "challenge_rating": "one"}`), &bad); err == nil {
+		t.Errorf("Unmarshal with an invalid challenge rating expected error, got nil")
+	}
+
+	// Loading reports each monster that doesn't decode and keeps the rest.
+	dir := t.TempDir()
+	monsters := `[
+  {"name": "Bad Typed", "challenge_rating": "one"},
+  {"name": "Bad Printed", "properties": {"Challenge": "lots (9000 XP)"}},
+  {"name": "Frost Goblin", "size": "Small", "type": "humanoid", "challenge_rating": "1/4"}
+]`
+	if err := os.WriteFile(filepath.Join(dir, "monsters.json"), []byte(monsters), 0644); err != nil {
+		t.Fatal(err)
+	}
+	store := NewStore()
+	if err := store.Load(LoadOptions{Overlays: []Overlay{{Path: dir}}}); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if m, err := store.GetMonsterByName("Frost Goblin"); err != nil || m.XP != 50 {
+		t.Errorf("Frost Goblin should load alongside the bad monsters: %+v, %v", m, err)
+	}
+	var got []string
+	for _, p := range store.Problems() {
+		got = append(got, strings.TrimPrefix(p.String(), dir+string(filepath.Separator)))
+	}
+	want := []string{
+		`monsters.json:2: error: Bad Typed: invalid challenge rating "one"`,
+		`monsters.json:3: error: Bad Printed: invalid challenge rating "lots"`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Problems =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestSpellCard(t *testing.T) {
+	typed := `{
+		"name": "Fireball", "description": "A bright streak flashes from your pointing finger.",
+		"level": 3, "school": "evocation", "casting_time": "1 action", "range": "150 feet",
+		"components": "V, S, M (a tiny ball of bat guano and sulfur)", "duration": "Instantaneous",
+		"classes": ["Sorcerer", "Wizard"], "higher_levels": "The damage increases by 1d6 for each slot level above 3rd."
+	}`
+	printed := `{
+		"name": "Fireball", "description": "A bright streak flashes from your pointing finger.",
+		"properties": {
+			"Level": "3rd", "School": "Evocation", "Casting Time": "1 action", "Range": "150 feet",
+			"Components": "V, S, M", "Material": "a tiny ball of bat guano and sulfur", "Duration": "Instantaneous",
+			"Classes": "Sorcerer, Wizard", "At Higher Levels": "The damage increases by 1d6 for each slot level above 3rd."
+		}
+	}`
+
+	for name, raw := range map[string]string{"typed": typed, "printed": printed} {
+		t.Run(name, func(t *testing.T) {
+			var s Spell
+			if err := json.Unmarshal([]byte(raw), &s); err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+			want := Components{Verbal: true, Somatic: true, Material: true, Text: "a tiny ball of bat guano and sulfur"}
+			if s.Level != 3 || s.School != "Evocation" || s.Components != want || len(s.Classes) != 2 {
+				t.Errorf("Unmarshal = %+v", s)
+			}
+			card := s.Card()
+			for _, want := range []string{"3rd-level evocation", "Components: V, S, M (a tiny ball of bat guano and sulfur)", "Classes: Sorcerer, Wizard", "At Higher Levels."} {
+				if !strings.Contains(card, want) {
+					t.Errorf("Card() missing %q:\n%s", want, card)
+				}
+			}
+		})
+	}
+
+	var ritual Spell
+	if err := json.Unmarshal([]byte(`{"name": "Detect Magic", "level": "1", "school": "Divination", "casting_time": "1 action (ritual)", "duration": "Concentration, up to 10 minutes"}`), &ritual); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if !ritual.Ritual || !ritual.Concentration || ritual.LevelSchool() != "1st-level divination (ritual)" {
+		t.Errorf("Detect Magic = %+v, %q", ritual, ritual.LevelSchool())
+	}
+
+	for _, bad := range []string{
+		`{"name": "Bad", "level": 11}`,
+		`{"name": "Bad", "school": "Pyromancy"}`,
+		`{"name": "Bad", "components": "V, X"}`,
+		`{"description": "no name"}`,
+	} {
+		var s Spell
+		if err := json.Unmarshal([]byte(bad), &s); err == nil {
+			t.Errorf("Unmarshal(%s) expected error, got nil", bad)
+		}
+	}
+
+	// Loading reports each spell that doesn't decode and keeps the rest.
+	dir := t.TempDir()
+	spells := `[
+  {"name": "Bad Level", "level": 11},
+  {"name": "Frostball", "level": 3, "school": "Evocation", "description": "Cold."},
+  {"name": "Bad School", "school": "Pyromancy"},
+  {"description": "no name"}
+]`
+	if err := os.WriteFile(filepath.Join(dir, "spells.json"), []byte(spells), 0644); err != nil {
+		t.Fatal(err)
+	}
+	store := NewStore()
+	if err := store.Load(LoadOptions{Overlays: []Overlay{{Path: dir}}}); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := store.GetSpellByName("Frostball"); err != nil {
+		t.Errorf("Frostball should load alongside the bad spells: %v", err)
+	}
+	var got []string
+	for _, p := range store.Problems() {
+		got = append(got, strings.TrimPrefix(p.String(), dir+string(filepath.Separator)))
+	}
+	want := []string{
+		`spells.json:2: error: Bad Level: invalid spell level "11"`,
+		`spells.json:4: error: Bad School: unknown school of magic "Pyromancy"`,
+		`spells.json:5: error: missing name`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Problems =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestItemCard(t *testing.T) {
+	var sword Item
+	if err := json.Unmarshal([]byte(`{
+		"name": "Longsword", "category": "Weapon", "cost": "15 gp", "weight": 3,
+		"weapon": {"category": "Martial Melee", "damage": "1d8", "damage_type": "slashing", "properties": ["versatile (1d10)"]}
+	}`), &sword); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if sword.Cost.Copper() != 1500 || !sword.Weapon.HasProperty("versatile") || sword.Weapon.HasProperty("finesse") {
+		t.Errorf("Longsword = %+v", sword)
+	}
+	card := sword.Card()
+	for _, want := range []string{"Weapon (martial melee)", "Cost: 15 gp", "Weight: 3 lb.", "Damage: 1d8 slashing", "Properties: versatile (1d10)"} {
+		if !strings.Contains(card, want) {
+			t.Errorf("Card() missing %q:\n%s", want, card)
+		}
+	}
+
+	armor := []struct {
+		raw    string
+		dexMod int
+		wantAC int
+		wantAs string
+	}{
+		{`{"name": "Leather", "properties": {"Armor Class": "11 + Dex modifier", "Weight": "10 lb."}}`, 4, 15, "11 + Dex modifier"},
+		{`{"name": "Half Plate", "properties": {"Armor Class": "15 + Dex modifier (max 2)", "Stealth": "Disadvantage"}}`, 4, 17, "15 + Dex modifier (max 2)"},
+		{`{"name": "Chain Mail", "properties": {"Armor Class": "16", "Strength": "Str 13", "Stealth": "Disadvantage"}}`, 4, 16, "16"},
+		{`{"name": "Shield", "properties": {"Armor Class": "+2"}}`, 4, 2, "+2"},
+		{`{"name": "Breastplate", "armor": {"category": "Medium", "base_ac": 14}}`, 3, 16, "14 + Dex modifier (max 2)"},
+	}
+	for _, tt := range armor {
+		var it Item
+		if err := json.Unmarshal([]byte(tt.raw), &it); err != nil {
+			t.Fatalf("Unmarshal(%s) failed: %v", tt.raw, err)
+		}
+		if it.Armor == nil || it.Category != "Armor" {
+			t.Fatalf("%s: Armor = %+v", it.Name, it)
+		}
+		if got := it.Armor.AC(tt.dexMod); got != tt.wantAC {
+			t.Errorf("%s: AC(%d) = %d, want %d", it.Name, tt.dexMod, got, tt.wantAC)
+		}
+		if got := it.Armor.ACString(); got != tt.wantAs {
+			t.Errorf("%s: ACString() = %q, want %q", it.Name, got, tt.wantAs)
+		}
+	}
+
+	var chain Item
+	json.Unmarshal([]byte(armor[2].raw), &chain)
+	if chain.Armor.StrRequirement != 13 || !chain.Armor.StealthDisadvantage {
+		t.Errorf("Chain Mail = %+v", chain.Armor)
+	}
+
+	var cloak Item
+	if err := json.Unmarshal([]byte(`{"name": "Cloak of Protection", "category": "Wondrous Item", "properties": {"Rarity": "Uncommon", "Attunement": "by a spellcaster"}}`), &cloak); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if got := cloak.Summary(); got != "Wondrous Item, uncommon (requires attunement by a spellcaster)" {
+		t.Errorf("Summary() = %q", got)
+	}
+
+	for _, bad := range []string{
+		`{"name": "Bad", "cost": "lots"}`,
+		`{"name": "Bad", "weapon": {"damage": "1d"}}`,
+		`{"name": "Bad", "armor": {"base_ac": 0}}`,
+		`{"name": "Bad", "properties": {"Weight": "heavy"}}`,
+		`{"description": "no name"}`,
+	} {
+		var it Item
+		if err := json.Unmarshal([]byte(bad), &it); err == nil {
+			t.Errorf("Unmarshal(%s) expected error, got nil", bad)
+		}
+	}
+}
+
+func TestSpellQuery(t *testing.T) {
+	spells := []Spell{
+		{Name: "Fireball", Level: 3, School: "Evocation", Classes: []string{"Sorcerer", "Wizard"}},
+		{Name: "Fire Bolt", Level: 0, School: "Evocation", Classes: []string{"Sorcerer", "Wizard"}},
+		{Name: "Detect Magic", Level: 1, School: "Divination", Classes: []string{"Cleric", "Wizard"}, Ritual: true, Concentration: true},
+		{Name: "Leomund's Tiny Hut", Level: 3, School: "Evocation", Classes: []string{"Bard", "Wizard"}, Ritual: true},
+		{Name: "Fly", Level: 3, School: "Transmutation", Classes: []string{"Wizard"}, Concentration: true},
+	}
+	names := func(spells []Spell) string {
+		var out []string
+		for _, s := range spells {
+			out = append(out, s.Name)
+		}
+		return strings.Join(out, ", ")
+	}
+
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"", "Detect Magic, Fire Bolt, Fireball, Fly, Leomund's Tiny Hut"},
+		{"class:wizard level:3 ritual", "Leomund's Tiny Hut"},
+		{"level:3 conc:no sort:school", "Fireball, Leomund's Tiny Hut"},
+		{"class:cleric", "Detect Magic"},
+		{"school:evoc sort:level", "Fire Bolt, Fireball, Leomund's Tiny Hut"},
+		{"level:cantrip-1", "Detect Magic, Fire Bolt"},
+		{"fire", "Fire Bolt, Fireball"},
+		{"ritual:false conc:false", "Fire Bolt, Fireball"},
+	}
+	for _, tt := range tests {
+		q, err := ParseSpellQuery(tt.line)
+		if err != nil {
+			t.Errorf("ParseSpellQuery(%q) failed: %v", tt.line, err)
+			continue
+		}
+		if got := names(q.Apply(spells)); got != tt.want {
+			t.Errorf("ParseSpellQuery(%q).Apply = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"level:10", "level:3-1", "school:pyro", "ritual:maybe", "sort:power", "range:60"} {
+		if _, err := ParseSpellQuery(bad); err == nil {
+			t.Errorf("ParseSpellQuery(%q) expected error, got nil", bad)
+		}
+	}
+
+	page, pages := Page(spells, 2, 2)
+	if pages != 3 || len(page) != 2 || page[0].Name != "Detect Magic" {
+		t.Errorf("Page(spells, 2, 2) = %v, %d", page, pages)
+	}
+	if page, _ := Page(spells, 4, 2); page != nil {
+		t.Errorf("Page(spells, 4, 2) = %v, want nil", page)
+	}
+}
+
+func TestMonsterQuery(t *testing.T) {
+	var monsters []Monster
+	if err := json.Unmarshal([]byte(`[
+		{"name": "Goblin", "size": "Small", "type": "humanoid", "subtype": "goblinoid", "challenge_rating": "1/4", "environments": ["forest", "hill"]},
+		{"name": "Zombie", "size": "Medium", "type": "undead", "challenge_rating": "1/4", "environments": ["swamp", "urban"]},
+		{"name": "Ghoul", "size": "Medium", "type": "undead", "challenge_rating": 1, "properties": {"Environment": "swamp, urban"}},
+		{"name": "Wight", "size": "Medium", "type": "undead", "challenge_rating": 3, "environments": ["swamp"]},
+		{"name": "Lich", "size": "Medium", "type": "undead", "challenge_rating": 21}
+	]`), &monsters); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	names := func(monsters []Monster) string {
+		var out []string
+		for _, m := range monsters {
+			out = append(out, m.Name)
+		}
+		return strings.Join(out, ", ")
+	}
+
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"", "Goblin, Zombie, Ghoul, Wight, Lich"},
+		{"cr:1/4..2 type:undead size:medium env:swamp", "Zombie, Ghoul"},
+		{"type:goblinoid", "Goblin"},
+		{"cr:5..", "Lich"},
+		{"cr:..1/2 sort:name", "Goblin, Zombie"},
+		{"env:swamp sort:xp", "Zombie, Ghoul, Wight"},
+		{"size:sm", "Goblin"},
+		{"gho", "Ghoul"},
+	}
+	for _, tt := range tests {
+		q, err := ParseMonsterQuery(tt.line)
+		if err != nil {
+			t.Errorf("ParseMonsterQuery(%q) failed: %v", tt.line, err)
+			continue
+		}
+		if got := names(q.Apply(monsters)); got != tt.want {
+			t.Errorf("ParseMonsterQuery(%q).Apply = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"cr:2..1", "cr:", "cr:x", "size:colossal", "sort:hp", "alignment:evil"} {
+		if _, err := ParseMonsterQuery(bad); err == nil {
+			t.Errorf("ParseMonsterQuery(%q) expected error, got nil", bad)
+		}
+	}
+}
+
+// newTestStore returns a store holding the entries of d.
+func newTestStore(d *dataset) *Store {
+	d.buildIndexes()
+	return &Store{data: d}
+}
+
+func TestIndexAndSearch(t *testing.T) {
+	store := newTestStore(&dataset{
+		spells: []Spell{
+			{Name: "Fear", School: "Illusion", Description: "Each creature in a 30-foot cone must succeed on a Wisdom saving throw or be frightened for the duration."},
+			{Name: "Leomund's Tiny Hut", School: "Evocation", Description: "A 10-foot-radius immobile dome of force springs into existence."},
+			{Name: "Fireball", School: "Evocation", Description: "A bright streak flashes from your pointing finger."},
+		},
+		monsters: []Monster{
+			{Name: "Goblin", Type: "humanoid", Traits: []Feature{{Name: "Nimble Escape", Description: "The goblin can take the Disengage or Hide action."}}},
+			{Name: "Wolf", Type: "beast", Description: "Wolves hunt in packs."},
+		},
+		items:   []Item{{Name: "Potion of Healing", Description: "You regain 2d4 + 2 hit points."}},
+		species: []Species{{Name: "Elf", Description: "Elves have advantage on saving throws against being charmed."}},
+		classes: []Class{{Name: "Paladin", Description: "Aura of Courage: you can't be frightened."}},
+	})
+
+	lookups := []struct {
+		name string
+		want string
+	}{
+		{"leomund's tiny hut", "Leomund's Tiny Hut"},
+		{"Leomunds Tiny-Hut", "Leomund's Tiny Hut"},
+		{"tiny hut", "Leomund's Tiny Hut"},
+		{"FEAR", "Fear"},
+		{"fire ball", "Fireball"},
+		{"Fire-Balls", "Fireball"},
+		{"tinyhut", "Leomund's Tiny Hut"},
+	}
+	for _, tt := range lookups {
+		if s, err := store.GetSpellByName(tt.name); err != nil || s.Name != tt.want {
+			t.Errorf("GetSpellByName(%q) = %v, %v; want %s", tt.name, s, err, tt.want)
+		}
+	}
+	for _, name := range []string{"goblins", "wolves"} {
+		if _, err := store.GetMonsterByName(name); err != nil {
+			t.Errorf("GetMonsterByName(%q) failed: %v", name, err)
+		}
+	}
+	if _, err := store.GetItemByName("potions of healing"); err != nil {
+		t.Errorf("GetItemByName(plural) failed: %v", err)
+	}
+	if _, err := store.GetSpellByName("hut"); err == nil {
+		t.Errorf("GetSpellByName(\"hut\") expected error, got nil")
+	}
+
+	hits := store.Search("frightened")
+	if len(hits) != 2 {
+		t.Fatalf("Search(frightened) = %+v, want 2 hits", hits)
+	}
+	for _, hit := range hits {
+		if !strings.Contains(strings.ToLower(hit.Snippet), "frightened") {
+			t.Errorf("hit %s snippet %q does not show the match", hit.Name, hit.Snippet)
+		}
+	}
+	if hits := store.Search("fear"); len(hits) == 0 || hits[0].Name != "Fear" {
+		t.Errorf("Search(fear) = %+v, want Fear first", hits)
+	}
+	if hits := store.Search("fright"); len(hits) != 2 {
+		t.Errorf("Search(fright) = %d hits, want 2 by prefix", len(hits))
+	}
+	if hits := store.Search("goblin disengage"); len(hits) != 1 || hits[0].Kind != KindMonster {
+		t.Errorf("Search(goblin disengage) = %+v", hits)
+	}
+	if hits := store.Search("wolf"); len(hits) != 1 || hits[0].Name != "Wolf" {
+		t.Errorf("Search(wolf) = %+v", hits)
+	}
+	if hits := store.Search("saving throw"); len(hits) != 2 {
+		t.Errorf("Search(saving throw) = %+v, want Fear and Elf", hits)
+	}
+	if hits := store.Search("dragon"); len(hits) != 0 {
+		t.Errorf("Search(dragon) = %+v, want none", hits)
+	}
+
+	// 'İ' lowercases to three bytes from two, and 'ẞ' to two from three,
+	// which must not shift the snippet off the match or split a rune.
+	for _, text := range []string{
+		strings.Repeat("İ", 80) + " the Frightened condition ends " + strings.Repeat("ẞ", 80),
+		strings.Repeat("ẞ", 80) + " the FRIGHTENED condition ends " + strings.Repeat("İ", 80),
+	} {
+		got := snippet(text, []string{"frightened"})
+		if !utf8.ValidString(got) || !strings.Contains(strings.ToLower(got), "frightened condition") {
+			t.Errorf("snippet() = %q, want valid text around the match", got)
+		}
+	}
+}
+
+func TestSuggestions(t *testing.T) {
+	store := newTestStore(&dataset{
+		spells:   []Spell{{Name: "Fireball"}, {Name: "Fire Bolt"}, {Name: "Fire Shield"}, {Name: "Leomund's Tiny Hut"}, {Name: "Cure Wounds"}},
+		monsters: []Monster{{Name: "Goblin"}, {Name: "Hobgoblin"}, {Name: "Gnoll"}},
+	})
+
+	tests := []struct {
+		name        string
+		suggestions []string
+		closest     string
+	}{
+		{"firebal", []string{"Fireball", "Fire Bolt"}, "Fireball"},
+		{"fierball", []string{"Fireball"}, "Fireball"},
+		{"fire", []string{"Fireball", "Fire Bolt", "Fire Shield"}, ""},
+		{"tiny", []string{"Leomund's Tiny Hut"}, ""},
+		{"cure wound spell", []string{"Cure Wounds"}, ""},
+		{"xyzzy", nil, ""},
+	}
+	for _, tt := range tests {
+		_, err := store.GetSpellByName(tt.name)
+		var notFound *NotFoundError
+		if !errors.As(err, &notFound) {
+			t.Fatalf("GetSpellByName(%q) error = %v, want *NotFoundError", tt.name, err)
+		}
+		if strings.Join(notFound.Suggestions, ", ") != strings.Join(tt.suggestions, ", ") {
+			t.Errorf("GetSpellByName(%q) suggestions = %q, want %q", tt.name, notFound.Suggestions, tt.suggestions)
+		}
+		if closest, _ := notFound.Closest(); closest != tt.closest {
+			t.Errorf("GetSpellByName(%q) closest = %q, want %q", tt.name, closest, tt.closest)
+		}
+		if closest, ok := ClosestMatch(fmt.Errorf("lookup: %w", err)); closest != tt.closest || ok != (tt.closest != "") {
+			t.Errorf("ClosestMatch(%q) = %q, %v; want %q", tt.name, closest, ok, tt.closest)
+		}
+	}
+	if closest, ok := ClosestMatch(errors.New("no such file")); ok || closest != "" {
+		t.Errorf("ClosestMatch of another error = %q, %v; want none", closest, ok)
+	}
+
+	_, err := store.GetMonsterByName("goblim")
+	var notFound *NotFoundError
+	if !errors.As(err, &notFound) || notFound.Suggestions[0] != "Goblin" {
+		t.Fatalf("GetMonsterByName(goblim) = %v", err)
+	}
+	if got := notFound.DidYouMean(); got != "Did you mean Goblin?" {
+		t.Errorf("DidYouMean() = %q", got)
+	}
+	_, err = store.GetSpellByName("fire")
+	if errors.As(err, &notFound); notFound.DidYouMean() != "Did you mean Fireball, Fire Bolt or Fire Shield?" {
+		t.Errorf("DidYouMean() = %q", notFound.DidYouMean())
+	}
+	_, err = store.GetMonsterByName("goblim")
+	if err.Error() != "monster 'goblim' not found" {
+		t.Errorf("Error() = %q", err.Error())
+	}
+}
+
+func TestResolveDataDir(t *testing.T) {
+	withData, empty := t.TempDir(), t.TempDir()
+	if err := os.WriteFile(filepath.Join(withData, "spells.json"), []byte("[]"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	xdg := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(xdg, "dnd-cli"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(xdg, "dnd-cli", "spells.json"), []byte("[]"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_DATA_HOME", xdg)
+	t.Setenv("XDG_DATA_DIRS", empty)
+
+	t.Setenv(DataDirEnv, "")
+	if got, err := ResolveDataDir(DataDirSources(withData, "")); err != nil || got.Path != withData || got.Name != "--data-dir" {
+		t.Errorf("flag: got %+v, %v", got, err)
+	}
+	if got, err := ResolveDataDir(DataDirSources("", withData)); err != nil || got.Name != "config data_dir" {
+		t.Errorf("config: got %+v, %v", got, err)
+	}
+	if got, err := ResolveDataDir(DataDirSources("", "")); err != nil || got.Path != filepath.Join(xdg, "dnd-cli") {
+		t.Errorf("XDG: got %+v, %v", got, err)
+	}
+
+	t.Setenv(DataDirEnv, withData)
+	if got, err := ResolveDataDir(DataDirSources("", empty)); err != nil || got.Name != DataDirEnv {
+		t.Errorf("env before config: got %+v, %v", got, err)
+	}
+	if _, err := ResolveDataDir(DataDirSources(empty, "")); err == nil || !strings.Contains(err.Error(), "--data-dir") {
+		t.Errorf("explicit dir without data: err = %v, want --data-dir error", err)
+	}
+
+	t.Setenv(DataDirEnv, "")
+	if _, err := ResolveDataDir([]DataDirSource{{Name: "XDG_DATA_HOME", Path: empty}}); !errors.Is(err, ErrNoDataDir) {
+		t.Errorf("no data: err = %v, want ErrNoDataDir", err)
+	}
+}
+
+func TestLoadLayersOverEmbedded(t *testing.T) {
+	store := NewStore()
+	if err := store.Load(LoadOptions{}); err != nil {
+		t.Fatalf("Load of the embedded data failed: %v", err)
+	}
+	if len(store.Loaded()) != len(Kinds) {
+		t.Fatalf("Loaded has %d categories, want %d", len(store.Loaded()), len(Kinds))
+	}
+	for i, info := range store.Loaded() {
+		if info.Kind != Kinds[i] || info.Count == 0 || len(info.Sources) != 1 || info.Sources[0].Tag != SourceSRD {
+			t.Errorf("embedded only: Loaded[%d] = %+v", i, info)
+		}
+		if info.Sources[0].Version == "" || info.Sources[0].Version != EmbeddedManifest().Version {
+			t.Errorf("embedded only: %s version = %q", info.Kind, info.Sources[0].Version)
+		}
+	}
+	embeddedSpells := store.Loaded()[0].Count
+	fireball, err := store.GetSpellByName("Fireball")
+	if err != nil || fireball.Level != 3 {
+		t.Fatalf("embedded Fireball: %+v, %v", fireball, err)
+	}
+
+	dir := t.TempDir()
+	spells := `[
+		{"name":"FIREBALL","level":3,"school":"Evocation","description":"A homebrewed fireball."},
+		{"name":"Frostball","level":3,"school":"Evocation","description":"Like a fireball, but cold."}
+	]`
+	if err := os.WriteFile(filepath.Join(dir, "spells.json"), []byte(spells), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "manifest.json"), []byte(`{"name":"Test","version":"2.0"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Load(LoadOptions{DataDir: dir}); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	info := store.Loaded()[0]
+	if info.Count != embeddedSpells+1 {
+		t.Errorf("spells: Count = %d, want %d", info.Count, embeddedSpells+1)
+	}
+	if len(info.Sources) != 2 || info.Sources[1] != (Source{Tag: SourceCore, Path: dir, Version: "2.0", Entries: 2}) {
+		t.Errorf("spells: Sources = %+v", info.Sources)
+	}
+	if len(store.Loaded()[1].Sources) != 1 {
+		t.Errorf("monsters without a file on disk: Sources = %+v", store.Loaded()[1].Sources)
+	}
+	if fireball, err := store.GetSpellByName("Fireball"); err != nil || fireball.Description != "A homebrewed fireball." {
+		t.Errorf("Fireball was not replaced: %+v, %v", fireball, err)
+	}
+	if _, err := store.GetSpellByName("Frostball"); err != nil {
+		t.Errorf("Frostball was not added: %v", err)
+	}
+	if _, err := store.GetMonsterByName("Goblin"); err != nil {
+		t.Errorf("embedded Goblin missing: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "items.json"), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Load(LoadOptions{DataDir: dir}); err == nil || !strings.Contains(err.Error(), "items") {
+		t.Errorf("malformed items.json: err = %v", err)
+	}
+}
+
+func TestHomebrewOverlays(t *testing.T) {
+	store := NewStore()
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	campaign, house := filepath.Join(t.TempDir(), "campaign"), filepath.Join(t.TempDir(), "house")
+	write(filepath.Join(campaign, "spells", "frostball.yaml"), `
+name: Frostball
+level: 3
+school: Evocation
+components: V, S
+description: Campaign frostball.
+slots:
+  1: 2
+  2: 1
+`)
+	write(filepath.Join(campaign, "monsters.json"), `[{"name":"Goblin","challenge_rating":"1/2","description":"Campaign goblin."}]`)
+	write(filepath.Join(campaign, "subclasses.yml"), "- name: Way of the Hexblade\n  class: Fighter\n")
+	write(filepath.Join(house, "spells.json"), `{"name":"Frostball","level":4,"school":"Evocation","description":"House frostball."}`)
+
+	opts := LoadOptions{Overlays: []Overlay{{Path: house, Priority: 1}, {Path: campaign}}}
+	if err := store.Load(opts); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	frostball, err := store.GetSpellByName("Frostball")
+	if err != nil || frostball.Level != 4 || frostball.Source != "house" {
+		t.Errorf("higher priority overlay should win: %+v, %v", frostball, err)
+	}
+	if !strings.Contains(frostball.Card(), "Homebrew: house") {
+		t.Errorf("card does not name the overlay:\n%s", frostball.Card())
+	}
+	if goblin, err := store.GetMonsterByName("Goblin"); err != nil || goblin.Source != "campaign" || goblin.ChallengeRating != 0.5 {
+		t.Errorf("overlay should replace the SRD goblin: %+v, %v", goblin, err)
+	}
+	if fireball, err := store.GetSpellByName("Fireball"); err != nil || fireball.Source != SourceSRD {
+		t.Errorf("Fireball: %+v, %v", fireball, err)
+	}
+	if subs := store.SubclassesOf("fighter"); len(subs) != 2 {
+		t.Errorf("SubclassesOf(fighter) = %+v, want Champion and the homebrew one", subs)
+	}
+	spells := store.Loaded()[0].Sources
+	if len(spells) != 3 || spells[1].Tag != "campaign" || spells[2].Tag != "house" {
+		t.Errorf("spell sources not in precedence order: %+v", spells)
+	}
+
+	opts.Overlays[0].Priority = 0 // a tie goes to the later overlay
+	if err := store.Load(opts); err != nil {
+		t.Fatal(err)
+	}
+	if frostball, _ := store.GetSpellByName("Frostball"); frostball == nil || frostball.Source != "campaign" {
+		t.Errorf("tie: got %+v, want the campaign frostball", frostball)
+	}
+
+	opts.Disabled = []string{"campaign", "SRD"}
+	if err := store.Load(opts); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.GetMonsterByName("Goblin"); err == nil {
+		t.Errorf("Goblin should be gone with srd and campaign disabled")
+	}
+	if frostball, _ := store.GetSpellByName("Frostball"); frostball == nil || frostball.Source != "house" {
+		t.Errorf("disabled campaign: got %+v", frostball)
+	}
+
+	for name, bad := range map[string]LoadOptions{
+		"unknown source": {Disabled: []string{"nope"}},
+		"duplicate tag":  {Overlays: []Overlay{{Path: campaign}, {Name: "campaign", Path: house}}},
+		"reserved tag":   {Overlays: []Overlay{{Name: "core", Path: house}}},
+		"missing dir":    {Overlays: []Overlay{{Path: filepath.Join(house, "nope")}}},
+	} {
+		if err := store.Load(bad); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestLoadSkipsBadEntries(t *testing.T) {
+	dir := t.TempDir()
+	spells := `- name: Frostball
+  level: 3
+  school: Evocation
+  description: Cold.
+- name: Broken
+  level: 12
+  school: Evocation
+- just a string
+`
+	if err := os.WriteFile(filepath.Join(dir, "spells.yaml"), []byte(spells), 0644); err != nil {
+		t.Fatal(err)
+	}
+	store := NewStore()
+	if err := store.Load(LoadOptions{Overlays: []Overlay{{Path: dir}}}); err != nil {
+		t.Fatalf("one bad entry should not fail the load: %v", err)
+	}
+	if _, err := store.GetSpellByName("Frostball"); err != nil {
+		t.Errorf("the good entry should load: %v", err)
+	}
+	if _, err := store.GetSpellByName("Broken"); err == nil {
+		t.Errorf("the bad entry should be left out")
+	}
+	if src := store.Loaded()[0].Sources; len(src) != 2 || src[1].Entries != 1 {
+		t.Errorf("overlay Sources = %+v, want 1 entry", src)
+	}
+
+	path := filepath.Join(dir, "spells.yaml")
+	problems := store.Problems()
+	if len(problems) != 2 {
+		t.Fatalf("Problems = %v, want 2", problems)
+	}
+	if p := problems[0]; p.File != path || p.Line != 5 || p.Entry != "Broken" || p.Severity != Error || !strings.Contains(p.Message, "12") {
+		t.Errorf("Problems[0] = %+v", p)
+	}
+	if p := problems[1]; p.File != path || p.Line != 8 || p.Entry != "" {
+		t.Errorf("Problems[1] = %+v", p)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	report, err := Validate(LoadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Problems) != 0 || report.Entries == 0 {
+		t.Errorf("embedded data should be clean: %d entries, problems %v", report.Entries, report.Problems)
+	}
+
+	// A complete data directory is checked alone, not as an overlay whose
+	// every entry overrides the embedded one.
+	full := t.TempDir()
+	for _, c := range categories() {
+		raw, err := srdFiles.ReadFile("srd/" + c.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(full, c.file), raw, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if report, err := ValidateDir(full); err != nil || len(report.Problems) != 0 || report.Entries == 0 {
+		t.Errorf("ValidateDir() of a clean full data dir = %+v, %v; want no problems", report, err)
+	}
+	if err := os.Remove(filepath.Join(full, "rules.json")); err != nil {
+		t.Fatal(err)
+	}
+	if report, err := ValidateDir(full); err != nil || report.Count(Warning) == 0 {
+		t.Errorf("ValidateDir() of a partial dir should check it as an overlay: %+v, %v", report, err)
+	}
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"spells.json": `[
+  {"name": "Frostball", "school": "Evocation", "casting_time": "1 action", "range": "150 feet",
+   "duration": "Instantaneous", "description": "Cold.", "classes": ["Wizard", "Frostmage"]},
+  {"name": "Frostball", "school": "Evocation"},
+  {"name": "Bad School", "school": "Pyromancy"},
+  {"name": "Fireball", "school": "Evocation", "casting_time": "1 action", "range": "150 feet",
+   "duration": "Instantaneous", "description": "Hotter.", "colour": "red"}
+]`,
+		"classes.json":    `{"name": "Cryomancer", "description": "Casts cold.", "spells": ["Frostball", "Ice Lance"]}`,
+		"subclasses.yaml": "- name: Shadow Blade\n  class: Fighter\n- name: Hexer\n  class: Witch\n",
+		"monsters.json":   `[{"name": "Orc", "size": "Medium"`,
+		"spels.yaml":      "{}",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	report, err = Validate(LoadOptions{Overlays: []Overlay{{Path: dir}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, p := range report.Problems {
+		got = append(got, strings.TrimPrefix(p.String(), dir+string(filepath.Separator)))
+	}
+	want := []string{
+		"spells.json:4: error: Frostball: casting_time is required",
+		"spells.json:4: error: Frostball: range is required",
+		"spells.json:4: error: Frostball: duration is required",
+		"spells.json:4: error: Frostball: description is required",
+		"spells.json:4: error: Frostball: duplicate spell; also defined at " + filepath.Join(dir, "spells.json") + ":2",
+		`spells.json:5: error: Bad School: unknown school of magic "Pyromancy"`,
+		`spells.json:6: warning: Fireball: unknown field "colour"`,
+		"spells.json:6: warning: Fireball: overrides the spell from srd",
+		"monsters.json:1: error: unexpected EOF",
+		"spels.yaml: warning: not a content file; it is ignored",
+		`spells.json:2: error: Frostball: classes names unknown class "Frostmage"`,
+		`classes.json:1: error: Cryomancer: spells names unknown spell "Ice Lance"`,
+		`subclasses.yaml:3: error: Hexer: class names unknown class "Witch"`,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d problems, want %d:\n%s", len(got), len(want), strings.Join(got, "\n"))
+	}
+	for i := range want {
+		if !strings.HasPrefix(got[i], want[i]) {
+			t.Errorf("problem %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+	if report.Count(Error) != 10 || report.Count(Warning) != 3 {
+		t.Errorf("counts: %d errors, %d warnings", report.Count(Error), report.Count(Warning))
+	}
+}
+
+func TestImport(t *testing.T) {
+	store := NewStore()
+	open5e := `{"count": 3, "results": [
+{"slug": "frostball", "name": "Frostball", "desc": "A cold streak.", "level": "3rd-level", "school": "Evocation",
+ "casting_time": "1 action", "range": "150 feet", "components": "V, S, M", "material": "A bead of ice.",
+ "ritual": "no", "concentration": "no", "duration": "Instantaneous", "dnd_class": "Sorcerer, Wizard",
+ "archetype": "Domain: Winter", "document__title": "Frost Tome"},
+{"slug": "ice-goblin", "name": "Ice Goblin", "size": "Small", "type": "humanoid", "armor_class": 13, "hit_points": 7,
+ "speed": {"walk": 30, "fly": 40, "hover": true}, "dexterity": 14, "dexterity_save": 4, "skills": {"stealth": 6},
+ "challenge_rating": "1/4", "actions": [{"name": "Icicle", "desc": "Ranged Weapon Attack."}],
+ "bonus_actions": [{"name": "Nimble Escape", "desc": "Disengage or Hide."}], "reactions": "", "legendary_desc": "Old"},
+{"slug": "ice-shield", "name": "Ice Shield", "category": "Shield", "base_ac": 12, "cost": "10 gp", "weight": "6 lb."}
+]}`
+	im, err := Import(FormatOpen5e, []byte(open5e), "")
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if len(im.Spells) != 1 || len(im.Monsters) != 1 || len(im.Items) != 1 || len(im.Failed) != 0 {
+		t.Fatalf("Import = %d spells, %d monsters, %d items, failed %v", len(im.Spells), len(im.Monsters), len(im.Items), im.Failed)
+	}
+	spell := im.Spells[0]
+	if spell.Level != 3 || !spell.Components.Material || spell.Components.Text != "A bead of ice" || len(spell.Classes) != 2 || spell.Book != "Frost Tome" {
+		t.Errorf("spell = %+v", spell)
+	}
+	monster := im.Monsters[0]
+	if monster.Speed != "30 ft., fly 40 ft. (hover)" || monster.SavingThrows["Dex"] != 4 || monster.Skills["Stealth"] != 6 || monster.XP != 50 {
+		t.Errorf("monster = %+v", monster)
+	}
+	if len(monster.Actions) != 2 || monster.Actions[1].Name != "Nimble Escape (Bonus Action)" {
+		t.Errorf("actions = %+v", monster.Actions)
+	}
+	if shield := im.Items[0]; shield.Armor == nil || shield.Armor.BaseAC != 2 || shield.Weight != 6 {
+		t.Errorf("shield = %+v", shield)
+	}
+	want := map[Kind][]string{KindSpell: {"archetype (1)"}, KindMonster: {"legendary_desc (1)"}}
+	for _, kind := range []Kind{KindSpell, KindMonster, KindItem} {
+		if got := im.SkippedFields(kind); !reflect.DeepEqual(got, want[kind]) {
+			t.Errorf("SkippedFields(%s) = %v, want %v", kind, got, want[kind])
+		}
+	}
+
+	srd := `[{"index": "cloak", "name": "Cloak of Frost", "equipment_category": {"name": "Wondrous Items"},
+ "rarity": {"name": "Rare"}, "desc": ["Wondrous item, rare (requires attunement by a druid)", "It is cold."]},
+{"index": "yeti", "name": "Yeti", "size": "Large", "type": "monstrosity", "armor_class": [{"type": "natural", "value": 12}],
+ "hit_points": 51, "challenge_rating": 3, "speed": {"walk": "40 ft.", "climb": "40 ft."},
+ "proficiencies": [{"value": 3, "proficiency": {"name": "Skill: Perception"}}],
+ "condition_immunities": [{"name": "Frightened"}], "senses": {"darkvision": "60 ft.", "passive_perception": 13}},
+{"name": "Mystery"}]`
+	im, err = Import(Format5eSRD, []byte(srd), "")
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if len(im.Items) != 1 || len(im.Monsters) != 1 || len(im.Failed) != 1 {
+		t.Fatalf("Import = %d items, %d monsters, failed %v", len(im.Items), len(im.Monsters), im.Failed)
+	}
+	cloak := im.Items[0]
+	if cloak.Category != "Wondrous item" || !cloak.Attunement || cloak.AttunedBy != "a druid" || cloak.Description != "It is cold." {
+		t.Errorf("cloak = %+v", cloak)
+	}
+	yeti := im.Monsters[0]
+	if yeti.ArmorClass != 12 || yeti.ArmorDesc != "natural armor" || yeti.Skills["Perception"] != 3 ||
+		yeti.ConditionImmunities != "frightened" || yeti.Senses != "darkvision 60 ft., passive Perception 13" {
+		t.Errorf("yeti = %+v", yeti)
+	}
+
+	dir := t.TempDir()
+	written, err := im.Save(dir, "frost")
+	if err != nil || len(written) != 2 {
+		t.Fatalf("Save = %v, %v", written, err)
+	}
+	if err := store.Load(LoadOptions{Overlays: []Overlay{{Path: dir}}}); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if yeti, err := store.GetMonsterByName("Yeti"); err != nil || yeti.Source != filepath.Base(dir) {
+		t.Errorf("saved yeti: %+v, %v", yeti, err)
+	}
+
+	if _, err := Import("roll20", []byte(`[]`), ""); err == nil {
+		t.Error("Import should reject an unknown format")
+	}
+
+	// Entries that don't decode are reported and neither kept nor saved.
+	bad := `[{"slug": "frost-axe", "name": "Frost Axe", "category": "Martial Melee Weapons", "damage_dice": "lots", "cost": "30 gp"},
+{"slug": "ice-mace", "name": "Ice Mace", "category": "Simple Melee Weapons", "damage_dice": 6, "cost": "5 gp"},
+{"slug": "ice-club", "name": "Ice Club", "category": "Simple Melee Weapons", "damage_dice": "1d4", "damage_type": "bludgeoning", "cost": "1 sp"}]`
+	im, err = Import(FormatOpen5e, []byte(bad), "")
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if len(im.Items) != 1 || im.Items[0].Name != "Ice Club" || len(im.Failed) != 2 {
+		t.Fatalf("Import = items %+v, failed %v", im.Items, im.Failed)
+	}
+	dir = t.TempDir()
+	written, err = im.Save(dir, "bad")
+	if err != nil || len(written) != 1 {
+		t.Fatalf("Save = %v, %v", written, err)
+	}
+	raw, err := os.ReadFile(written[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved []Item
+	if err := json.Unmarshal(raw, &saved); err != nil || len(saved) != 1 || saved[0].Name != "Ice Club" {
+		t.Errorf("saved items = %+v, %v", saved, err)
+	}
+}
+
+func TestFeatsConditionsAndRules(t *testing.T) {
+	store := NewStore()
+	if err := store.Load(LoadOptions{}); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	grappler, err := store.GetFeatByName("grappler")
+	if err != nil || grappler.PrerequisiteText() != "Strength 13 or higher" || len(grappler.Benefits) != 2 {
+		t.Errorf("Grappler = %+v, %v", grappler, err)
+	}
+	paralyzed, err := store.GetConditionByName("paralyzed")
+	if err != nil || !paralyzed.SpeedZero || paralyzed.AttacksAgainst != Advantage || len(paralyzed.FailsSaves) != 2 {
+		t.Errorf("Paralyzed = %+v, %v", paralyzed, err)
+	}
+	if !strings.Contains(paralyzed.Card(), "Also: Incapacitated") {
+		t.Errorf("Paralyzed card lacks its mechanics:\n%s", paralyzed.Card())
+	}
+	if rule, err := store.GetRuleByName("opportunity attack"); err != nil || rule.Category != "Combat" {
+		t.Errorf("GetRuleByName(opportunity attack) = %+v, %v", rule, err)
+	}
+	champion, err := store.GetSubclassByName("Champion")
+	if err != nil {
+		t.Fatalf("GetSubclassByName(Champion) failed: %v", err)
+	}
+	if features := champion.FeaturesThrough(7); len(features) != 2 || features[1].Name != "Remarkable Athlete" {
+		t.Errorf("Champion features through 7th level = %+v", features)
+	}
+
+	prerequisites := []struct {
+		text string
+		want Prerequisite
+	}{
+		{"Strength 13 or higher", Prerequisite{Ability: "Strength", Score: 13}},
+		{"Dex 13+", Prerequisite{Ability: "Dexterity", Score: 13}},
+		{"Proficiency with heavy armor", Prerequisite{Proficiency: "heavy armor"}},
+		{"The ability to cast at least one spell", Prerequisite{Spellcasting: true}},
+		{"4th level", Prerequisite{Level: 4}},
+		{"Elf or half-elf", Prerequisite{Text: "Elf or half-elf"}},
+	}
+	for _, tt := range prerequisites {
+		if got := ParsePrerequisite(tt.text); got != tt.want {
+			t.Errorf("ParsePrerequisite(%q) = %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+
+	bad := []struct {
+		raw string
+		v   interface{}
+	}{
+		{`{"name": "Sluggish", "effects": ["Slow."], "attack_rolls": "sometimes"}`, new(Condition)},
+		{`{"name": "Mighty", "description": "Strong.", "prerequisites": [{"ability": "Might", "score": 13}]}`, new(Feat)},
+		{`{"name": "Way of Rust", "class": "Monk", "features": [{"level": 21, "name": "Rust"}]}`, new(Subclass)},
+	}
+	for _, tt := range bad {
+		if err := json.Unmarshal([]byte(tt.raw), tt.v); err == nil {
+			t.Errorf("decoding %s should fail", tt.raw)
+		}
+	}
+
+	dir := filepath.Join(t.TempDir(), "frost")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	condition := `[{"name": "Frozen", "effects": ["A frozen creature can't move."], "includes": ["Restrained", "Chilled"]}]`
+	if err := os.WriteFile(filepath.Join(dir, "conditions.json"), []byte(condition), 0644); err != nil {
+		t.Fatal(err)
+	}
+	report, err := Validate(LoadOptions{Overlays: []Overlay{{Path: dir}}})
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if len(report.Problems) != 1 || !strings.Contains(report.Problems[0].Message, `unknown condition "Chilled"`) {
+		t.Errorf("Validate = %v, want the unknown Chilled condition", report.Problems)
+	}
+}
+
+// loadedCards renders every loaded entry, to compare two loads of the same
+// data.
+func loadedCards(store *Store) []string {
+	var cards []string
+	spells := store.Spells()
+	for i := range spells {
+		cards = append(cards, spells[i].Card())
+	}
+	monsters := store.Monsters()
+	for i := range monsters {
+		cards = append(cards, monsters[i].StatBlock())
+	}
+	items := store.Items()
+	for i := range items {
+		cards = append(cards, items[i].Card())
+	}
+	for _, s := range store.Species() {
+		cards = append(cards, s.Name+s.Description)
+	}
+	for _, b := range store.Backgrounds() {
+		cards = append(cards, b.Name+b.Description)
+	}
+	for _, c := range store.Classes() {
+		cards = append(cards, c.Name+c.Description)
+	}
+	subclasses := store.Subclasses()
+	for i := range subclasses {
+		cards = append(cards, subclasses[i].Card())
+	}
+	feats := store.Feats()
+	for i := range feats {
+		cards = append(cards, feats[i].Card())
+	}
+	conditions := store.Conditions()
+	for i := range conditions {
+		cards = append(cards, conditions[i].Card())
+	}
+	rules := store.Rules()
+	for i := range rules {
+		cards = append(cards, rules[i].Card())
+	}
+	return cards
+}
+
+func TestLoadCache(t *testing.T) {
+	store := NewStore()
+	dir, cacheDir := t.TempDir(), t.TempDir()
+	spellsPath := filepath.Join(dir, "spells.json")
+	if err := os.WriteFile(spellsPath, []byte(`[{"name":"Frostball","level":3,"school":"Evocation","description":"Like a fireball, but cold."}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	opts := LoadOptions{DataDir: dir, CacheDir: cacheDir}
+
+	if err := store.Load(opts); err != nil {
+		t.Fatalf("cold load failed: %v", err)
+	}
+	if store.Stats().Cache != CacheMiss || store.Stats().CacheErr != nil {
+		t.Fatalf("cold load: %+v", store.Stats())
+	}
+	cachePath := store.Stats().CachePath
+	if _, err := os.Stat(cachePath); err != nil || filepath.Dir(cachePath) != cacheDir {
+		t.Fatalf("cache was not written to %s: %v", cacheDir, err)
+	}
+	cards, loaded, hits := loadedCards(store), store.Loaded(), store.Search("fireball cold")
+
+	if err := store.Load(opts); err != nil {
+		t.Fatalf("warm load failed: %v", err)
+	}
+	if store.Stats().Cache != CacheHit || store.Stats().Parse != 0 {
+		t.Fatalf("warm load: %+v", store.Stats())
+	}
+	if !reflect.DeepEqual(loadedCards(store), cards) || !reflect.DeepEqual(store.Loaded(), loaded) {
+		t.Errorf("the cached data differs from the parsed data")
+	}
+	if got := store.Search("fireball cold"); !reflect.DeepEqual(got, hits) || len(got) == 0 {
+		t.Errorf("Search from the cache = %+v, want %+v", got, hits)
+	}
+	if spell, err := store.GetSpellByName("frostballs"); err != nil || spell.Source != SourceCore {
+		t.Errorf("Frostball from the cache: %+v, %v", spell, err)
+	}
+
+	if err := os.WriteFile(spellsPath, []byte(`[{"name":"Sleetball","level":3,"school":"Evocation","description":"Wetter."}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(spellsPath, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Load(opts); err != nil || store.Stats().Cache != CacheMiss {
+		t.Fatalf("load after a change: %+v, %v", store.Stats(), err)
+	}
+	if _, err := store.GetSpellByName("Sleetball"); err != nil {
+		t.Errorf("changed file was not reloaded: %v", err)
+	}
+
+	if err := os.WriteFile(cachePath, []byte("not a cache"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Load(opts); err != nil || store.Stats().Cache != CacheMiss || store.Stats().CacheErr == nil {
+		t.Fatalf("load with a corrupt cache: %+v, %v", store.Stats(), err)
+	}
+	if _, err := store.GetSpellByName("Sleetball"); err != nil {
+		t.Errorf("load with a corrupt cache: %v", err)
+	}
+	if err := store.Load(opts); err != nil || store.Stats().Cache != CacheHit {
+		t.Errorf("corrupt cache was not rewritten: %+v, %v", store.Stats(), err)
+	}
+
+	// Each set of sources keeps its own cache, so switching between them
+	// doesn't throw the other's away.
+	overlay := LoadOptions{DataDir: dir, Overlays: []Overlay{{Path: t.TempDir()}}, CacheDir: cacheDir}
+	if err := store.Load(overlay); err != nil || store.Stats().Cache != CacheMiss || store.Stats().CachePath == cachePath {
+		t.Fatalf("load with an overlay: %+v, %v", store.Stats(), err)
+	}
+	if err := store.Load(opts); err != nil || store.Stats().Cache != CacheHit {
+		t.Errorf("the overlay's cache replaced the data directory's: %+v, %v", store.Stats(), err)
+	}
+
+	if err := store.Load(LoadOptions{DataDir: dir}); err != nil || store.Stats().Cache != CacheOff {
+		t.Errorf("Load without a cache: %+v, %v", store.Stats(), err)
+	}
+}
+
+// benchmarkData writes a data directory of n spells and n monsters.
+func benchmarkData(b *testing.B, n int) string {
+	dir := b.TempDir()
+	var spells, monsters []map[string]interface{}
+	for i := 0; i < n; i++ {
+		spells = append(spells, map[string]interface{}{
+			"name": fmt.Sprintf("Spell %d", i), "level": i % 10, "school": "Evocation",
+			"casting_time": "1 action", "range": "150 feet", "components": "V, S, M",
+			"duration": "Instantaneous", "classes": []string{"Sorcerer", "Wizard"},
+			"description": strings.Repeat("A bright streak flashes to a point you choose and blossoms into flame. ", 8),
+		})
+		monsters = append(monsters, map[string]interface{}{
+			"name": fmt.Sprintf("Monster %d", i), "size": "Medium", "type": "humanoid",
+			"armor_class": 15, "hit_points": 11, "speed": "30 ft.", "challenge_rating": "1/4",
+			"description": strings.Repeat("A small, black-hearted humanoid that lairs in caves. ", 8),
+		})
+	}
+	for file, entries := range map[string]interface{}{"spells.json": spells, "monsters.json": monsters} {
+		raw, err := json.Marshal(entries)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, file), raw, 0644); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// BenchmarkLoad compares parsing the data files (cold) with reading
+// them back from the cache (warm).
+func BenchmarkLoad(b *testing.B) {
+	store := NewStore()
+	dir := benchmarkData(b, 2000)
+	b.Run("cold", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if err := store.Load(LoadOptions{DataDir: dir}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("warm", func(b *testing.B) {
+		opts := LoadOptions{DataDir: dir, CacheDir: b.TempDir()}
+		if err := store.Load(opts); err != nil {
+			b.Fatal(err)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if err := store.Load(opts); err != nil || store.Stats().Cache != CacheHit {
+				b.Fatalf("%+v, %v", store.Stats(), err)
+			}
+		}
+	})
+}
+
+func TestStore(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "spells.json"), []byte(`[{"name":"Frostball","level":3,"school":"Evocation","description":"Like a fireball, but cold."}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	core, homebrew := NewStore(), NewStore()
+	if err := core.Load(LoadOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := homebrew.Load(LoadOptions{Overlays: []Overlay{{Name: "campaign", Path: dir}}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := core.GetSpellByName("Frostball"); err == nil {
+		t.Errorf("the core store has the other store's homebrew")
+	}
+	if spell, err := homebrew.GetSpellByName("Frostball"); err != nil || spell.Source != "campaign" {
+		t.Errorf("homebrew Frostball = %+v, %v", spell, err)
+	}
+
+	empty := NewStore()
+	if _, err := empty.GetSpellByName("Fireball"); err == nil || len(empty.Search("fire")) != 0 || len(empty.Spells()) != 0 {
+		t.Errorf("an empty store has data")
+	}
+
+	if err := homebrew.Load(LoadOptions{Overlays: []Overlay{{Path: filepath.Join(dir, "missing")}}}); err == nil {
+		t.Fatalf("Load of a missing overlay succeeded")
+	}
+	if _, err := homebrew.GetSpellByName("Frostball"); err != nil {
+		t.Errorf("a failed Load dropped the loaded data: %v", err)
+	}
+
+	spells := homebrew.Spells()
+	spells[0].Name = "Changed"
+	if homebrew.Spells()[0].Name == "Changed" {
+		t.Errorf("Spells returned the store's own slice")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if _, err := homebrew.GetSpellByName("Fireball"); err != nil {
+					t.Errorf("concurrent lookup failed: %v", err)
+					return
+				}
+				homebrew.Search("fire")
+				homebrew.FindSpells(SpellQuery{Text: "ball"})
+			}
+		}()
+	}
+	for i := 0; i < 5; i++ {
+		if err := homebrew.Load(LoadOptions{DataDir: dir}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wg.Wait()
+}
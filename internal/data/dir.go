@@ -0,0 +1,126 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DataDirEnv is the environment variable that sets the data directory.
+const DataDirEnv = "DND_DATA_DIR"
+
+// ErrNoDataDir is returned by ResolveDataDir when no source holds any data
+// files. The embedded data can still be used on its own.
+var ErrNoDataDir = errors.New("no data directory found")
+
+// DataDirSource is a place the data directory may be found.
+type DataDirSource struct {
+	Name     string // where the path came from, e.g. "--data-dir"
+	Path     string
+	Explicit bool // set by the user, so it must hold the data
+}
+
+// DataDirSources lists where to look for the data directory, in order: the
+// --data-dir flag, $DND_DATA_DIR, the data_dir config setting, the XDG data
+// directories, next to the executable and, last, data/data under the
+// working directory. Empty settings are left out.
+func DataDirSources(flag, configured string) []DataDirSource {
+	var sources []DataDirSource
+	explicit := func(name, path string) {
+		if path != "" {
+			sources = append(sources, DataDirSource{Name: name, Path: expandHome(path), Explicit: true})
+		}
+	}
+	explicit("--data-dir", flag)
+	explicit(DataDirEnv, os.Getenv(DataDirEnv))
+	explicit("config data_dir", configured)
+
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			dataHome = filepath.Join(home, ".local", "share")
+		}
+	}
+	if dataHome != "" {
+		sources = append(sources, DataDirSource{Name: "XDG_DATA_HOME", Path: filepath.Join(dataHome, "dnd-cli")})
+	}
+	dataDirs := os.Getenv("XDG_DATA_DIRS")
+	if dataDirs == "" {
+		dataDirs = "/usr/local/share:/usr/share"
+	}
+	for _, dir := range filepath.SplitList(dataDirs) {
+		if dir != "" {
+			sources = append(sources, DataDirSource{Name: "XDG_DATA_DIRS", Path: filepath.Join(dir, "dnd-cli")})
+		}
+	}
+
+	if exe, err := os.Executable(); err == nil {
+		if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+			exe = resolved
+		}
+		dir := filepath.Dir(exe)
+		sources = append(sources,
+			DataDirSource{Name: "executable", Path: filepath.Join(dir, "data", "data")},
+			DataDirSource{Name: "executable", Path: filepath.Join(dir, "..", "share", "dnd-cli")},
+		)
+	}
+	if wd, err := os.Getwd(); err == nil {
+		sources = append(sources, DataDirSource{Name: "working directory", Path: filepath.Join(wd, "data", "data")})
+	}
+	return sources
+}
+
+// ResolveDataDir returns the first source that holds any of the data files.
+// A directory the user set explicitly must hold some: rather than looking
+// further, ResolveDataDir reports the mistake. If no source holds any, the
+// error wraps ErrNoDataDir.
+func ResolveDataDir(sources []DataDirSource) (DataDirSource, error) {
+	var tried []string
+	for _, s := range sources {
+		if isDataDir(s.Path) {
+			return s, nil
+		}
+		if s.Explicit {
+			return DataDirSource{}, fmt.Errorf("%s %s does not contain any data files", s.Name, s.Path)
+		}
+		if !containsFold(tried, s.Path) {
+			tried = append(tried, s.Path)
+		}
+	}
+	return DataDirSource{}, fmt.Errorf("%w (looked in %s); set --data-dir or %s", ErrNoDataDir, strings.Join(tried, ", "), DataDirEnv)
+}
+
+// isDataDir reports whether dir holds at least one of the data files.
+func isDataDir(dir string) bool {
+	for _, c := range categories() {
+		if info, err := os.Stat(filepath.Join(dir, c.file)); err == nil && !info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+// isCompleteDataDir reports whether dir holds the data file of every
+// category, as a full core data directory does.
+func isCompleteDataDir(dir string) bool {
+	for _, c := range categories() {
+		if info, err := os.Stat(filepath.Join(dir, c.file)); err != nil || info.IsDir() {
+			return false
+		}
+	}
+	return true
+}
+
+// expandHome replaces a leading ~ with the user's home directory.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
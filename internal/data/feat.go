@@ -0,0 +1,136 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Feat represents the structure of a feat from feats.json
+type Feat struct {
+	Name          string         `json:"name"`
+	Prerequisites []Prerequisite `json:"prerequisites,omitempty"` // all must be met
+	Description   string         `json:"description"`
+	Benefits      []string       `json:"benefits,omitempty"`
+	Publisher     string         `json:"publisher,omitempty"`
+	Book          string         `json:"book,omitempty"`
+	Source        string         `json:"-"` // tag of the source it was loaded from
+}
+
+// Prerequisite is one thing a character must have to take a feat. In data
+// files it is an object with one of its fields set or a line of text such
+// as "Strength 13 or higher", which is parsed the same way.
+type Prerequisite struct {
+	Ability      string `json:"ability,omitempty"` // with Score: a minimum ability score
+	Score        int    `json:"score,omitempty"`
+	Proficiency  string `json:"proficiency,omitempty"`  // "heavy armor"
+	Spellcasting bool   `json:"spellcasting,omitempty"` // the ability to cast at least one spell
+	Species      string `json:"species,omitempty"`
+	Level        int    `json:"level,omitempty"` // a minimum character level
+	Text         string `json:"text,omitempty"`  // anything else, as written
+}
+
+// abilityPrerequisite matches "Strength 13 or higher" and "Dex 13+".
+var abilityPrerequisite = regexp.MustCompile(`(?i)^(\w+)\s+(\d+)(?:\s+or\s+higher|\s*\+)?$`)
+
+// ParsePrerequisite parses a prerequisite written out, such as "Strength
+// 13 or higher", "Proficiency with heavy armor", "The ability to cast at
+// least one spell" or "4th level". Anything else, a species included, is
+// kept as written.
+func ParsePrerequisite(text string) Prerequisite {
+	text = strings.TrimSuffix(strings.TrimSpace(text), ".")
+	lower := strings.ToLower(text)
+	if m := abilityPrerequisite.FindStringSubmatch(text); m != nil {
+		if i := abilityIndex(m[1]); i >= 0 {
+			score, _ := strconv.Atoi(m[2])
+			return Prerequisite{Ability: fullAbilityNames[i], Score: score}
+		}
+	}
+	switch {
+	case strings.HasPrefix(lower, "proficiency with "):
+		return Prerequisite{Proficiency: text[len("proficiency with "):]}
+	case strings.Contains(lower, "ability to cast"):
+		return Prerequisite{Spellcasting: true}
+	case strings.HasSuffix(lower, " level") || strings.HasPrefix(lower, "level "):
+		number := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(lower, "level "), " level"))
+		number = strings.TrimRight(number, "stndrh")
+		if level, err := strconv.Atoi(number); err == nil && level >= 1 && level <= 20 {
+			return Prerequisite{Level: level}
+		}
+	}
+	return Prerequisite{Text: text}
+}
+
+// UnmarshalJSON decodes a prerequisite from an object or a line of text.
+func (p *Prerequisite) UnmarshalJSON(b []byte) error {
+	var text string
+	if err := json.Unmarshal(b, &text); err == nil {
+		*p = ParsePrerequisite(text)
+		return nil
+	}
+	type plain Prerequisite
+	if err := json.Unmarshal(b, (*plain)(p)); err != nil {
+		return err
+	}
+	if p.Ability != "" {
+		i := abilityIndex(p.Ability)
+		if i < 0 {
+			return fmt.Errorf("invalid prerequisite ability %q", p.Ability)
+		}
+		p.Ability = fullAbilityNames[i]
+		if p.Score < 1 || p.Score > 30 {
+			return fmt.Errorf("invalid prerequisite score %d for %s", p.Score, p.Ability)
+		}
+	}
+	if p.Level < 0 || p.Level > 20 {
+		return fmt.Errorf("invalid prerequisite level %d", p.Level)
+	}
+	return nil
+}
+
+// String renders the prerequisite as it is written in a book.
+func (p Prerequisite) String() string {
+	switch {
+	case p.Ability != "":
+		return fmt.Sprintf("%s %d or higher", p.Ability, p.Score)
+	case p.Proficiency != "":
+		return "Proficiency with " + p.Proficiency
+	case p.Spellcasting:
+		return "The ability to cast at least one spell"
+	case p.Species != "":
+		return p.Species
+	case p.Level > 0:
+		return Ordinal(p.Level) + " level"
+	}
+	return p.Text
+}
+
+// PrerequisiteText renders the feat's prerequisites as one line, or "" if
+// it has none.
+func (f *Feat) PrerequisiteText() string {
+	parts := make([]string, len(f.Prerequisites))
+	for i, p := range f.Prerequisites {
+		parts[i] = p.String()
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Card renders the feat for display: its prerequisites, description and
+// benefits.
+func (f *Feat) Card() string {
+	var b strings.Builder
+	writeLine(&b, "Prerequisite:", f.PrerequisiteText())
+	if f.Description != "" {
+		fmt.Fprintf(&b, "\n%s\n", f.Description)
+	}
+	if len(f.Benefits) > 0 {
+		b.WriteString("\n")
+		for _, benefit := range f.Benefits {
+			fmt.Fprintf(&b, "- %s\n", benefit)
+		}
+	}
+	writeSource(&b, f.Book, f.Publisher, f.Source)
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,422 @@
+package data
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Kind names a category of content.
+type Kind string
+
+// The kinds of content that are indexed.
+const (
+	KindSpell      Kind = "spell"
+	KindMonster    Kind = "monster"
+	KindItem       Kind = "item"
+	KindSpecies    Kind = "species"
+	KindBackground Kind = "background"
+	KindClass      Kind = "class"
+	KindSubclass   Kind = "subclass"
+	KindFeat       Kind = "feat"
+	KindCondition  Kind = "condition"
+	KindRule       Kind = "rule"
+)
+
+// Kinds lists every indexed kind in display order.
+var Kinds = []Kind{KindSpell, KindMonster, KindItem, KindSpecies, KindBackground, KindClass, KindSubclass, KindFeat, KindCondition, KindRule}
+
+// Title renders the kind as a label, e.g. "Spell".
+func (k Kind) Title() string { return capitalize(string(k)) }
+
+// nameIndex maps normalized names and aliases to positions in a slice. The
+// singular map holds the same keys with every word made singular, so
+// "goblins" finds "Goblin", and the compact map holds the singular keys
+// without their spaces, so "fire ball" finds "Fireball".
+type nameIndex struct {
+	names    []string
+	exact    map[string]int
+	singular map[string]int
+	compact  map[string]int
+}
+
+// newNameIndex indexes the given names. When names repeat, the first wins.
+func newNameIndex(names []string) nameIndex {
+	idx := nameIndex{names: names, exact: make(map[string]int), singular: make(map[string]int), compact: make(map[string]int)}
+	add := func(key string, i int) {
+		if key == "" {
+			return
+		}
+		if _, ok := idx.exact[key]; !ok {
+			idx.exact[key] = i
+		}
+		if s := singularPhrase(key); s != "" {
+			if _, ok := idx.singular[s]; !ok {
+				idx.singular[s] = i
+			}
+			if _, ok := idx.compact[compactPhrase(s)]; !ok {
+				idx.compact[compactPhrase(s)] = i
+			}
+		}
+	}
+	for i, name := range names {
+		for _, alias := range nameAliases(name) {
+			add(alias, i)
+		}
+	}
+	return idx
+}
+
+// lookup returns the position of the entry with the given name or alias.
+func (idx nameIndex) lookup(name string) (int, bool) {
+	key := normalizeName(name)
+	if i, ok := idx.exact[key]; ok {
+		return i, true
+	}
+	if i, ok := idx.singular[singularPhrase(key)]; ok {
+		return i, true
+	}
+	i, ok := idx.compact[compactPhrase(singularPhrase(key))]
+	return i, ok
+}
+
+// normalizeName lowercases a name, drops apostrophes and turns any other
+// punctuation into single spaces: "Tasha’s Hideous-Laughter" becomes
+// "tashas hideous laughter".
+func normalizeName(name string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r == '\'' || r == '’':
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteRune(r)
+		default:
+			space = true
+		}
+	}
+	return b.String()
+}
+
+// nameAliases returns the normalized keys a name can be looked up by: the
+// name itself and, for names that start with an owner ("Leomund's Tiny
+// Hut"), the name without the owner.
+func nameAliases(name string) []string {
+	aliases := []string{normalizeName(name)}
+	if first, rest, ok := strings.Cut(strings.TrimSpace(name), " "); ok {
+		if strings.HasSuffix(first, "'s") || strings.HasSuffix(first, "’s") {
+			aliases = append(aliases, normalizeName(rest))
+		}
+	}
+	return aliases
+}
+
+// singularPhrase makes every word of a normalized phrase singular.
+func singularPhrase(phrase string) string {
+	words := strings.Fields(phrase)
+	for i, w := range words {
+		words[i] = singular(w)
+	}
+	return strings.Join(words, " ")
+}
+
+// compactPhrase drops the spaces of a normalized phrase, so that "fire
+// ball" and "fireball" are the same.
+func compactPhrase(phrase string) string { return strings.ReplaceAll(phrase, " ", "") }
+
+// singular returns a rough singular form of an English word: "wolves" is
+// "wolf", "harpies" is "harpy" and "goblins" is "goblin".
+func singular(word string) string {
+	if len(word) <= 3 {
+		return word
+	}
+	switch {
+	case strings.HasSuffix(word, "ies"):
+		return word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "ves"):
+		return word[:len(word)-3] + "f"
+	case strings.HasSuffix(word, "ches"), strings.HasSuffix(word, "shes"),
+		strings.HasSuffix(word, "sses"), strings.HasSuffix(word, "xes"):
+		return word[:len(word)-2]
+	case strings.HasSuffix(word, "ss"), strings.HasSuffix(word, "us"), strings.HasSuffix(word, "is"):
+		return word
+	case strings.HasSuffix(word, "s"):
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+// Hit is one result of a full-text search.
+type Hit struct {
+	Kind    Kind
+	Name    string
+	Score   float64
+	Snippet string // the text around the first match
+}
+
+// document is one indexed entry.
+type document struct {
+	kind Kind
+	name string
+	text string // everything shown for the entry, used for snippets
+}
+
+// posting records how often a term occurs in a document.
+type posting struct {
+	doc    int
+	count  int
+	inName bool
+}
+
+// textIndex is an inverted index from terms to the documents they occur in.
+type textIndex struct {
+	docs     []document
+	postings map[string][]posting
+	terms    []string // every term, sorted, for prefix matches
+}
+
+// Search weights.
+const (
+	nameWeight   = 3.0 // a term in the name counts this many times more
+	prefixWeight = 0.5 // a term matched by prefix counts this much
+	snippetWidth = 60  // characters of context on each side of a match
+)
+
+// tokenize splits text into singular lowercase words.
+func tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, w := range words {
+		words[i] = singular(w)
+	}
+	return words
+}
+
+// newTextIndex indexes the given documents.
+func newTextIndex(docs []document) *textIndex {
+	idx := &textIndex{docs: docs, postings: make(map[string][]posting)}
+	for i, d := range docs {
+		counts := make(map[string]int)
+		for _, term := range tokenize(d.text) {
+			counts[term]++
+		}
+		inName := make(map[string]bool)
+		for _, term := range tokenize(d.name) {
+			inName[term] = true
+			if counts[term] == 0 {
+				counts[term] = 1
+			}
+		}
+		for term, n := range counts {
+			idx.postings[term] = append(idx.postings[term], posting{doc: i, count: n, inName: inName[term]})
+		}
+	}
+	for term := range idx.postings {
+		idx.terms = append(idx.terms, term)
+	}
+	sort.Strings(idx.terms)
+	return idx
+}
+
+// search returns the documents containing every query word, best first.
+// Words of three or more letters also match terms they begin, so "fright"
+// finds "frightened".
+func (idx *textIndex) search(query string) []Hit {
+	words := tokenize(query)
+	if len(words) == 0 {
+		return nil
+	}
+	var scores map[int]float64
+	for _, word := range words {
+		wordScores := make(map[int]float64)
+		for _, term := range idx.expand(word) {
+			weight := 1.0
+			if term != word {
+				weight = prefixWeight
+			}
+			postings := idx.postings[term]
+			idf := math.Log(1 + float64(len(idx.docs))/float64(len(postings)))
+			for _, p := range postings {
+				score := (1 + math.Log(float64(p.count))) * idf * weight
+				if p.inName {
+					score *= nameWeight
+				}
+				wordScores[p.doc] = math.Max(wordScores[p.doc], score)
+			}
+		}
+		if scores == nil {
+			scores = wordScores
+			continue
+		}
+		for doc := range scores {
+			if s, ok := wordScores[doc]; ok {
+				scores[doc] += s
+			} else {
+				delete(scores, doc)
+			}
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for doc, score := range scores {
+		d := idx.docs[doc]
+		hits = append(hits, Hit{Kind: d.kind, Name: d.name, Score: score, Snippet: snippet(d.text, words)})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Name < hits[j].Name
+	})
+	return hits
+}
+
+// expand returns the indexed terms a query word matches: the word itself
+// and, if it is long enough, every term it is a prefix of.
+func (idx *textIndex) expand(word string) []string {
+	var terms []string
+	if _, ok := idx.postings[word]; ok {
+		terms = append(terms, word)
+	}
+	if len(word) < 3 {
+		return terms
+	}
+	for i := sort.SearchStrings(idx.terms, word); i < len(idx.terms) && strings.HasPrefix(idx.terms[i], word); i++ {
+		if idx.terms[i] != word {
+			terms = append(terms, idx.terms[i])
+		}
+	}
+	return terms
+}
+
+// snippet returns the text around the first occurrence of any of the words,
+// on one line.
+func snippet(text string, words []string) string {
+	at := -1
+	for _, w := range words {
+		if i := indexFold(text, w); i >= 0 && (at < 0 || i < at) {
+			at = i
+		}
+	}
+	if at < 0 {
+		at = 0
+	}
+	start, end := at-snippetWidth, at+snippetWidth
+	prefix, suffix := "…", "…"
+	if start <= 0 {
+		start, prefix = 0, ""
+	} else if i := strings.IndexAny(text[start:at], " \n"); i >= 0 {
+		start += i + 1 // don't start mid-word
+	}
+	if end >= len(text) {
+		end, suffix = len(text), ""
+	} else if i := strings.LastIndexAny(text[at:end], " \n"); i > 0 {
+		end = at + i // don't end mid-word
+	}
+	for start > 0 && !isRuneStart(text[start]) {
+		start--
+	}
+	for end < len(text) && !isRuneStart(text[end]) {
+		end++
+	}
+	return prefix + strings.Join(strings.Fields(text[start:end]), " ") + suffix
+}
+
+// indexFold returns the byte offset in text of the first occurrence of
+// word, ignoring case, or -1. Unlike searching strings.ToLower(text), the
+// offset is into text itself, whose runes may lowercase to a different
+// number of bytes.
+func indexFold(text, word string) int {
+	n := utf8.RuneCountInString(word)
+	if n == 0 {
+		return -1
+	}
+	for i := range text {
+		end, runes := i, 0
+		for end < len(text) && runes < n {
+			_, size := utf8.DecodeRuneInString(text[end:])
+			end += size
+			runes++
+		}
+		if runes == n && strings.EqualFold(text[i:end], word) {
+			return i
+		}
+	}
+	return -1
+}
+
+// isRuneStart reports whether b begins a UTF-8 encoded rune.
+func isRuneStart(b byte) bool { return b&0xC0 != 0x80 }
+
+// buildIndexes indexes the data set's entries by name and by content.
+func (d *dataset) buildIndexes() {
+	var docs []document
+	d.spellIndex = newNameIndex(names(d.spells, func(s *Spell) string { return s.Name }))
+	for i := range d.spells {
+		docs = append(docs, document{KindSpell, d.spells[i].Name, d.spells[i].Card()})
+	}
+	d.monsterIndex = newNameIndex(names(d.monsters, func(m *Monster) string { return m.Name }))
+	for i := range d.monsters {
+		docs = append(docs, document{KindMonster, d.monsters[i].Name, d.monsters[i].StatBlock()})
+	}
+	d.itemIndex = newNameIndex(names(d.items, func(it *Item) string { return it.Name }))
+	for i := range d.items {
+		docs = append(docs, document{KindItem, d.items[i].Name, d.items[i].Card()})
+	}
+	d.speciesIndex = newNameIndex(names(d.species, func(s *Species) string { return s.Name }))
+	for _, s := range d.species {
+		docs = append(docs, document{KindSpecies, s.Name, s.Description})
+	}
+	d.backgroundIndex = newNameIndex(names(d.backgrounds, func(b *Background) string { return b.Name }))
+	for _, b := range d.backgrounds {
+		docs = append(docs, document{KindBackground, b.Name, b.Description})
+	}
+	d.classIndex = newNameIndex(names(d.classes, func(c *Class) string { return c.Name }))
+	for _, c := range d.classes {
+		docs = append(docs, document{KindClass, c.Name, c.Description})
+	}
+	d.subclassIndex = newNameIndex(names(d.subclasses, func(s *Subclass) string { return s.Name }))
+	for i := range d.subclasses {
+		docs = append(docs, document{KindSubclass, d.subclasses[i].Name, d.subclasses[i].Card()})
+	}
+	d.featIndex = newNameIndex(names(d.feats, func(f *Feat) string { return f.Name }))
+	for i := range d.feats {
+		docs = append(docs, document{KindFeat, d.feats[i].Name, d.feats[i].Card()})
+	}
+	d.conditionIndex = newNameIndex(names(d.conditions, func(c *Condition) string { return c.Name }))
+	for i := range d.conditions {
+		docs = append(docs, document{KindCondition, d.conditions[i].Name, d.conditions[i].Card()})
+	}
+	d.ruleIndex = newNameIndex(names(d.rules, func(r *Rule) string { return r.Name }))
+	for i := range d.rules {
+		docs = append(docs, document{KindRule, d.rules[i].Name, d.rules[i].Card()})
+	}
+	d.fullText = newTextIndex(docs)
+}
+
+// names returns the name of every entry.
+func names[T any](entries []T, name func(*T) string) []string {
+	out := make([]string, len(entries))
+	for i := range entries {
+		out[i] = name(&entries[i])
+	}
+	return out
+}
+
+// lookupByName finds an entry through a name index. On a miss it returns
+// a *NotFoundError with the closest names.
+func lookupByName[T any](kind Kind, index *nameIndex, entries []T, name string) (*T, error) {
+	i, ok := index.lookup(name)
+	if !ok || i >= len(entries) {
+		return nil, notFound(kind, index, name)
+	}
+	entry := entries[i]
+	return &entry, nil
+}
@@ -0,0 +1,383 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"dnd-cli/internal/dice"
+)
+
+// Item is a piece of equipment or a magic item from items.json. Weapon and
+// Armor are set only for weapons and armor (including shields).
+type Item struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Category    string  `json:"category"` // e.g. "Weapon", "Armor", "Adventuring Gear", "Wondrous Item"
+	Cost        Cost    `json:"cost"`
+	Weight      float64 `json:"weight"` // in pounds
+	Rarity      string  `json:"rarity"`
+	Attunement  bool    `json:"attunement"`
+	AttunedBy   string  `json:"attuned_by"` // e.g. "a spellcaster"; empty when anyone can attune
+	Weapon      *Weapon `json:"weapon"`
+	Armor       *Armor  `json:"armor"`
+	Publisher   string  `json:"publisher"`
+	Book        string  `json:"book"`
+	Source      string  `json:"-"` // tag of the source it was loaded from
+}
+
+// Weapon holds the combat statistics of a weapon.
+type Weapon struct {
+	Category   string   `json:"category"` // e.g. "Martial Melee"
+	Damage     string   `json:"damage"`   // dice notation, e.g. "1d8"
+	DamageType string   `json:"damage_type"`
+	Properties []string `json:"properties"` // e.g. "finesse", "versatile (1d10)"
+}
+
+// HasProperty reports whether the weapon has the named property, ignoring
+// any parenthesised detail ("versatile" matches "versatile (1d10)").
+func (w *Weapon) HasProperty(name string) bool {
+	for _, p := range w.Properties {
+		base, _, _ := strings.Cut(p, "(")
+		if strings.EqualFold(strings.TrimSpace(base), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Armor holds the statistics of a suit of armor or a shield.
+type Armor struct {
+	Category            string `json:"category"` // "Light", "Medium", "Heavy" or "Shield"
+	BaseAC              int    `json:"base_ac"`  // for a shield, the bonus it adds
+	DexCap              *int   `json:"dex_cap"`  // nil uses the category's rule
+	StrRequirement      int    `json:"str_requirement"`
+	StealthDisadvantage bool   `json:"stealth_disadvantage"`
+}
+
+// MaxDex returns the largest Dexterity modifier the armor allows, or -1 if
+// it is unlimited.
+func (a *Armor) MaxDex() int {
+	if a.DexCap != nil {
+		return *a.DexCap
+	}
+	switch strings.ToLower(a.Category) {
+	case "medium":
+		return 2
+	case "heavy", "shield":
+		return 0
+	}
+	return -1
+}
+
+// AC returns the armor class the armor gives a wearer with the given
+// Dexterity modifier. For a shield it returns the bonus.
+func (a *Armor) AC(dexMod int) int {
+	if strings.EqualFold(a.Category, "shield") {
+		return a.BaseAC
+	}
+	if limit := a.MaxDex(); limit >= 0 && dexMod > limit {
+		dexMod = limit
+	}
+	return a.BaseAC + dexMod
+}
+
+// ACString renders the armor class as in the equipment tables: "16",
+// "11 + Dex modifier", "14 + Dex modifier (max 2)" or "+2".
+func (a *Armor) ACString() string {
+	switch limit := a.MaxDex(); {
+	case strings.EqualFold(a.Category, "shield"):
+		return fmt.Sprintf("+%d", a.BaseAC)
+	case limit == 0:
+		return strconv.Itoa(a.BaseAC)
+	case limit < 0:
+		return fmt.Sprintf("%d + Dex modifier", a.BaseAC)
+	default:
+		return fmt.Sprintf("%d + Dex modifier (max %d)", a.BaseAC, limit)
+	}
+}
+
+// Cost is a price in one of the five coin denominations.
+type Cost struct {
+	Amount int
+	Unit   string // "cp", "sp", "ep", "gp" or "pp"
+}
+
+// copperPerCoin is the value of each coin in copper pieces.
+var copperPerCoin = map[string]int{"cp": 1, "sp": 10, "ep": 50, "gp": 100, "pp": 1000}
+
+// costPattern matches "15 gp" or "1,500 gp".
+var costPattern = regexp.MustCompile(`^\s*([\d,]+)\s*(cp|sp|ep|gp|pp)\s*$`)
+
+// ParseCost parses a price such as "15 gp". An empty string or "—" is no cost.
+func ParseCost(text string) (Cost, error) {
+	text = strings.TrimSpace(text)
+	if text == "" || text == "—" || text == "-" {
+		return Cost{}, nil
+	}
+	match := costPattern.FindStringSubmatch(strings.ToLower(text))
+	if match == nil {
+		return Cost{}, fmt.Errorf("invalid cost %q", text)
+	}
+	amount, err := strconv.Atoi(strings.ReplaceAll(match[1], ",", ""))
+	if err != nil {
+		return Cost{}, fmt.Errorf("invalid cost %q", text)
+	}
+	return Cost{Amount: amount, Unit: match[2]}, nil
+}
+
+// Copper returns the cost in copper pieces.
+func (c Cost) Copper() int { return c.Amount * copperPerCoin[c.Unit] }
+
+// String renders the cost as "15 gp", or "" if there is none.
+func (c Cost) String() string {
+	if c.Unit == "" {
+		return ""
+	}
+	return fmt.Sprintf("%d %s", c.Amount, c.Unit)
+}
+
+// UnmarshalJSON accepts a price such as "15 gp" or a number of gold pieces.
+func (c *Cost) UnmarshalJSON(b []byte) error {
+	var text string
+	if err := json.Unmarshal(b, &text); err == nil {
+		cost, err := ParseCost(text)
+		*c = cost
+		return err
+	}
+	var gp int
+	if err := json.Unmarshal(b, &gp); err != nil {
+		return fmt.Errorf("invalid cost %s", b)
+	}
+	*c = Cost{Amount: gp, Unit: "gp"}
+	return nil
+}
+
+// MarshalJSON writes the cost as a price string.
+func (c Cost) MarshalJSON() ([]byte, error) { return json.Marshal(c.String()) }
+
+// UnmarshalJSON decodes and validates an item. Besides the typed fields,
+// entries may carry a "properties" map keyed by printed names ("Cost",
+// "Weight", "Damage", "Armor Class", "Stealth", ...) which fills in any
+// typed fields left empty.
+func (it *Item) UnmarshalJSON(b []byte) error {
+	type plain Item
+	var raw struct {
+		plain
+		Properties map[string]interface{} `json:"properties"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	*it = Item(raw.plain)
+	if err := it.applyProperties(raw.Properties); err != nil {
+		return fmt.Errorf("item %q: %w", it.Name, err)
+	}
+	if err := it.validate(); err != nil {
+		return fmt.Errorf("item %q: %w", it.Name, err)
+	}
+	return nil
+}
+
+// weaponDamage matches "1d8 slashing" or "2 bludgeoning".
+var weaponDamage = regexp.MustCompile(`^\s*(\S+)\s+(\w+)`)
+
+// armorClass matches "14 + Dex modifier (max 2)", "11 + Dex modifier", "18"
+// or "+2".
+var armorClass = regexp.MustCompile(`^\s*(\+)?(\d+)\s*(\+\s*dex(?:terity)?(?: modifier)?)?\s*(?:\(max (\d+)\))?`)
+
+// digitsPattern finds the number in a Strength requirement such as "Str 13".
+var digitsPattern = regexp.MustCompile(`\d+`)
+
+// applyProperties fills empty fields from a map of printed item lines.
+func (it *Item) applyProperties(props map[string]interface{}) error {
+	weapon, armor := it.Weapon, it.Armor
+	if weapon == nil {
+		weapon = &Weapon{}
+	}
+	if armor == nil {
+		armor = &Armor{}
+	}
+	for key, value := range props {
+		text := strings.TrimSpace(fmt.Sprint(value))
+		var err error
+		switch strings.ToLower(key) {
+		case "type", "category", "item type":
+			if it.Category == "" {
+				it.Category = text
+			}
+		case "cost":
+			if it.Cost == (Cost{}) {
+				it.Cost, err = ParseCost(text)
+			}
+		case "weight":
+			if it.Weight == 0 {
+				it.Weight, err = parseWeight(value)
+			}
+		case "rarity":
+			if it.Rarity == "" {
+				it.Rarity = text
+			}
+		case "attunement", "requires attunement":
+			if text != "" && !strings.EqualFold(text, "no") && !strings.EqualFold(text, "false") {
+				it.Attunement = true
+				if by, found := strings.CutPrefix(strings.ToLower(text), "by "); found {
+					it.AttunedBy = by
+				}
+			}
+		case "damage":
+			if match := weaponDamage.FindStringSubmatch(text); match != nil && weapon.Damage == "" {
+				weapon.Damage, weapon.DamageType = match[1], match[2]
+			}
+		case "properties", "weapon properties":
+			if weapon.Properties == nil {
+				weapon.Properties = splitList(value)
+			}
+		case "weapon category":
+			weapon.Category = text
+		case "armor category":
+			armor.Category = text
+		case "armor class", "ac":
+			match := armorClass.FindStringSubmatch(strings.ToLower(text))
+			if match == nil {
+				err = fmt.Errorf("invalid armor class %q", text)
+				break
+			}
+			armor.BaseAC, _ = strconv.Atoi(match[2])
+			switch {
+			case match[1] == "+":
+				armor.Category = "Shield"
+			case match[4] != "":
+				limit, _ := strconv.Atoi(match[4])
+				armor.DexCap = &limit
+			case match[3] == "":
+				limit := 0
+				armor.DexCap = &limit
+			}
+		case "strength":
+			if digits := digitsPattern.FindString(text); digits != "" {
+				armor.StrRequirement, _ = strconv.Atoi(digits)
+			}
+		case "stealth":
+			armor.StealthDisadvantage = strings.Contains(strings.ToLower(text), "disadvantage")
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if weapon.Damage != "" && it.Weapon == nil {
+		it.Weapon = weapon
+	}
+	if armor.BaseAC != 0 && it.Armor == nil {
+		it.Armor = armor
+	}
+	return nil
+}
+
+// parseWeight parses a weight in pounds given as a number or "3 lb.".
+func parseWeight(value interface{}) (float64, error) {
+	if v, ok := value.(float64); ok {
+		return v, nil
+	}
+	text := strings.TrimSpace(fmt.Sprint(value))
+	if text == "" || text == "—" || text == "-" {
+		return 0, nil
+	}
+	number, _, _ := strings.Cut(text, " ")
+	w, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid weight %q", text)
+	}
+	return w, nil
+}
+
+// validate checks the item's weapon and armor statistics.
+func (it *Item) validate() error {
+	if it.Name == "" {
+		return fmt.Errorf("missing name")
+	}
+	if it.Weight < 0 {
+		return fmt.Errorf("negative weight %v", it.Weight)
+	}
+	if w := it.Weapon; w != nil {
+		if _, err := strconv.Atoi(w.Damage); err != nil {
+			if _, err := dice.ParseDiceNotation(w.Damage); err != nil {
+				return fmt.Errorf("invalid weapon damage: %w", err)
+			}
+		}
+		if it.Category == "" {
+			it.Category = "Weapon"
+		}
+	}
+	if a := it.Armor; a != nil {
+		if a.BaseAC <= 0 {
+			return fmt.Errorf("invalid armor class %d", a.BaseAC)
+		}
+		if it.Category == "" {
+			it.Category = "Armor"
+		}
+	}
+	return nil
+}
+
+// Summary renders the item's category line, e.g. "Weapon (martial melee)"
+// or "Wondrous Item, rare (requires attunement by a spellcaster)".
+func (it *Item) Summary() string {
+	line := it.Category
+	if line == "" {
+		line = "Item"
+	}
+	switch {
+	case it.Weapon != nil && it.Weapon.Category != "":
+		line += " (" + strings.ToLower(it.Weapon.Category) + ")"
+	case it.Armor != nil && it.Armor.Category != "" && !strings.EqualFold(it.Armor.Category, it.Category):
+		line += " (" + strings.ToLower(it.Armor.Category) + ")"
+	}
+	if it.Rarity != "" {
+		line += ", " + strings.ToLower(it.Rarity)
+	}
+	if it.Attunement {
+		attune := "requires attunement"
+		if it.AttunedBy != "" {
+			attune += " by " + it.AttunedBy
+		}
+		line += " (" + attune + ")"
+	}
+	return line
+}
+
+// Card renders the item as a plain-text card with its fields in a fixed
+// order.
+func (it *Item) Card() string {
+	var fields strings.Builder
+	writeLine(&fields, "Cost:", it.Cost.String())
+	if it.Weight > 0 {
+		fmt.Fprintf(&fields, "Weight: %s lb.\n", strconv.FormatFloat(it.Weight, 'f', -1, 64))
+	}
+	if w := it.Weapon; w != nil {
+		writeLine(&fields, "Damage:", strings.TrimSpace(w.Damage+" "+w.DamageType))
+		writeLine(&fields, "Properties:", strings.Join(w.Properties, ", "))
+	}
+	if a := it.Armor; a != nil {
+		writeLine(&fields, "Armor Class:", a.ACString())
+		if a.StrRequirement > 0 {
+			fmt.Fprintf(&fields, "Strength: Str %d\n", a.StrRequirement)
+		}
+		if a.StealthDisadvantage {
+			fields.WriteString("Stealth: Disadvantage\n")
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n", it.Summary())
+	if fields.Len() > 0 {
+		fmt.Fprintf(&b, "\n%s", fields.String())
+	}
+	if it.Description != "" {
+		fmt.Fprintf(&b, "\n%s\n", it.Description)
+	}
+	writeSource(&b, it.Book, it.Publisher, it.Source)
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,430 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Monster is a creature's stat block from monsters.json.
+type Monster struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+
+	Size      string `json:"size"`
+	Type      string `json:"type"`
+	Subtype   string `json:"subtype"`
+	Alignment string `json:"alignment"`
+
+	ArmorClass          int             `json:"armor_class"`
+	ArmorDesc           string          `json:"armor_desc"`
+	HitPoints           int             `json:"hit_points"`
+	HitDice             string          `json:"hit_dice"`
+	Speed               string          `json:"speed"`
+	AbilityScores       AbilityScores   `json:"ability_scores"`
+	SavingThrows        map[string]int  `json:"saving_throws"`
+	Skills              map[string]int  `json:"skills"`
+	Vulnerabilities     string          `json:"damage_vulnerabilities"`
+	Resistances         string          `json:"damage_resistances"`
+	Immunities          string          `json:"damage_immunities"`
+	ConditionImmunities string          `json:"condition_immunities"`
+	Senses              string          `json:"senses"`
+	Languages           string          `json:"languages"`
+	ChallengeRating     ChallengeRating `json:"challenge_rating"`
+	HasChallengeRating  bool            `json:"-"` // whether a rating was given; 0 is a rating
+	XP                  int             `json:"xp"`
+	Environments        []string        `json:"environments"` // where it is encountered, e.g. "swamp"
+
+	Traits           []Feature `json:"traits"`
+	Actions          []Feature `json:"actions"`
+	Reactions        []Feature `json:"reactions"`
+	LegendaryActions []Feature `json:"legendary_actions"`
+
+	Publisher string `json:"publisher"`
+	Book      string `json:"book"`
+	Source    string `json:"-"` // tag of the source it was loaded from
+}
+
+// AbilityScores are the six ability scores of a creature.
+type AbilityScores struct {
+	Str int `json:"str"`
+	Dex int `json:"dex"`
+	Con int `json:"con"`
+	Int int `json:"int"`
+	Wis int `json:"wis"`
+	Cha int `json:"cha"`
+}
+
+// abilityNames lists the abilities in stat block order.
+var abilityNames = []string{"STR", "DEX", "CON", "INT", "WIS", "CHA"}
+
+// values returns the scores in stat block order.
+func (a AbilityScores) values() []int {
+	return []int{a.Str, a.Dex, a.Con, a.Int, a.Wis, a.Cha}
+}
+
+// fields returns pointers to the scores in stat block order.
+func (a *AbilityScores) fields() []*int {
+	return []*int{&a.Str, &a.Dex, &a.Con, &a.Int, &a.Wis, &a.Cha}
+}
+
+// abilityIndex returns the stat block position of an ability given by its
+// short or full name ("DEX", "Dexterity"), or -1.
+func abilityIndex(name string) int {
+	for i := range abilityNames {
+		if strings.EqualFold(name, abilityNames[i]) || strings.EqualFold(name, fullAbilityNames[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// fullAbilityNames lists the abilities' full names in stat block order.
+var fullAbilityNames = []string{"Strength", "Dexterity", "Constitution", "Intelligence", "Wisdom", "Charisma"}
+
+// AbilityModifier returns the modifier for an ability score.
+func AbilityModifier(score int) int {
+	if score >= 10 {
+		return (score - 10) / 2
+	}
+	return (score - 11) / 2
+}
+
+// Feature is a named trait, action, reaction or legendary action.
+type Feature struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// ChallengeRating is a monster's challenge rating. Fractional ratings are
+// 0.125, 0.25 and 0.5. In JSON it may be a number or a string such as "1/4".
+type ChallengeRating float64
+
+// ParseChallengeRating parses "1/8", "1/4", "1/2", "0.5" or a whole number.
+func ParseChallengeRating(s string) (ChallengeRating, error) {
+	s = strings.TrimSpace(s)
+	if num, den, found := strings.Cut(s, "/"); found {
+		n, err1 := strconv.Atoi(strings.TrimSpace(num))
+		d, err2 := strconv.Atoi(strings.TrimSpace(den))
+		if err1 != nil || err2 != nil || d == 0 {
+			return 0, fmt.Errorf("invalid challenge rating %q", s)
+		}
+		return ChallengeRating(float64(n) / float64(d)), nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid challenge rating %q", s)
+	}
+	return ChallengeRating(v), nil
+}
+
+// UnmarshalJSON accepts a number or a string such as "1/4".
+func (cr *ChallengeRating) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		if s == "" {
+			*cr = 0
+			return nil
+		}
+		v, err := ParseChallengeRating(s)
+		*cr = v
+		return err
+	}
+	var v float64
+	if err := json.Unmarshal(b, &v); err != nil {
+		return fmt.Errorf("invalid challenge rating %s", b)
+	}
+	*cr = ChallengeRating(v)
+	return nil
+}
+
+// String renders the rating as "1/4", "1/2" or a whole number.
+func (cr ChallengeRating) String() string {
+	switch cr {
+	case 0.125:
+		return "1/8"
+	case 0.25:
+		return "1/4"
+	case 0.5:
+		return "1/2"
+	}
+	return strconv.FormatFloat(float64(cr), 'f', -1, 64)
+}
+
+// xpByCR is the experience award for each challenge rating.
+var xpByCR = map[ChallengeRating]int{
+	0: 10, 0.125: 25, 0.25: 50, 0.5: 100, 1: 200, 2: 450, 3: 700, 4: 1100,
+	5: 1800, 6: 2300, 7: 2900, 8: 3900, 9: 5000, 10: 5900, 11: 7200, 12: 8400,
+	13: 10000, 14: 11500, 15: 13000, 16: 15000, 17: 18000, 18: 20000, 19: 22000,
+	20: 25000, 21: 33000, 22: 41000, 23: 50000, 24: 62000, 25: 75000, 26: 90000,
+	27: 105000, 28: 120000, 29: 135000, 30: 155000,
+}
+
+// XP returns the experience award for the rating, or 0 if it is not a
+// standard rating.
+func (cr ChallengeRating) XP() int { return xpByCR[cr] }
+
+// Initiative returns the monster's initiative modifier.
+func (m *Monster) Initiative() int { return AbilityModifier(m.AbilityScores.Dex) }
+
+// UnmarshalJSON decodes a stat block. Besides the typed fields, entries may
+// carry a "properties" map of stat block lines keyed by their printed names
+// ("Armor Class": "15 (leather armor)", "STR": "8 (-1)", "Challenge":
+// "1/4 (50 XP)", ...), which fill in any typed fields left empty. A
+// challenge rating that doesn't parse is an error naming the monster; the
+// loader leaves it out and reports it with its file and line.
+func (m *Monster) UnmarshalJSON(b []byte) error {
+	type plain Monster
+	var raw struct {
+		plain
+		ChallengeRating json.RawMessage        `json:"challenge_rating"`
+		Properties      map[string]interface{} `json:"properties"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	*m = Monster(raw.plain)
+	if cr := string(raw.ChallengeRating); cr != "" && cr != "null" && cr != `""` {
+		if err := m.ChallengeRating.UnmarshalJSON(raw.ChallengeRating); err != nil {
+			return fmt.Errorf("monster %q: %w", m.Name, err)
+		}
+		m.HasChallengeRating = true
+	}
+	if err := m.applyProperties(raw.Properties); err != nil {
+		return fmt.Errorf("monster %q: %w", m.Name, err)
+	}
+	if m.XP == 0 && m.HasChallengeRating {
+		m.XP = m.ChallengeRating.XP()
+	}
+	return nil
+}
+
+// leadingNumber matches a number with an optional parenthesised remark, as
+// in "15 (natural armor)" or "7 (2d6)".
+var leadingNumber = regexp.MustCompile(`^\s*(\d+)\s*(?:\((.*)\))?`)
+
+// xpAward matches the "(1,100 XP)" after a challenge rating.
+var xpAward = regexp.MustCompile(`([\d,]+)\s*XP`)
+
+// signedList matches the entries of "Dex +5, Con +3" style lists.
+var signedList = regexp.MustCompile(`([A-Za-z][A-Za-z ]*?)\s*([+-]\d+)`)
+
+// applyProperties fills empty fields from a map of printed stat block lines.
+func (m *Monster) applyProperties(props map[string]interface{}) error {
+	for key, value := range props {
+		text := strings.TrimSpace(fmt.Sprint(value))
+		switch k := strings.ToLower(key); {
+		case k == "armor class" && m.ArmorClass == 0:
+			if match := leadingNumber.FindStringSubmatch(text); match != nil {
+				m.ArmorClass, _ = strconv.Atoi(match[1])
+				m.ArmorDesc = match[2]
+			}
+		case k == "hit points" && m.HitPoints == 0:
+			if match := leadingNumber.FindStringSubmatch(text); match != nil {
+				m.HitPoints, _ = strconv.Atoi(match[1])
+				m.HitDice = match[2]
+			}
+		case k == "speed" && m.Speed == "":
+			m.Speed = text
+		case k == "size" && m.Size == "":
+			m.Size = text
+		case k == "type" && m.Type == "":
+			m.Type = text
+		case k == "alignment" && m.Alignment == "":
+			m.Alignment = text
+		case k == "saving throws" && m.SavingThrows == nil:
+			m.SavingThrows = parseSignedList(text)
+		case k == "skills" && m.Skills == nil:
+			m.Skills = parseSignedList(text)
+		case k == "damage vulnerabilities" && m.Vulnerabilities == "":
+			m.Vulnerabilities = text
+		case k == "damage resistances" && m.Resistances == "":
+			m.Resistances = text
+		case k == "damage immunities" && m.Immunities == "":
+			m.Immunities = text
+		case k == "condition immunities" && m.ConditionImmunities == "":
+			m.ConditionImmunities = text
+		case k == "senses" && m.Senses == "":
+			m.Senses = text
+		case k == "languages" && m.Languages == "":
+			m.Languages = text
+		case (k == "challenge" || k == "challenge rating" || k == "cr") && !m.HasChallengeRating:
+			rating, xp, _ := strings.Cut(text, " ")
+			cr, err := ParseChallengeRating(rating)
+			if err != nil {
+				return err
+			}
+			m.ChallengeRating, m.HasChallengeRating = cr, true
+			if match := xpAward.FindStringSubmatch(xp); match != nil && m.XP == 0 {
+				m.XP, _ = strconv.Atoi(strings.ReplaceAll(match[1], ",", ""))
+			}
+		case (k == "environment" || k == "environments") && m.Environments == nil:
+			m.Environments = splitList(value)
+		case abilityIndex(k) >= 0:
+			score := m.AbilityScores.fields()[abilityIndex(k)]
+			if match := leadingNumber.FindStringSubmatch(text); match != nil && *score == 0 {
+				*score, _ = strconv.Atoi(match[1])
+			}
+		}
+	}
+	return nil
+}
+
+// parseSignedList parses "Dex +5, Con +3" into a map of bonuses.
+func parseSignedList(text string) map[string]int {
+	bonuses := make(map[string]int)
+	for _, match := range signedList.FindAllStringSubmatch(text, -1) {
+		bonus, _ := strconv.Atoi(match[2])
+		bonuses[strings.TrimSpace(match[1])] = bonus
+	}
+	return bonuses
+}
+
+// StatBlock renders the monster as a plain-text stat block.
+func (m *Monster) StatBlock() string {
+	var b strings.Builder
+	if line := m.typeLine(); line != "" {
+		fmt.Fprintf(&b, "%s\n\n", line)
+	}
+
+	if m.ArmorClass > 0 {
+		fmt.Fprintf(&b, "Armor Class %d%s\n", m.ArmorClass, parenthesised(m.ArmorDesc))
+	}
+	if m.HitPoints > 0 {
+		fmt.Fprintf(&b, "Hit Points %d%s\n", m.HitPoints, parenthesised(m.HitDice))
+	}
+	writeLine(&b, "Speed", m.Speed)
+
+	if m.AbilityScores != (AbilityScores{}) {
+		var names, scores strings.Builder
+		for i, score := range m.AbilityScores.values() {
+			fmt.Fprintf(&names, "%-8s", abilityNames[i])
+			fmt.Fprintf(&scores, "%-8s", fmt.Sprintf("%d (%+d)", score, AbilityModifier(score)))
+		}
+		fmt.Fprintf(&b, "\n%s\n%s\n\n", strings.TrimRight(names.String(), " "), strings.TrimRight(scores.String(), " "))
+	}
+
+	writeLine(&b, "Saving Throws", formatBonuses(m.SavingThrows, abilityOrder))
+	writeLine(&b, "Skills", formatBonuses(m.Skills, nil))
+	writeLine(&b, "Damage Vulnerabilities", m.Vulnerabilities)
+	writeLine(&b, "Damage Resistances", m.Resistances)
+	writeLine(&b, "Damage Immunities", m.Immunities)
+	writeLine(&b, "Condition Immunities", m.ConditionImmunities)
+	writeLine(&b, "Senses", m.Senses)
+	writeLine(&b, "Languages", m.Languages)
+	if m.HasChallengeRating || m.XP > 0 {
+		fmt.Fprintf(&b, "Challenge %s (%d XP)\n", m.ChallengeRating, m.XP)
+	}
+	writeLine(&b, "Environments", strings.Join(m.Environments, ", "))
+
+	writeFeatures(&b, "", m.Traits)
+	writeFeatures(&b, "Actions", m.Actions)
+	writeFeatures(&b, "Reactions", m.Reactions)
+	writeFeatures(&b, "Legendary Actions", m.LegendaryActions)
+
+	if m.Description != "" {
+		fmt.Fprintf(&b, "\n%s\n", m.Description)
+	}
+	writeSource(&b, m.Book, m.Publisher, m.Source)
+	return strings.TrimSpace(b.String())
+}
+
+// typeLine renders e.g. "Small humanoid (goblinoid), neutral evil".
+func (m *Monster) typeLine() string {
+	line := strings.TrimSpace(m.Size + " " + m.Type)
+	if m.Subtype != "" {
+		line += " (" + m.Subtype + ")"
+	}
+	if m.Alignment != "" {
+		if line != "" {
+			line += ", "
+		}
+		line += m.Alignment
+	}
+	return line
+}
+
+// abilityOrder sorts saving throws in stat block order.
+var abilityOrder = map[string]int{"str": 0, "dex": 1, "con": 2, "int": 3, "wis": 4, "cha": 5}
+
+// formatBonuses renders a bonus map as "Dex +5, Con +3". Keys found in order
+// come first in that order; the rest are sorted alphabetically.
+func formatBonuses(bonuses map[string]int, order map[string]int) string {
+	keys := make([]string, 0, len(bonuses))
+	for k := range bonuses {
+		keys = append(keys, k)
+	}
+	rank := func(k string) int {
+		if r, ok := order[strings.ToLower(k)[:min(3, len(k))]]; ok {
+			return r
+		}
+		return len(order)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if ri, rj := rank(keys[i]), rank(keys[j]); ri != rj {
+			return ri < rj
+		}
+		return keys[i] < keys[j]
+	})
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%s %+d", capitalize(k), bonuses[k])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// capitalize upper-cases the first letter of s.
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// parenthesised returns " (s)", or "" if s is empty.
+func parenthesised(s string) string {
+	if s == "" {
+		return ""
+	}
+	return " (" + s + ")"
+}
+
+// writeSource writes the book an entry comes from and, for homebrew, the
+// overlay it was loaded from.
+func writeSource(b *strings.Builder, book, publisher, tag string) {
+	homebrew := IsHomebrew(tag)
+	if book == "" && !homebrew {
+		return
+	}
+	b.WriteString("\n")
+	if book != "" {
+		fmt.Fprintf(b, "Source: %s%s\n", book, parenthesised(publisher))
+	}
+	if homebrew {
+		fmt.Fprintf(b, "Homebrew: %s\n", tag)
+	}
+}
+
+// writeLine writes "label value" if value is not empty.
+func writeLine(b *strings.Builder, label, value string) {
+	if value != "" {
+		fmt.Fprintf(b, "%s %s\n", label, value)
+	}
+}
+
+// writeFeatures writes a titled section of features as "Name. Description".
+func writeFeatures(b *strings.Builder, title string, features []Feature) {
+	if len(features) == 0 {
+		return
+	}
+	b.WriteString("\n")
+	if title != "" {
+		fmt.Fprintf(b, "%s\n", title)
+	}
+	for _, f := range features {
+		fmt.Fprintf(b, "%s. %s\n", f.Name, f.Description)
+	}
+}
@@ -0,0 +1,405 @@
+package data
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SpellQuery selects and orders spells. Zero fields match every spell.
+type SpellQuery struct {
+	Text          string // words that must all appear in the name
+	Class         string // a class that can cast the spell
+	Levels        []int  // any of these levels
+	School        string
+	Ritual        *bool
+	Concentration *bool
+	Sort          string // "name" (the default), "level" or "school"
+}
+
+// SpellSorts lists the orders a SpellQuery can sort by.
+var SpellSorts = []string{"name", "level", "school"}
+
+// Matches reports whether s satisfies every condition of q.
+func (q SpellQuery) Matches(s *Spell) bool {
+	name := strings.ToLower(s.Name)
+	for _, word := range strings.Fields(strings.ToLower(q.Text)) {
+		if !strings.Contains(name, word) {
+			return false
+		}
+	}
+	if q.Class != "" && !containsFold(s.Classes, q.Class) {
+		return false
+	}
+	if len(q.Levels) > 0 && !containsInt(q.Levels, s.Level) {
+		return false
+	}
+	if q.School != "" && !strings.EqualFold(s.School, q.School) {
+		return false
+	}
+	if q.Ritual != nil && s.Ritual != *q.Ritual {
+		return false
+	}
+	if q.Concentration != nil && s.Concentration != *q.Concentration {
+		return false
+	}
+	return true
+}
+
+// Apply returns the spells matching q in q's order.
+func (q SpellQuery) Apply(spells []Spell) []Spell {
+	var matched []Spell
+	for i := range spells {
+		if q.Matches(&spells[i]) {
+			matched = append(matched, spells[i])
+		}
+	}
+	byName := func(i, j int) bool { return strings.ToLower(matched[i].Name) < strings.ToLower(matched[j].Name) }
+	switch q.Sort {
+	case "level":
+		sort.SliceStable(matched, func(i, j int) bool {
+			if matched[i].Level != matched[j].Level {
+				return matched[i].Level < matched[j].Level
+			}
+			return byName(i, j)
+		})
+	case "school":
+		sort.SliceStable(matched, func(i, j int) bool {
+			if matched[i].School != matched[j].School {
+				return matched[i].School < matched[j].School
+			}
+			return byName(i, j)
+		})
+	default:
+		sort.SliceStable(matched, byName)
+	}
+	return matched
+}
+
+// SetLevels sets the levels from a list such as "3", "1-3", "cantrip" or
+// "0,1,2".
+func (q *SpellQuery) SetLevels(text string) error {
+	q.Levels = nil
+	for _, part := range strings.Split(text, ",") {
+		low, high, isRange := strings.Cut(part, "-")
+		if !isRange {
+			high = low
+		}
+		from, err := ParseSpellLevel(low)
+		if err != nil {
+			return err
+		}
+		to, err := ParseSpellLevel(high)
+		if err != nil {
+			return err
+		}
+		if from > to {
+			return fmt.Errorf("invalid spell level range %q", part)
+		}
+		for level := from; level <= to; level++ {
+			q.Levels = append(q.Levels, level)
+		}
+	}
+	return nil
+}
+
+// SetSchool sets the school from its name or the start of it ("evoc").
+func (q *SpellQuery) SetSchool(text string) error {
+	prefix := strings.ToLower(strings.TrimSpace(text))
+	if prefix == "" {
+		return fmt.Errorf("missing school of magic")
+	}
+	for _, school := range SpellSchools {
+		if strings.HasPrefix(strings.ToLower(school), prefix) {
+			q.School = school
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown school of magic %q", text)
+}
+
+// SetSort sets the order, one of SpellSorts.
+func (q *SpellQuery) SetSort(by string) error {
+	by = strings.ToLower(by)
+	for _, s := range SpellSorts {
+		if by == s {
+			q.Sort = by
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot sort spells by %q (use %s)", by, strings.Join(SpellSorts, ", "))
+}
+
+// ParseSpellQuery parses a filter line such as
+// "fire class:wizard level:1-3 school:evocation ritual conc:no". Terms of
+// the form key:value set a condition; "ritual" and "conc" alone mean yes;
+// anything else must appear in the name.
+func ParseSpellQuery(line string) (SpellQuery, error) {
+	var q SpellQuery
+	var words []string
+	for _, term := range strings.Fields(line) {
+		key, value, hasValue := strings.Cut(strings.ToLower(term), ":")
+		var err error
+		switch key {
+		case "class":
+			q.Class = value
+		case "level", "lvl":
+			err = q.SetLevels(value)
+		case "school":
+			err = q.SetSchool(value)
+		case "ritual":
+			q.Ritual, err = parseQueryBool(value, hasValue)
+		case "conc", "concentration":
+			q.Concentration, err = parseQueryBool(value, hasValue)
+		case "sort":
+			err = q.SetSort(value)
+		default:
+			if hasValue {
+				return SpellQuery{}, fmt.Errorf("unknown spell filter %q", key)
+			}
+			words = append(words, term)
+		}
+		if err != nil {
+			return SpellQuery{}, err
+		}
+	}
+	q.Text = strings.Join(words, " ")
+	return q, nil
+}
+
+// parseQueryBool parses the value of a yes/no filter term. A term without
+// a value means yes.
+func parseQueryBool(value string, hasValue bool) (*bool, error) {
+	if !hasValue {
+		yes := true
+		return &yes, nil
+	}
+	var b bool
+	switch value {
+	case "yes", "y", "true":
+		b = true
+	case "no", "n", "false":
+	default:
+		return nil, fmt.Errorf("expected yes or no, got %q", value)
+	}
+	return &b, nil
+}
+
+// MonsterSizes lists the creature sizes from smallest to largest.
+var MonsterSizes = []string{"Tiny", "Small", "Medium", "Large", "Huge", "Gargantuan"}
+
+// MaxChallengeRating is the highest challenge rating.
+const MaxChallengeRating ChallengeRating = 30
+
+// CRRange is an inclusive range of challenge ratings.
+type CRRange struct {
+	Min, Max ChallengeRating
+}
+
+// ParseCRRange parses a rating or a range of ratings: "2", "1/4..2",
+// "..1/2" (up to 1/2) or "5.." (5 and up).
+func ParseCRRange(text string) (CRRange, error) {
+	low, high, isRange := strings.Cut(strings.TrimSpace(text), "..")
+	if !isRange {
+		high = low
+	}
+	r := CRRange{Min: 0, Max: MaxChallengeRating}
+	var err error
+	if low != "" {
+		if r.Min, err = ParseChallengeRating(low); err != nil {
+			return CRRange{}, err
+		}
+	}
+	if high != "" {
+		if r.Max, err = ParseChallengeRating(high); err != nil {
+			return CRRange{}, err
+		}
+	}
+	if !isRange && low == "" || r.Min > r.Max {
+		return CRRange{}, fmt.Errorf("invalid challenge rating range %q", text)
+	}
+	return r, nil
+}
+
+// Contains reports whether cr lies in the range.
+func (r CRRange) Contains(cr ChallengeRating) bool { return cr >= r.Min && cr <= r.Max }
+
+// MonsterQuery selects and orders monsters, for lists and for building
+// encounters. Zero fields match every monster.
+type MonsterQuery struct {
+	Text        string   // words that must all appear in the name
+	CR          *CRRange // challenge ratings to include
+	Type        string   // type or subtype, e.g. "undead" or "goblinoid"
+	Size        string
+	Environment string
+	Sort        string // "cr" (the default), "xp" or "name"
+}
+
+// MonsterSorts lists the orders a MonsterQuery can sort by.
+var MonsterSorts = []string{"cr", "xp", "name"}
+
+// Matches reports whether m satisfies every condition of q.
+func (q MonsterQuery) Matches(m *Monster) bool {
+	name := strings.ToLower(m.Name)
+	for _, word := range strings.Fields(strings.ToLower(q.Text)) {
+		if !strings.Contains(name, word) {
+			return false
+		}
+	}
+	if q.CR != nil && !q.CR.Contains(m.ChallengeRating) {
+		return false
+	}
+	if q.Type != "" && !strings.EqualFold(m.Type, q.Type) && !strings.EqualFold(m.Subtype, q.Type) {
+		return false
+	}
+	if q.Size != "" && !strings.EqualFold(m.Size, q.Size) {
+		return false
+	}
+	if q.Environment != "" && !containsFold(m.Environments, q.Environment) {
+		return false
+	}
+	return true
+}
+
+// Apply returns the monsters matching q in q's order.
+func (q MonsterQuery) Apply(monsters []Monster) []Monster {
+	var matched []Monster
+	for i := range monsters {
+		if q.Matches(&monsters[i]) {
+			matched = append(matched, monsters[i])
+		}
+	}
+	byName := func(i, j int) bool { return strings.ToLower(matched[i].Name) < strings.ToLower(matched[j].Name) }
+	switch q.Sort {
+	case "name":
+		sort.SliceStable(matched, byName)
+	case "xp":
+		sort.SliceStable(matched, func(i, j int) bool {
+			if matched[i].XP != matched[j].XP {
+				return matched[i].XP < matched[j].XP
+			}
+			return byName(i, j)
+		})
+	default:
+		sort.SliceStable(matched, func(i, j int) bool {
+			if matched[i].ChallengeRating != matched[j].ChallengeRating {
+				return matched[i].ChallengeRating < matched[j].ChallengeRating
+			}
+			return byName(i, j)
+		})
+	}
+	return matched
+}
+
+// SetCR sets the challenge ratings from a range such as "1/4..2".
+func (q *MonsterQuery) SetCR(text string) error {
+	r, err := ParseCRRange(text)
+	if err != nil {
+		return err
+	}
+	q.CR = &r
+	return nil
+}
+
+// SetSize sets the size from its name or the start of it ("med").
+func (q *MonsterQuery) SetSize(text string) error {
+	prefix := strings.ToLower(strings.TrimSpace(text))
+	if prefix == "" {
+		return fmt.Errorf("missing size")
+	}
+	for _, size := range MonsterSizes {
+		if strings.HasPrefix(strings.ToLower(size), prefix) {
+			q.Size = size
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown size %q (use %s)", text, strings.Join(MonsterSizes, ", "))
+}
+
+// SetSort sets the order, one of MonsterSorts.
+func (q *MonsterQuery) SetSort(by string) error {
+	by = strings.ToLower(by)
+	for _, s := range MonsterSorts {
+		if by == s {
+			q.Sort = by
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot sort monsters by %q (use %s)", by, strings.Join(MonsterSorts, ", "))
+}
+
+// ParseMonsterQuery parses a filter line such as
+// "cr:1/4..2 type:undead size:medium env:swamp". Terms of the form
+// key:value set a condition; anything else must appear in the name.
+func ParseMonsterQuery(line string) (MonsterQuery, error) {
+	var q MonsterQuery
+	var words []string
+	for _, term := range strings.Fields(line) {
+		key, value, hasValue := strings.Cut(strings.ToLower(term), ":")
+		var err error
+		switch key {
+		case "cr":
+			err = q.SetCR(value)
+		case "type":
+			q.Type = value
+		case "size":
+			err = q.SetSize(value)
+		case "env", "environment":
+			q.Environment = value
+		case "sort":
+			err = q.SetSort(value)
+		default:
+			if hasValue {
+				return MonsterQuery{}, fmt.Errorf("unknown monster filter %q", key)
+			}
+			words = append(words, term)
+		}
+		if err != nil {
+			return MonsterQuery{}, err
+		}
+	}
+	q.Text = strings.Join(words, " ")
+	return q, nil
+}
+
+// Page returns page number page (counting from 1) of items when split into
+// pages of size items, and the number of pages. A size of 0 or less is a
+// single page.
+func Page[T any](items []T, page, size int) ([]T, int) {
+	if size <= 0 {
+		return items, 1
+	}
+	pages := (len(items) + size - 1) / size
+	if pages == 0 {
+		pages = 1
+	}
+	start := (page - 1) * size
+	if page < 1 || start >= len(items) {
+		return nil, pages
+	}
+	end := start + size
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[start:end], pages
+}
+
+// containsFold reports whether list holds s, ignoring case.
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsInt reports whether list holds n.
+func containsInt(list []int, n int) bool {
+	for _, item := range list {
+		if item == n {
+			return true
+		}
+	}
+	return false
+}
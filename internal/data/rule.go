@@ -0,0 +1,30 @@
+package data
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Rule represents the structure of a rules section from rules.json
+type Rule struct {
+	Name        string `json:"name"`
+	Category    string `json:"category,omitempty"` // the chapter it belongs to, e.g. "Combat"
+	Description string `json:"description"`
+	Publisher   string `json:"publisher,omitempty"`
+	Book        string `json:"book,omitempty"`
+	Source      string `json:"-"` // tag of the source it was loaded from
+}
+
+// Card renders the rules section for display.
+func (r *Rule) Card() string {
+	var b strings.Builder
+	writeLine(&b, "Category:", r.Category)
+	if r.Description != "" {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "%s\n", r.Description)
+	}
+	writeSource(&b, r.Book, r.Publisher, r.Source)
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,387 @@
+package data
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// srdFiles is the data built into the binary: a starter set of SRD 5.1
+// content that every category falls back on. Data directories are layered
+// on top of it.
+//
+//go:embed srd/*.json
+var srdFiles embed.FS
+
+// manifestFile describes a data set: srd/manifest.json for the embedded
+// data and, optionally, manifest.json in a data directory or overlay.
+const manifestFile = "manifest.json"
+
+// The tags of the sources that are not homebrew overlays.
+const (
+	SourceSRD  = "srd"  // the data embedded in the binary
+	SourceCore = "core" // the data directory
+)
+
+// IsHomebrew reports whether tag names a homebrew overlay.
+func IsHomebrew(tag string) bool {
+	return tag != "" && tag != SourceSRD && tag != SourceCore
+}
+
+// Manifest names and versions a data set.
+type Manifest struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	License     string `json:"license,omitempty"`
+	Attribution string `json:"attribution,omitempty"`
+}
+
+// EmbeddedManifest describes the data built into the binary.
+func EmbeddedManifest() Manifest {
+	var m Manifest
+	if raw, err := srdFiles.ReadFile("srd/" + manifestFile); err == nil {
+		_ = json.Unmarshal(raw, &m)
+	}
+	return m
+}
+
+// dirManifest reads the manifest of a data directory. A directory without
+// one has an empty manifest.
+func dirManifest(dir string) Manifest {
+	var m Manifest
+	if raw, err := os.ReadFile(filepath.Join(dir, manifestFile)); err == nil {
+		_ = json.Unmarshal(raw, &m)
+	}
+	return m
+}
+
+// Overlay is a directory of homebrew content layered over the core data.
+// It holds a file per category, named like the core files but in JSON or
+// YAML (spells.json, spells.yaml, monsters.yml), and/or a directory per
+// category holding any number of such files (spells/frostball.yaml). A
+// file holds a list of entries or a single entry.
+type Overlay struct {
+	Name     string `json:"name,omitempty"` // source tag; defaults to the directory's name
+	Path     string `json:"path"`
+	Priority int    `json:"priority,omitempty"` // higher overrides lower; ties go to the later overlay
+}
+
+// Tag returns the overlay's source tag.
+func (o Overlay) Tag() string {
+	if o.Name != "" {
+		return o.Name
+	}
+	return filepath.Base(filepath.Clean(o.Path))
+}
+
+// LoadOptions says which sources Store.Load layers.
+type LoadOptions struct {
+	DataDir  string    // the core data directory; "" for the embedded data alone
+	Overlays []Overlay // homebrew overlays
+	Disabled []string  // tags of sources to leave out, e.g. "srd" or an overlay's name
+	CacheDir string    // where to cache the parsed data between runs; "" not to cache it
+}
+
+// layer is one source of data in the order it is applied.
+type layer struct {
+	tag     string
+	path    string // "" for the embedded data
+	version string
+	overlay bool
+}
+
+// layers returns the enabled sources, lowest precedence first: the
+// embedded data, the core data directory, then the overlays by priority.
+func (opts LoadOptions) layers() ([]layer, error) {
+	all := []layer{{tag: SourceSRD, version: EmbeddedManifest().Version}}
+	if opts.DataDir != "" {
+		all = append(all, layer{tag: SourceCore, path: opts.DataDir, version: dirManifest(opts.DataDir).Version})
+	}
+
+	overlays := append([]Overlay(nil), opts.Overlays...)
+	sort.SliceStable(overlays, func(i, j int) bool { return overlays[i].Priority < overlays[j].Priority })
+	known := map[string]bool{SourceSRD: true, SourceCore: true}
+	for _, o := range overlays {
+		tag := o.Tag()
+		if known[strings.ToLower(tag)] {
+			return nil, fmt.Errorf("homebrew overlay %s: source %q is already in use", o.Path, tag)
+		}
+		known[strings.ToLower(tag)] = true
+		path := expandHome(o.Path)
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("homebrew overlay %q: %s is not a directory", tag, path)
+		}
+		all = append(all, layer{tag: tag, path: path, version: dirManifest(path).Version, overlay: true})
+	}
+
+	disabled := make(map[string]bool)
+	for _, tag := range opts.Disabled {
+		if !known[strings.ToLower(tag)] {
+			return nil, fmt.Errorf("cannot disable unknown source %q", tag)
+		}
+		disabled[strings.ToLower(tag)] = true
+	}
+	var enabled []layer
+	for _, l := range all {
+		if !disabled[strings.ToLower(l.tag)] {
+			enabled = append(enabled, l)
+		}
+	}
+	return enabled, nil
+}
+
+// Source is one place a category's entries were read from.
+type Source struct {
+	Tag     string // SourceSRD, SourceCore or an overlay's name
+	Path    string // the directory; "" for the embedded data
+	Version string // from the source's manifest; "" if it has none
+	Entries int    // entries read, including those replacing earlier ones
+}
+
+// CategoryInfo records how one category was loaded.
+type CategoryInfo struct {
+	Kind    Kind
+	File    string
+	Count   int      // entries once every source is layered
+	Sources []Source // lowest precedence first
+}
+
+// entry is implemented by a pointer to each kind of content.
+type entry interface {
+	entryName() string
+	setSource(tag string)
+}
+
+func (s *Spell) entryName() string      { return s.Name }
+func (m *Monster) entryName() string    { return m.Name }
+func (it *Item) entryName() string      { return it.Name }
+func (s *Species) entryName() string    { return s.Name }
+func (b *Background) entryName() string { return b.Name }
+func (c *Class) entryName() string      { return c.Name }
+func (s *Subclass) entryName() string   { return s.Name }
+func (f *Feat) entryName() string       { return f.Name }
+func (c *Condition) entryName() string  { return c.Name }
+func (r *Rule) entryName() string       { return r.Name }
+
+func (s *Spell) setSource(tag string)      { s.Source = tag }
+func (m *Monster) setSource(tag string)    { m.Source = tag }
+func (it *Item) setSource(tag string)      { it.Source = tag }
+func (s *Species) setSource(tag string)    { s.Source = tag }
+func (b *Background) setSource(tag string) { b.Source = tag }
+func (c *Class) setSource(tag string)      { c.Source = tag }
+func (s *Subclass) setSource(tag string)   { s.Source = tag }
+func (f *Feat) setSource(tag string)       { f.Source = tag }
+func (c *Condition) setSource(tag string)  { c.Source = tag }
+func (r *Rule) setSource(tag string)       { r.Source = tag }
+
+// category ties a kind to its data file and the loaded entries.
+type category struct {
+	kind   Kind
+	file   string
+	add    func(raw []byte, tag string) error // decodes an entry and merges it into the loaded ones
+	count  func() int
+	decode func(raw []byte) (entry, error) // decodes a single entry
+	fields map[string]bool                 // the JSON fields an entry may have
+}
+
+// categories lists the data files in Kinds order, with d holding the
+// entries loaded from them.
+func (d *dataset) categories() []category {
+	return []category{
+		newCategory(KindSpell, "spells.json", &d.spells),
+		newCategory(KindMonster, "monsters.json", &d.monsters),
+		newCategory(KindItem, "items.json", &d.items),
+		newCategory(KindSpecies, "species.json", &d.species),
+		newCategory(KindBackground, "backgrounds.json", &d.backgrounds),
+		newCategory(KindClass, "classes.json", &d.classes),
+		newCategory(KindSubclass, "subclasses.json", &d.subclasses),
+		newCategory(KindFeat, "feats.json", &d.feats),
+		newCategory(KindCondition, "conditions.json", &d.conditions),
+		newCategory(KindRule, "rules.json", &d.rules),
+	}
+}
+
+// categories lists the data files in Kinds order, for working with the
+// files rather than loading them.
+func categories() []category {
+	return new(dataset).categories()
+}
+
+// newCategory builds the category whose entries are held in all. An
+// added entry is tagged with its source and replaces the loaded entry of
+// the same name, ignoring case and punctuation, or is appended otherwise.
+func newCategory[T any, P interface {
+	*T
+	entry
+}](kind Kind, file string, all *[]T) category {
+	at := make(map[string]int) // normalized name -> position in all
+	return category{
+		kind: kind,
+		file: file,
+		add: func(raw []byte, tag string) error {
+			var e T
+			if err := json.Unmarshal(raw, &e); err != nil {
+				return err
+			}
+			P(&e).setSource(tag)
+			key := normalizeName(P(&e).entryName())
+			if i, ok := at[key]; ok {
+				(*all)[i] = e
+				return nil
+			}
+			at[key] = len(*all)
+			*all = append(*all, e)
+			return nil
+		},
+		count: func() int { return len(*all) },
+		decode: func(raw []byte) (entry, error) {
+			e := P(new(T))
+			err := json.Unmarshal(raw, e)
+			return e, err
+		},
+		fields: jsonFields(reflect.TypeOf((*T)(nil)).Elem()),
+	}
+}
+
+// label names the category's data in messages, e.g. "spells".
+func (c category) label() string { return strings.TrimSuffix(c.file, ".json") }
+
+// load merges the category's entries from l. ok is false if l has none.
+// An entry that does not decode is left out and reported as a problem
+// with its file and line; a file that can't be read or parsed at all is
+// an error.
+func (c category) load(l layer) (source Source, ok bool, problems []Problem, err error) {
+	source = Source{Tag: l.tag, Path: l.path, Version: l.version}
+	for _, path := range c.files(l) {
+		raw, err := l.readFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return source, false, nil, fmt.Errorf("failed to load %s data: failed to open file %s: %w", c.label(), path, err)
+		}
+		entries, err := splitEntries(path, raw)
+		if err != nil {
+			var perr *parseError
+			if errors.As(err, &perr) && perr.line > 0 {
+				path = fmt.Sprintf("%s:%d", path, perr.line)
+			}
+			return source, false, nil, fmt.Errorf("failed to load %s data: failed to parse file %s: %w", c.label(), path, err)
+		}
+		for _, e := range entries {
+			if err := c.addEntry(e, l.tag); err != nil {
+				problems = append(problems, c.decodeProblem(path, e, err))
+				continue
+			}
+			source.Entries++
+		}
+		ok = true
+	}
+	return source, ok, problems, nil
+}
+
+// addEntry decodes an entry of a content file and merges it into the
+// loaded ones.
+func (c category) addEntry(e rawEntry, tag string) error {
+	if trimmed := bytes.TrimSpace(e.raw); len(trimmed) == 0 || trimmed[0] != '{' {
+		return errNotObject
+	}
+	return c.add(e.raw, tag)
+}
+
+// errNotObject is the problem with an entry that is not a JSON or YAML
+// object.
+var errNotObject = errors.New("an entry must be an object")
+
+// decodeProblem reports an entry of the file at path that does not
+// decode, naming the entry if it has a name.
+func (c category) decodeProblem(path string, e rawEntry, err error) Problem {
+	var head struct {
+		Name string `json:"name"`
+	}
+	_ = json.Unmarshal(e.raw, &head)
+	msg := strings.TrimPrefix(err.Error(), fmt.Sprintf("%s %q: ", c.kind, head.Name))
+	return Problem{Severity: Error, File: path, Line: e.line, Entry: head.Name, Message: msg}
+}
+
+// files returns the files of l that may hold entries of the category.
+// Files of the embedded data are named like srd/spells.json.
+func (c category) files(l layer) []string {
+	switch {
+	case l.path == "":
+		return []string{"srd/" + c.file}
+	case l.overlay:
+		return c.overlayFiles(l.path)
+	}
+	return []string{filepath.Join(l.path, c.file)}
+}
+
+// readFile reads one of the files of l.
+func (l layer) readFile(path string) ([]byte, error) {
+	if l.path == "" {
+		return srdFiles.ReadFile(path)
+	}
+	return os.ReadFile(path)
+}
+
+// contentExts are the extensions of overlay files, in the order they load.
+var contentExts = []string{".json", ".yaml", ".yml"}
+
+// overlayFiles returns the overlay files in dir holding entries of the
+// category: spells.json, spells.yaml and spells.yml, then the files in
+// spells/ in name order.
+func (c category) overlayFiles(dir string) []string {
+	var files []string
+	for _, ext := range contentExts {
+		files = append(files, filepath.Join(dir, c.label()+ext))
+	}
+	entries, _ := os.ReadDir(filepath.Join(dir, c.label()))
+	for _, e := range entries {
+		if !e.IsDir() && hasContentExt(e.Name()) {
+			files = append(files, filepath.Join(dir, c.label(), e.Name()))
+		}
+	}
+	return files
+}
+
+// hasContentExt reports whether name is a JSON or YAML file.
+func hasContentExt(name string) bool {
+	return containsFold(contentExts, filepath.Ext(name))
+}
+
+// isYAML reports whether path is a YAML file.
+func isYAML(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// jsonValue makes a decoded YAML value encodable as JSON. YAML decodes a
+// map whose keys are not all strings, such as {1: 2}, with interface{}
+// keys; they are turned into strings.
+func jsonValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[fmt.Sprint(key)] = jsonValue(value)
+		}
+		return m
+	case map[string]interface{}:
+		for key, value := range v {
+			v[key] = jsonValue(value)
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = jsonValue(value)
+		}
+	}
+	return v
+}
@@ -0,0 +1,320 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Spell is a spell from spells.json.
+type Spell struct {
+	Name          string     `json:"name"`
+	Description   string     `json:"description"`
+	Level         int        `json:"level"` // 0 for cantrips
+	School        string     `json:"school"`
+	CastingTime   string     `json:"casting_time"`
+	Range         string     `json:"range"`
+	Components    Components `json:"components"`
+	Duration      string     `json:"duration"`
+	Concentration bool       `json:"concentration"`
+	Ritual        bool       `json:"ritual"`
+	Classes       []string   `json:"classes"`
+	HigherLevels  string     `json:"higher_levels"`
+	Publisher     string     `json:"publisher"`
+	Book          string     `json:"book"`
+	Source        string     `json:"-"` // tag of the source it was loaded from
+}
+
+// Components are the verbal, somatic and material components of a spell.
+type Components struct {
+	Verbal   bool   `json:"verbal"`
+	Somatic  bool   `json:"somatic"`
+	Material bool   `json:"material"`
+	Text     string `json:"material_text"` // what the material component is
+}
+
+// SpellSchools lists the schools of magic.
+var SpellSchools = []string{"Abjuration", "Conjuration", "Divination", "Enchantment", "Evocation", "Illusion", "Necromancy", "Transmutation"}
+
+// ParseComponents parses a components line such as "V, S, M (a pinch of soot)".
+func ParseComponents(text string) (Components, error) {
+	letters, material := text, ""
+	if i := strings.Index(text, "("); i >= 0 {
+		letters = text[:i]
+		material = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(text[i+1:]), ")"))
+	}
+	c := Components{Text: material}
+	for _, letter := range strings.Split(letters, ",") {
+		switch strings.ToUpper(strings.TrimSpace(letter)) {
+		case "V":
+			c.Verbal = true
+		case "S":
+			c.Somatic = true
+		case "M":
+			c.Material = true
+		default:
+			return Components{}, fmt.Errorf("invalid components %q", text)
+		}
+	}
+	return c, nil
+}
+
+// String renders the components as "V, S, M (a pinch of soot)".
+func (c Components) String() string {
+	var parts []string
+	if c.Verbal {
+		parts = append(parts, "V")
+	}
+	if c.Somatic {
+		parts = append(parts, "S")
+	}
+	if c.Material {
+		parts = append(parts, "M")
+	}
+	s := strings.Join(parts, ", ")
+	if c.Material && c.Text != "" {
+		s += " (" + c.Text + ")"
+	}
+	return s
+}
+
+// UnmarshalJSON accepts either a components line or an object.
+func (c *Components) UnmarshalJSON(b []byte) error {
+	var text string
+	if err := json.Unmarshal(b, &text); err == nil {
+		if text == "" {
+			*c = Components{}
+			return nil
+		}
+		parsed, err := ParseComponents(text)
+		*c = parsed
+		return err
+	}
+	type plain Components
+	return json.Unmarshal(b, (*plain)(c))
+}
+
+// ParseSpellLevel parses a spell level such as 3, "3", "3rd", "3rd-level" or
+// "Cantrip".
+func ParseSpellLevel(text string) (int, error) {
+	t := strings.ToLower(strings.TrimSpace(text))
+	if t == "cantrip" || t == "0" {
+		return 0, nil
+	}
+	t = strings.TrimSuffix(strings.TrimSuffix(t, " level"), "-level")
+	for _, suffix := range []string{"st", "nd", "rd", "th"} {
+		t = strings.TrimSuffix(t, suffix)
+	}
+	level, err := strconv.Atoi(t)
+	if err != nil || level < 0 || level > 9 {
+		return 0, fmt.Errorf("invalid spell level %q", text)
+	}
+	return level, nil
+}
+
+// normalizeSchool returns the canonical name of a school of magic.
+func normalizeSchool(school string) (string, error) {
+	for _, s := range SpellSchools {
+		if strings.EqualFold(s, strings.TrimSpace(school)) {
+			return s, nil
+		}
+	}
+	return "", fmt.Errorf("unknown school of magic %q", school)
+}
+
+// UnmarshalJSON decodes and validates a spell. Besides the typed fields,
+// entries may carry a "properties" map keyed by printed names ("Level",
+// "School", "Casting Time", "Components", "Classes", ...) which fills in
+// any typed fields left empty. An invalid spell is an error naming it; the
+// loader leaves it out and reports it with its file and line.
+func (s *Spell) UnmarshalJSON(b []byte) error {
+	type plain Spell
+	var raw struct {
+		plain
+		Level      json.RawMessage        `json:"level"`
+		Properties map[string]interface{} `json:"properties"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	*s = Spell(raw.plain)
+	if err := s.decode(raw.Level, raw.Properties); err != nil {
+		return fmt.Errorf("spell %q: %w", s.Name, err)
+	}
+	return nil
+}
+
+// decode fills and validates the fields that need more than plain decoding.
+func (s *Spell) decode(level json.RawMessage, props map[string]interface{}) error {
+	if s.Name == "" {
+		return fmt.Errorf("missing name")
+	}
+	if len(level) > 0 {
+		var text string
+		if err := json.Unmarshal(level, &text); err != nil {
+			text = string(level)
+		}
+		var err error
+		if s.Level, err = ParseSpellLevel(text); err != nil {
+			return err
+		}
+	}
+
+	material := ""
+	for key, value := range props {
+		text := strings.TrimSpace(fmt.Sprint(value))
+		var err error
+		switch strings.ToLower(key) {
+		case "level":
+			if len(level) == 0 {
+				s.Level, err = ParseSpellLevel(text)
+			}
+		case "school":
+			if s.School == "" {
+				s.School = text
+			}
+		case "casting time":
+			if s.CastingTime == "" {
+				s.CastingTime = text
+			}
+		case "range":
+			if s.Range == "" {
+				s.Range = text
+			}
+		case "components":
+			if s.Components == (Components{}) {
+				s.Components, err = ParseComponents(text)
+			}
+		case "material":
+			material = text
+		case "duration":
+			if s.Duration == "" {
+				s.Duration = text
+			}
+		case "concentration":
+			s.Concentration = s.Concentration || isYes(value)
+		case "ritual":
+			s.Ritual = s.Ritual || isYes(value)
+		case "classes", "class":
+			if s.Classes == nil {
+				s.Classes = splitList(value)
+			}
+		case "higher levels", "at higher levels", "higher level":
+			if s.HigherLevels == "" {
+				s.HigherLevels = text
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if material != "" && s.Components.Text == "" {
+		s.Components.Text = material
+	}
+	if s.School != "" {
+		school, err := normalizeSchool(s.School)
+		if err != nil {
+			return err
+		}
+		s.School = school
+	}
+	if strings.HasPrefix(strings.ToLower(s.Duration), "concentration") {
+		s.Concentration = true
+	}
+	if strings.Contains(strings.ToLower(s.CastingTime), "ritual") {
+		s.Ritual = true
+	}
+	return nil
+}
+
+// isYes reports whether a property value means true ("yes", true, ...).
+func isYes(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "yes", "true", "y":
+			return true
+		}
+	}
+	return false
+}
+
+// splitList returns a property value as a list, splitting strings on commas.
+func splitList(value interface{}) []string {
+	var items []string
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			items = append(items, strings.TrimSpace(fmt.Sprint(item)))
+		}
+	case string:
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+
+// LevelSchool renders e.g. "3rd-level evocation (ritual)" or "Evocation cantrip".
+func (s *Spell) LevelSchool() string {
+	school := s.School
+	if school == "" {
+		school = "spell"
+	}
+	var line string
+	if s.Level == 0 {
+		line = school + " cantrip"
+	} else {
+		line = fmt.Sprintf("%s-level %s", Ordinal(s.Level), strings.ToLower(school))
+	}
+	if s.Ritual {
+		line += " (ritual)"
+	}
+	return line
+}
+
+// Ordinal renders 1 as "1st", 2 as "2nd" and so on.
+func Ordinal(n int) string {
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+	return strconv.Itoa(n) + suffix
+}
+
+// Card renders the spell as a plain-text spell card with its fields in a
+// fixed order.
+func (s *Spell) Card() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", s.LevelSchool())
+	writeLine(&b, "Casting Time:", s.CastingTime)
+	writeLine(&b, "Range:", s.Range)
+	writeLine(&b, "Components:", s.Components.String())
+	duration := s.Duration
+	if s.Concentration && !strings.HasPrefix(strings.ToLower(duration), "concentration") {
+		duration = strings.TrimSpace("Concentration, " + duration)
+	}
+	writeLine(&b, "Duration:", duration)
+	writeLine(&b, "Classes:", strings.Join(s.Classes, ", "))
+
+	if s.Description != "" {
+		fmt.Fprintf(&b, "\n%s\n", s.Description)
+	}
+	if s.HigherLevels != "" {
+		fmt.Fprintf(&b, "\nAt Higher Levels. %s\n", s.HigherLevels)
+	}
+	writeSource(&b, s.Book, s.Publisher, s.Source)
+	return strings.TrimSpace(b.String())
+}
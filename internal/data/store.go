@@ -0,0 +1,289 @@
+package data
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Store holds a loaded data set: the entries of every category, where they
+// came from and the indexes for looking them up and searching them. Each
+// Store is independent, so a program may load several, such as the core
+// data alone and with homebrew, or test fixtures.
+//
+// The Get*ByName methods find an entry by its name or an alias, such as
+// "Tiny Hut" for "Leomund's Tiny Hut". They ignore case, punctuation and
+// plurals, and the spaces between words, so "Goblins", "fire ball" and
+// "tashas hideous laughter" all find their entries. A miss returns a
+// *NotFoundError with suggestions.
+//
+// A Store is safe for concurrent use. Load builds the new data set aside
+// and swaps it in, so readers see either the old data or the new, never a
+// mix. The slices its methods return, and the entries the Get*ByName
+// methods return, are shallow copies: the slices and maps inside each entry
+// (a class's Spells, a monster's Actions, ...) are shared with the store
+// and every other caller, so callers must treat them as read-only.
+type Store struct {
+	mu   sync.RWMutex
+	data *dataset
+}
+
+// dataset is one load of the data. It is not changed once it is built.
+type dataset struct {
+	spells      []Spell
+	monsters    []Monster
+	items       []Item
+	species     []Species
+	backgrounds []Background
+	classes     []Class
+	subclasses  []Subclass
+	feats       []Feat
+	conditions  []Condition
+	rules       []Rule
+
+	loaded   []CategoryInfo
+	problems []Problem // entries left out because they don't decode
+	stats    LoadStats
+
+	spellIndex      nameIndex
+	monsterIndex    nameIndex
+	itemIndex       nameIndex
+	speciesIndex    nameIndex
+	backgroundIndex nameIndex
+	classIndex      nameIndex
+	subclassIndex   nameIndex
+	featIndex       nameIndex
+	conditionIndex  nameIndex
+	ruleIndex       nameIndex
+	fullText        *textIndex
+}
+
+// NewStore returns an empty store. Load fills it.
+func NewStore() *Store {
+	return &Store{data: &dataset{}}
+}
+
+// current returns the data set the store holds.
+func (s *Store) current() *dataset {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.data
+}
+
+// Load loads the D&D data into the store, replacing what it held. Every
+// category starts from the data embedded in the binary; the core data
+// directory and then each homebrew overlay are layered on top, in order
+// of precedence. An entry replaces the entry of the same name from the
+// layers below it and any other entries are added. Disabled sources are
+// left out. Loaded records where each category came from. An entry that
+// does not decode is left out too and reported by Problems.
+//
+// With a CacheDir, the parsed and indexed data is kept there between
+// runs and read back as long as none of the source files has changed.
+// Stats records whether the cache was used and how long each step took.
+// If Load fails, the store keeps the data it held.
+func (s *Store) Load(opts LoadOptions) error {
+	start := time.Now()
+	d, err := load(opts)
+	if err != nil {
+		return err
+	}
+	d.stats.Total = time.Since(start)
+	s.mu.Lock()
+	s.data = d
+	s.mu.Unlock()
+	return nil
+}
+
+// load builds a data set for opts, from the cache if it is up to date.
+func load(opts LoadOptions) (*dataset, error) {
+	layers, err := opts.layers()
+	if err != nil {
+		return nil, err
+	}
+	stats := LoadStats{Cache: CacheOff}
+
+	var key string
+	if opts.CacheDir != "" {
+		t := time.Now()
+		key = cacheKey(layers)
+		stats.Check = time.Since(t)
+		stats.CachePath = filepath.Join(opts.CacheDir, cacheFile(layers))
+		t = time.Now()
+		d, err := readCache(stats.CachePath, key)
+		stats.Read = time.Since(t)
+		if err == nil {
+			stats.Cache = CacheHit
+			d.stats = stats
+			return d, nil
+		}
+		stats.Cache = CacheMiss
+		if !errors.Is(err, fs.ErrNotExist) && !errors.Is(err, errStaleCache) {
+			stats.CacheErr = err
+		}
+	}
+
+	t := time.Now()
+	d := &dataset{}
+	for _, c := range d.categories() {
+		info := CategoryInfo{Kind: c.kind, File: c.file}
+		for _, l := range layers {
+			source, ok, problems, err := c.load(l)
+			if err != nil {
+				return nil, err
+			}
+			d.problems = append(d.problems, problems...)
+			if ok {
+				info.Sources = append(info.Sources, source)
+			}
+		}
+		info.Count = c.count()
+		d.loaded = append(d.loaded, info)
+	}
+	stats.Parse = time.Since(t)
+
+	t = time.Now()
+	d.buildIndexes()
+	stats.Index = time.Since(t)
+
+	if key != "" {
+		t = time.Now()
+		if err := writeCache(stats.CachePath, key, d); err != nil && stats.CacheErr == nil {
+			stats.CacheErr = err
+		}
+		stats.Write = time.Since(t)
+	}
+	d.stats = stats
+	return d, nil
+}
+
+// Loaded describes each category, in Kinds order, as of the last Load.
+func (s *Store) Loaded() []CategoryInfo { return slices.Clone(s.current().loaded) }
+
+// Problems returns the entries the last Load left out because they don't
+// decode, with the file and line of each.
+func (s *Store) Problems() []Problem { return slices.Clone(s.current().problems) }
+
+// Stats returns the stats of the last Load.
+func (s *Store) Stats() LoadStats { return s.current().stats }
+
+// Spells returns every loaded spell.
+func (s *Store) Spells() []Spell { return slices.Clone(s.current().spells) }
+
+// Monsters returns every loaded monster.
+func (s *Store) Monsters() []Monster { return slices.Clone(s.current().monsters) }
+
+// Items returns every loaded item.
+func (s *Store) Items() []Item { return slices.Clone(s.current().items) }
+
+// Species returns every loaded species.
+func (s *Store) Species() []Species { return slices.Clone(s.current().species) }
+
+// Backgrounds returns every loaded background.
+func (s *Store) Backgrounds() []Background { return slices.Clone(s.current().backgrounds) }
+
+// Classes returns every loaded class.
+func (s *Store) Classes() []Class { return slices.Clone(s.current().classes) }
+
+// Subclasses returns every loaded subclass.
+func (s *Store) Subclasses() []Subclass { return slices.Clone(s.current().subclasses) }
+
+// Feats returns every loaded feat.
+func (s *Store) Feats() []Feat { return slices.Clone(s.current().feats) }
+
+// Conditions returns every loaded condition.
+func (s *Store) Conditions() []Condition { return slices.Clone(s.current().conditions) }
+
+// Rules returns every loaded rules section.
+func (s *Store) Rules() []Rule { return slices.Clone(s.current().rules) }
+
+// FindSpells returns the loaded spells matching q in q's order.
+func (s *Store) FindSpells(q SpellQuery) []Spell { return q.Apply(s.current().spells) }
+
+// FindMonsters returns the loaded monsters matching q in q's order.
+func (s *Store) FindMonsters(q MonsterQuery) []Monster { return q.Apply(s.current().monsters) }
+
+// GetSpellByName looks up a spell by its name or an alias.
+func (s *Store) GetSpellByName(name string) (*Spell, error) {
+	d := s.current()
+	return lookupByName(KindSpell, &d.spellIndex, d.spells, name)
+}
+
+// GetMonsterByName looks up a monster by its name or an alias.
+func (s *Store) GetMonsterByName(name string) (*Monster, error) {
+	d := s.current()
+	return lookupByName(KindMonster, &d.monsterIndex, d.monsters, name)
+}
+
+// GetItemByName looks up an item by its name or an alias.
+func (s *Store) GetItemByName(name string) (*Item, error) {
+	d := s.current()
+	return lookupByName(KindItem, &d.itemIndex, d.items, name)
+}
+
+// GetSpeciesByName looks up a species by its name or an alias.
+func (s *Store) GetSpeciesByName(name string) (*Species, error) {
+	d := s.current()
+	return lookupByName(KindSpecies, &d.speciesIndex, d.species, name)
+}
+
+// GetBackgroundByName looks up a background by its name or an alias.
+func (s *Store) GetBackgroundByName(name string) (*Background, error) {
+	d := s.current()
+	return lookupByName(KindBackground, &d.backgroundIndex, d.backgrounds, name)
+}
+
+// GetClassByName looks up a class by its name or an alias.
+func (s *Store) GetClassByName(name string) (*Class, error) {
+	d := s.current()
+	return lookupByName(KindClass, &d.classIndex, d.classes, name)
+}
+
+// GetSubclassByName looks up a subclass by its name or an alias.
+func (s *Store) GetSubclassByName(name string) (*Subclass, error) {
+	d := s.current()
+	return lookupByName(KindSubclass, &d.subclassIndex, d.subclasses, name)
+}
+
+// GetFeatByName looks up a feat by its name or an alias.
+func (s *Store) GetFeatByName(name string) (*Feat, error) {
+	d := s.current()
+	return lookupByName(KindFeat, &d.featIndex, d.feats, name)
+}
+
+// GetConditionByName looks up a condition by its name or an alias.
+func (s *Store) GetConditionByName(name string) (*Condition, error) {
+	d := s.current()
+	return lookupByName(KindCondition, &d.conditionIndex, d.conditions, name)
+}
+
+// GetRuleByName looks up a rules section by its name or an alias.
+func (s *Store) GetRuleByName(name string) (*Rule, error) {
+	d := s.current()
+	return lookupByName(KindRule, &d.ruleIndex, d.rules, name)
+}
+
+// SubclassesOf returns the subclasses of the named class.
+func (s *Store) SubclassesOf(class string) []Subclass {
+	var out []Subclass
+	for _, sub := range s.current().subclasses {
+		if strings.EqualFold(sub.Class, class) {
+			out = append(out, sub)
+		}
+	}
+	return out
+}
+
+// Search looks for the words of query in the names and text of all loaded
+// content and returns the entries containing all of them, best first.
+func (s *Store) Search(query string) []Hit {
+	d := s.current()
+	if d.fullText == nil {
+		return nil
+	}
+	return d.fullText.search(query)
+}
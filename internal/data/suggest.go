@@ -0,0 +1,179 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Suggestion limits.
+const (
+	MaxSuggestions = 3   // suggestions kept on a failed lookup
+	minSimilarity  = 0.6 // how alike a name must be to be suggested on spelling alone
+)
+
+// NotFoundError is returned by the GetXByName lookups when no entry has the
+// name. It carries the closest names, best first.
+type NotFoundError struct {
+	Kind        Kind
+	Name        string
+	Suggestions []string
+	closest     string // the only very close name, if there is exactly one
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%s '%s' not found", e.Kind, e.Name)
+}
+
+// DidYouMean renders the suggestions as "Did you mean Fireball or Fire
+// Bolt?", or "" if there are none.
+func (e *NotFoundError) DidYouMean() string {
+	switch n := len(e.Suggestions); n {
+	case 0:
+		return ""
+	case 1:
+		return fmt.Sprintf("Did you mean %s?", e.Suggestions[0])
+	default:
+		return fmt.Sprintf("Did you mean %s or %s?", strings.Join(e.Suggestions[:n-1], ", "), e.Suggestions[n-1])
+	}
+}
+
+// Closest returns the suggestion to use in place of the missing name when
+// exactly one name is within a typo or two of it.
+func (e *NotFoundError) Closest() (string, bool) {
+	return e.closest, e.closest != ""
+}
+
+// ClosestMatch returns the name to use in place of a missing one when err
+// is a *NotFoundError whose name is within a typo or two of exactly one
+// name.
+func ClosestMatch(err error) (string, bool) {
+	var notFound *NotFoundError
+	if !errors.As(err, &notFound) {
+		return "", false
+	}
+	return notFound.Closest()
+}
+
+// suggestion is a candidate name with how well it matches.
+type suggestion struct {
+	name     string
+	score    float64
+	distance int
+}
+
+// notFound builds the error for a failed lookup of name in index.
+func notFound(kind Kind, index *nameIndex, name string) *NotFoundError {
+	err := &NotFoundError{Kind: kind, Name: name}
+	query := normalizeName(name)
+	if query == "" {
+		return err
+	}
+
+	best := make(map[int]suggestion) // position -> best match among its aliases
+	for key, i := range index.exact {
+		s := suggestion{name: index.names[i], distance: editDistance(query, key)}
+		s.score = nameSimilarity(query, key, s.distance)
+		if prev, ok := best[i]; !ok || s.score > prev.score {
+			best[i] = s
+		}
+	}
+	var ranked []suggestion
+	for _, s := range best {
+		if s.score > 0 {
+			ranked = append(ranked, s)
+		}
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+		return ranked[i].name < ranked[j].name
+	})
+
+	nearby := 0
+	for _, s := range ranked {
+		if s.distance <= closeDistance(query) {
+			nearby++
+			err.closest = s.name
+		}
+	}
+	if nearby != 1 {
+		err.closest = ""
+	}
+	for i := 0; i < len(ranked) && i < MaxSuggestions; i++ {
+		err.Suggestions = append(err.Suggestions, ranked[i].name)
+	}
+	return err
+}
+
+// closeDistance is the most edits a name may be from the query to count
+// as a typo of it.
+func closeDistance(query string) int {
+	if len([]rune(query)) < 5 {
+		return 1
+	}
+	return 2
+}
+
+// nameSimilarity scores how well key matches query, or 0 if it doesn't.
+// Spelling similarity counts fully; a shared prefix and shared words add
+// to it, so "fire" suggests "Fireball" and "tiny" suggests "Leomund's Tiny
+// Hut" even though they are spelled very differently.
+func nameSimilarity(query, key string, distance int) float64 {
+	longest := len([]rune(query))
+	if n := len([]rune(key)); n > longest {
+		longest = n
+	}
+	spelling := 1 - float64(distance)/float64(longest)
+
+	prefix := 0.0
+	if strings.HasPrefix(key, query) || strings.HasPrefix(query, key) {
+		prefix = 0.5
+	}
+
+	queryWords, keyWords := strings.Fields(query), strings.Fields(key)
+	shared := 0
+	for _, q := range queryWords {
+		for _, k := range keyWords {
+			if strings.HasPrefix(k, q) || editDistance(q, k) <= closeDistance(q)-1 {
+				shared++
+				break
+			}
+		}
+	}
+	words := 0.5 * float64(shared) / float64(len(queryWords))
+
+	if spelling < minSimilarity && prefix == 0 && shared == 0 {
+		return 0
+	}
+	return spelling + prefix + words
+}
+
+// editDistance returns the number of single-character insertions,
+// deletions, substitutions and adjacent swaps that turn a into b.
+func editDistance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	rows := make([][]int, len(s)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(t)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(s)][len(t)]
+}
@@ -0,0 +1,442 @@
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Severity says how bad a Problem is.
+type Severity int
+
+// Severities, least severe first.
+const (
+	Warning Severity = iota // worth a look, but the data loads as intended
+	Error                   // the data is wrong or won't load
+)
+
+func (s Severity) String() string {
+	if s == Error {
+		return "error"
+	}
+	return "warning"
+}
+
+// Problem is one thing wrong with a content file.
+type Problem struct {
+	Severity Severity
+	File     string
+	Line     int    // 0 if not known
+	Entry    string // the entry's name; "" for problems with the whole file
+	Message  string
+}
+
+// String renders the problem as "file:line: severity: entry: message".
+func (p Problem) String() string {
+	loc := p.File
+	if p.Line > 0 {
+		loc += fmt.Sprintf(":%d", p.Line)
+	}
+	msg := p.Message
+	if p.Entry != "" {
+		msg = p.Entry + ": " + msg
+	}
+	return fmt.Sprintf("%s: %s: %s", loc, p.Severity, msg)
+}
+
+// Report is the result of Validate.
+type Report struct {
+	Files    int // content files checked
+	Entries  int // entries checked
+	Problems []Problem
+}
+
+// Count returns how many problems have the given severity.
+func (r *Report) Count(severity Severity) int {
+	n := 0
+	for _, p := range r.Problems {
+		if p.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+func (r *Report) add(severity Severity, file string, line int, entry, format string, args ...interface{}) {
+	r.Problems = append(r.Problems, Problem{severity, file, line, entry, fmt.Sprintf(format, args...)})
+}
+
+// origin is where an entry was defined.
+type origin struct {
+	tag  string
+	file string
+	line int
+}
+
+func (o origin) String() string { return fmt.Sprintf("%s:%d", o.file, o.line) }
+
+// reference is a name one entry uses to refer to another.
+type reference struct {
+	kind  Kind
+	name  string
+	field string
+	from  origin
+	entry string
+}
+
+// Validate checks every content file of the sources Store.Load would
+// layer for opts, without loading them. It reports files that don't parse,
+// entries that don't decode, missing required fields, unknown fields,
+// names defined twice in one source (an error) or overridden by a
+// homebrew overlay (a warning; the core data replacing the embedded data
+// is expected), and spells, classes and conditions named by other entries
+// that no source defines. It returns an error only if opts names a missing
+// overlay or an unknown source.
+func Validate(opts LoadOptions) (*Report, error) {
+	layers, err := opts.layers()
+	if err != nil {
+		return nil, err
+	}
+	r := &Report{}
+	defined := make(map[Kind]map[string]origin)
+	var refs []reference
+	for _, l := range layers {
+		for _, c := range categories() {
+			if defined[c.kind] == nil {
+				defined[c.kind] = make(map[string]origin)
+			}
+			for _, path := range c.files(l) {
+				raw, err := l.readFile(path)
+				if errors.Is(err, fs.ErrNotExist) {
+					continue
+				}
+				r.Files++
+				if err != nil {
+					r.add(Error, path, 0, "", "%v", err)
+					continue
+				}
+				entries, err := splitEntries(path, raw)
+				if err != nil {
+					var perr *parseError
+					if errors.As(err, &perr) {
+						r.add(Error, path, perr.line, "", "%v", perr.err)
+					} else {
+						r.add(Error, path, 0, "", "%v", err)
+					}
+					continue
+				}
+				for _, e := range entries {
+					r.Entries++
+					refs = append(refs, r.checkEntry(c, l, path, e, defined[c.kind])...)
+				}
+			}
+		}
+		if l.overlay {
+			r.checkOverlayFiles(l.path)
+		}
+	}
+
+	for _, ref := range refs {
+		if _, ok := defined[ref.kind][normalizeName(ref.name)]; !ok {
+			r.add(Error, ref.from.file, ref.from.line, ref.entry, "%s names unknown %s %q", ref.field, ref.kind, ref.name)
+		}
+	}
+	return r, nil
+}
+
+// ValidateDir checks a directory of content files on its own terms. A
+// complete data directory, one holding the data file of every category, is
+// checked alone as the core data, so that its entries neither override nor
+// lean on the embedded data; any other directory is checked as a homebrew
+// overlay over the embedded data.
+func ValidateDir(dir string) (*Report, error) {
+	dir = expandHome(dir)
+	if isCompleteDataDir(dir) {
+		return Validate(LoadOptions{DataDir: dir, Disabled: []string{SourceSRD}})
+	}
+	return Validate(LoadOptions{Overlays: []Overlay{{Path: dir}}})
+}
+
+// checkEntry checks one entry of a content file and records its name in
+// defined. It returns the references the entry makes.
+func (r *Report) checkEntry(c category, l layer, path string, e rawEntry, defined map[string]origin) []reference {
+	var head struct {
+		Name string `json:"name"`
+	}
+	_ = json.Unmarshal(e.raw, &head)
+	here := origin{l.tag, path, e.line}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(e.raw, &fields); err != nil {
+		r.add(Error, path, e.line, head.Name, "%v", errNotObject)
+		return nil
+	}
+	v, err := c.decode(e.raw)
+	if err != nil {
+		r.Problems = append(r.Problems, c.decodeProblem(path, e, err))
+		return nil
+	}
+	name := v.entryName()
+	if name == "" {
+		r.add(Error, path, e.line, "", "name is required")
+		return nil
+	}
+
+	var unknown []string
+	for field := range fields {
+		if !c.fields[field] {
+			unknown = append(unknown, field)
+		}
+	}
+	sort.Strings(unknown)
+	for _, field := range unknown {
+		r.add(Warning, path, e.line, name, "unknown field %q", field)
+	}
+	for _, field := range missingFields(v) {
+		r.add(Error, path, e.line, name, "%s is required", field)
+	}
+
+	key := normalizeName(name)
+	if prev, ok := defined[key]; ok {
+		switch {
+		case prev.tag == l.tag:
+			r.add(Error, path, e.line, name, "duplicate %s; also defined at %s", c.kind, prev)
+		case l.overlay:
+			r.add(Warning, path, e.line, name, "overrides the %s from %s (%s)", c.kind, prev.tag, prev)
+		}
+	}
+	defined[key] = here
+
+	refs := references(v)
+	for i := range refs {
+		refs[i].from, refs[i].entry = here, name
+	}
+	return refs
+}
+
+// missingFields returns the JSON names of the required fields an entry
+// lacks once decoded.
+func missingFields(e entry) []string {
+	var missing []string
+	require := func(field string, ok bool) {
+		if !ok {
+			missing = append(missing, field)
+		}
+	}
+	switch v := e.(type) {
+	case *Spell:
+		require("school", v.School != "")
+		require("casting_time", v.CastingTime != "")
+		require("range", v.Range != "")
+		require("duration", v.Duration != "")
+		require("description", v.Description != "")
+	case *Monster:
+		require("size", v.Size != "")
+		require("type", v.Type != "")
+		require("armor_class", v.ArmorClass > 0)
+		require("hit_points", v.HitPoints > 0)
+	case *Item:
+		require("category", v.Category != "")
+	case *Species:
+		require("description", v.Description != "")
+	case *Background:
+		require("description", v.Description != "")
+	case *Class:
+		require("description", v.Description != "")
+	case *Subclass:
+		require("class", v.Class != "")
+	case *Feat:
+		require("description", v.Description != "")
+	case *Condition:
+		require("effects", len(v.Effects) > 0)
+	case *Rule:
+		require("description", v.Description != "")
+	}
+	return missing
+}
+
+// references returns the entries an entry names: a spell's classes, the
+// spells of a class's spell list, a subclass's class and the conditions a
+// condition includes.
+func references(e entry) []reference {
+	var refs []reference
+	switch v := e.(type) {
+	case *Spell:
+		for _, class := range v.Classes {
+			refs = append(refs, reference{kind: KindClass, name: class, field: "classes"})
+		}
+	case *Class:
+		for _, spell := range v.Spells {
+			refs = append(refs, reference{kind: KindSpell, name: spell, field: "spells"})
+		}
+	case *Subclass:
+		if v.Class != "" {
+			refs = append(refs, reference{kind: KindClass, name: v.Class, field: "class"})
+		}
+	case *Condition:
+		for _, condition := range v.Includes {
+			refs = append(refs, reference{kind: KindCondition, name: condition, field: "includes"})
+		}
+	}
+	return refs
+}
+
+// checkOverlayFiles warns about JSON and YAML files at the top of an
+// overlay that no category reads, such as a misspelled spels.yaml.
+func (r *Report) checkOverlayFiles(dir string) {
+	known := map[string]bool{manifestFile: true}
+	for _, c := range categories() {
+		for _, ext := range contentExts {
+			known[c.label()+ext] = true
+		}
+	}
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		if !e.IsDir() && hasContentExt(e.Name()) && !known[strings.ToLower(e.Name())] {
+			r.add(Warning, filepath.Join(dir, e.Name()), 0, "", "not a content file; it is ignored")
+		}
+	}
+}
+
+// rawEntry is one entry of a content file, as JSON, with the line it
+// starts on.
+type rawEntry struct {
+	line int
+	raw  json.RawMessage
+}
+
+// parseError is a content file that can't be parsed, with the line where
+// parsing failed if it is known.
+type parseError struct {
+	line int
+	err  error
+}
+
+func (e *parseError) Error() string { return e.err.Error() }
+
+// splitEntries splits a content file, a list of entries or a single entry
+// in JSON or YAML, into its entries.
+func splitEntries(path string, raw []byte) ([]rawEntry, error) {
+	if isYAML(path) {
+		return splitYAML(raw)
+	}
+	return splitJSON(raw)
+}
+
+// splitJSON splits a JSON content file into its entries.
+func splitJSON(raw []byte) ([]rawEntry, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	start := skipSeparators(raw, 0)
+	if start < len(raw) && raw[start] == '{' {
+		var entry json.RawMessage
+		if err := dec.Decode(&entry); err != nil {
+			return nil, jsonError(raw, err)
+		}
+		return []rawEntry{{lineAt(raw, start), entry}}, nil
+	}
+
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, jsonError(raw, err)
+	}
+	if tok != json.Delim('[') {
+		return nil, &parseError{lineAt(raw, start), errors.New("expected a list of entries or a single entry")}
+	}
+	var entries []rawEntry
+	for dec.More() {
+		start := skipSeparators(raw, int(dec.InputOffset()))
+		var entry json.RawMessage
+		if err := dec.Decode(&entry); err != nil {
+			return nil, jsonError(raw, err)
+		}
+		entries = append(entries, rawEntry{lineAt(raw, start), entry})
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, jsonError(raw, err)
+	}
+	return entries, nil
+}
+
+// skipSeparators returns the offset of the first byte at or after offset
+// that is neither white space nor a comma.
+func skipSeparators(raw []byte, offset int) int {
+	for offset < len(raw) && strings.IndexByte(" \t\r\n,", raw[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+// jsonError adds the line to a JSON decoding error, if it is known.
+func jsonError(raw []byte, err error) error {
+	var syntax *json.SyntaxError
+	switch {
+	case errors.As(err, &syntax):
+		return &parseError{lineAt(raw, int(syntax.Offset)), err}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return &parseError{lineAt(raw, len(bytes.TrimRight(raw, " \t\r\n"))), err}
+	}
+	return &parseError{0, err}
+}
+
+// lineAt returns the line of raw that offset falls on, counting from 1.
+func lineAt(raw []byte, offset int) int {
+	if offset > len(raw) {
+		offset = len(raw)
+	}
+	return bytes.Count(raw[:offset], []byte("\n")) + 1
+}
+
+// splitYAML splits a YAML content file into its entries, converted to JSON.
+func splitYAML(raw []byte) ([]rawEntry, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, &parseError{0, err}
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	nodes := doc.Content[:1]
+	if root := doc.Content[0]; root.Kind == yaml.SequenceNode {
+		nodes = root.Content
+	}
+	var entries []rawEntry
+	for _, n := range nodes {
+		var v interface{}
+		if err := n.Decode(&v); err != nil {
+			return nil, &parseError{n.Line, err}
+		}
+		entry, err := json.Marshal(jsonValue(v))
+		if err != nil {
+			return nil, &parseError{n.Line, err}
+		}
+		entries = append(entries, rawEntry{n.Line, entry})
+	}
+	return entries, nil
+}
+
+// jsonFields returns the JSON names of the fields of struct type t, with
+// the publisher and book every entry may name and, for types that decode
+// themselves, the properties map they accept.
+func jsonFields(t reflect.Type) map[string]bool {
+	fields := map[string]bool{"publisher": true, "book": true}
+	if reflect.PointerTo(t).Implements(reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()) {
+		fields["properties"] = true
+	}
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = true
+		}
+	}
+	return fields
+}
@@ -0,0 +1,97 @@
+package dice
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CheckResult is the outcome of a rolled total, judged against the roll's
+// "vs" target when it has one. A natural 20 always hits and a natural 1
+// always misses, as for attack rolls.
+type CheckResult struct {
+	Total     int
+	Target    int
+	HasTarget bool
+	Hit       bool
+	Margin    int // Total - Target
+	Natural   int // face of the deciding d20, or 0 if there is none
+}
+
+// NaturalTwenty reports whether the deciding d20 showed a 20.
+func (c CheckResult) NaturalTwenty() bool { return c.Natural == 20 }
+
+// NaturalOne reports whether the deciding d20 showed a 1.
+func (c CheckResult) NaturalOne() bool { return c.Natural == 1 }
+
+// String renders the result as e.g. "Hit! (beats 15 by 3)" or "Natural 1!
+// Miss (short of 15 by 2)". It is empty when there is nothing to report.
+func (c CheckResult) String() string {
+	var parts []string
+	if c.NaturalTwenty() {
+		parts = append(parts, "Natural 20!")
+	} else if c.NaturalOne() {
+		parts = append(parts, "Natural 1!")
+	}
+	if c.HasTarget {
+		switch {
+		case c.Hit && c.Margin >= 0:
+			parts = append(parts, fmt.Sprintf("Hit! (beats %d by %d)", c.Target, c.Margin))
+		case c.Hit:
+			parts = append(parts, fmt.Sprintf("Hit! (short of %d by %d)", c.Target, -c.Margin))
+		case c.Margin >= 0:
+			parts = append(parts, fmt.Sprintf("Miss (beats %d by %d)", c.Target, c.Margin))
+		default:
+			parts = append(parts, fmt.Sprintf("Miss (short of %d by %d)", c.Target, -c.Margin))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// Check judges a total rolled by dr. The deciding d20 is the first group
+// that keeps a single d20, so "1d20+7" and "2d20kh1+7" are flagged on a
+// natural 1 or 20 but "2d20" is not.
+func (dr *DiceRoll) Check(total int, terms []TermResult) CheckResult {
+	c := CheckResult{Total: total, Target: dr.Target, HasTarget: dr.HasTarget, Natural: naturalD20(terms)}
+	if c.HasTarget {
+		c.Margin = total - dr.Target
+		c.Hit = c.Margin >= 0
+		if c.NaturalTwenty() {
+			c.Hit = true
+		} else if c.NaturalOne() {
+			c.Hit = false
+		}
+	}
+	return c
+}
+
+// naturalD20 returns the face of the first kept single d20, or 0.
+func naturalD20(terms []TermResult) int {
+	for _, t := range terms {
+		for _, g := range t.Groups {
+			if g.Sides != 20 || g.Pool || len(g.Rolls) != 1 {
+				continue
+			}
+			for _, d := range g.Dice {
+				if !d.Dropped {
+					return d.face()
+				}
+			}
+		}
+	}
+	return 0
+}
+
+// splitTarget splits "1d20+7 vs 15" into its expression and target number.
+func splitTarget(notation string) (string, int, bool, error) {
+	expr, target, found := strings.Cut(strings.ToLower(notation), "vs")
+	if !found {
+		return notation, 0, false, nil
+	}
+	target = strings.Join(strings.Fields(target), "")
+	n, err := strconv.Atoi(target)
+	if err != nil {
+		return "", 0, false, fmt.Errorf("invalid target number %q", target)
+	}
+	return expr, n, true, nil
+}
@@ -0,0 +1,72 @@
+package dice
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CritMode selects how a critical hit changes a damage roll. Only dice are
+// affected; flat modifiers are always added once.
+type CritMode int
+
+const (
+	CritNone        CritMode = iota
+	CritDoubleDice           // roll twice as many dice (the 5e rule)
+	CritMaxPlusRoll          // one set of dice counts as maximum, plus a normal roll
+	CritDoubleTotal          // roll the dice once and double what they show
+)
+
+// critModeNames maps the names accepted by ParseCritMode to their modes.
+var critModeNames = map[string]CritMode{
+	"double-dice":   CritDoubleDice,
+	"max-plus-roll": CritMaxPlusRoll,
+	"double-total":  CritDoubleTotal,
+}
+
+// ParseCritMode parses a crit mode name: double-dice, max-plus-roll or
+// double-total.
+func ParseCritMode(name string) (CritMode, error) {
+	if mode, ok := critModeNames[strings.ToLower(name)]; ok {
+		return mode, nil
+	}
+	return CritNone, fmt.Errorf("unknown crit mode %q (use double-dice, max-plus-roll or double-total)", name)
+}
+
+func (c CritMode) String() string {
+	switch c {
+	case CritDoubleDice:
+		return "dice doubled"
+	case CritMaxPlusRoll:
+		return "max dice + roll"
+	case CritDoubleTotal:
+		return "dice total doubled"
+	}
+	return "none"
+}
+
+// WithCrit returns a copy of the roll with critical hit damage applied to
+// every dice group according to mode.
+func (dr *DiceRoll) WithCrit(mode CritMode) (*DiceRoll, error) {
+	if mode == CritNone {
+		return dr, nil
+	}
+	var err error
+	expr := transformDice(dr.expr, func(dn *diceNode) node {
+		crit := *dn
+		crit.crit = mode
+		if mode == CritDoubleDice {
+			crit.count *= 2
+			crit.keep.n *= 2
+			if crit.count > MaxDice {
+				err = fmt.Errorf("a critical %s would roll more than %d dice", dn, MaxDice)
+			}
+		}
+		return &crit
+	})
+	if err != nil {
+		return nil, err
+	}
+	crit := dr.derive(expr)
+	crit.Crit = mode
+	return crit, nil
+}
@@ -1,82 +1,260 @@
 package dice
 
 import (
+	"errors"
 	"fmt"
-	"math/rand"
-	"regexp"
-	"strconv"
-	"time"
+	"strings"
 )
 
-func init() {
-	rand.Seed(time.Now().UnixNano())
-}
+// Limits that keep a single expression from rolling an unreasonable number of dice.
+const (
+	MaxDice  = 1000
+	MaxSides = 1000000
+)
 
-// DiceRoll represents the parsed components of a dice roll notation.
+// DiceRoll represents a parsed dice expression such as "1d8+2d6+3".
+// NumDice and DieType describe the first dice group of the expression and
+// Modifier is the net constant added at the top level, so a simple "NdM+K"
+// notation can still be inspected field by field.
 type DiceRoll struct {
 	NumDice  int
 	DieType  int
 	Modifier int
 	Notation string
+	Crit     CritMode // set by WithCrit
+
+	// Target is the number after "vs" in e.g. "1d20+7 vs 15"; see Check.
+	Target    int
+	HasTarget bool
+
+	expr node
 }
 
-// ParseDiceNotation parses a string like "2d6+5" into a DiceRoll struct.
-func ParseDiceNotation(notation string) (*DiceRoll, error) {
-	re := regexp.MustCompile(`^(\d+)d(\d+)(?:([+-])(\d+))?$`)
-	matches := re.FindStringSubmatch(notation)
+// GroupResult is the outcome of rolling a single dice group such as "2d6".
+// Rolls holds the values of the dice that count towards Value; dice
+// discarded by a keep/drop modifier are reported separately in Dropped.
+// Dice holds every die in roll order with its reroll/explosion history.
+// For a dice pool such as "10d6>=5", Value is the net count of successes
+// (see pool.go) rather than the sum of Rolls.
+type GroupResult struct {
+	Notation  string
+	Sides     int
+	Rolls     []int
+	Dropped   []int
+	Dice      []Die
+	Crit      CritMode
+	Pool      bool
+	Successes int
+	Failures  int
+	Value     int
+
+	countsSuccesses, countsFailures bool
+}
+
+// String renders the group as e.g. "4d6kh3 [6, 4, 3] dropped [1]" or
+// "2d6ro<3 [1→5, 6]".
+func (g GroupResult) String() string {
+	var kept, dropped []string
+	for _, d := range g.Dice {
+		if d.Dropped {
+			dropped = append(dropped, d.String())
+		} else {
+			kept = append(kept, d.String())
+		}
+	}
+	s := fmt.Sprintf("%s [%s]", g.Notation, strings.Join(kept, ", "))
+	if len(dropped) > 0 {
+		s += fmt.Sprintf(" dropped [%s]", strings.Join(dropped, ", "))
+	}
+	if g.Crit != CritNone {
+		s += fmt.Sprintf(" (critical: %s)", g.Crit)
+	}
+	var counts []string
+	if g.countsSuccesses {
+		counts = append(counts, plural(g.Successes, "success", "successes"))
+	}
+	if g.countsFailures {
+		counts = append(counts, plural(g.Failures, "failure", "failures"))
+	}
+	if len(counts) > 0 {
+		s += fmt.Sprintf(" (%s)", strings.Join(counts, ", "))
+	}
+	return s
+}
 
-	if len(matches) == 0 {
-		return nil, fmt.Errorf("invalid dice notation: %s", notation)
+// plural formats a count with the singular or plural form of a noun.
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, one)
 	}
+	return fmt.Sprintf("%d %s", n, many)
+}
 
-	numDice, err := strconv.Atoi(matches[1])
-	if err != nil {
-		return nil, fmt.Errorf("invalid number of dice: %w", err)
+// TermResult is the outcome of one top-level term of an expression, i.e.
+// one operand of the outermost chain of additions and subtractions.
+type TermResult struct {
+	Sign     int // +1 or -1
+	Notation string
+	Value    int // unsigned value of the term
+	Groups   []GroupResult
+}
+
+// String renders the term as e.g. "2d6 [3, 4] = 7" or "3".
+func (t TermResult) String() string {
+	if len(t.Groups) == 0 {
+		return t.Notation
+	}
+	var parts []string
+	for _, g := range t.Groups {
+		parts = append(parts, g.String())
 	}
-	if numDice <= 0 {
-		return nil, fmt.Errorf("number of dice must be positive")
+	if len(t.Groups) == 1 && t.Groups[0].Notation == t.Notation {
+		return fmt.Sprintf("%s = %d", parts[0], t.Value)
+	}
+	return fmt.Sprintf("%s {%s} = %d", t.Notation, strings.Join(parts, "; "), t.Value)
+}
+
+// FormatTerms renders a per-term breakdown, one signed term per line.
+func FormatTerms(terms []TermResult) string {
+	lines := make([]string, len(terms))
+	for i, t := range terms {
+		sign := "+"
+		if t.Sign < 0 {
+			sign = "-"
+		}
+		lines[i] = fmt.Sprintf("%s %s", sign, t)
 	}
+	return strings.Join(lines, "\n")
+}
 
-	dieType, err := strconv.Atoi(matches[2])
+// ParseDiceNotation parses a dice expression like "2d6+5", "1d20+1d4-1" or
+// "(1d6+2)*2" into a DiceRoll. Expressions may combine any number of dice
+// groups and constants with +, -, * and / (division rounds down), and may
+// use parentheses. At least one dice group is required.
+//
+// A dice group may carry modifiers (see parseModifiers): keep/drop ("4d6kh3",
+// "2d20kl1", "4d6dl1"), exploding ("3d6!"), rerolling ("2d6r1", "2d6ro<3")
+// and clamping ("1d8min2", "1d20max15"). Comparing a group with a target
+// ("10d6>=5") or adding a failure target ("8d10f1") turns it into a dice
+// pool that counts dice instead of summing them.
+//
+// The notation may end with a target number, as in "1d20+7 vs 15", which
+// Check compares the total against.
+func ParseDiceNotation(notation string) (*DiceRoll, error) {
+	exprNotation, target, hasTarget, err := splitTarget(notation)
 	if err != nil {
-		return nil, fmt.Errorf("invalid die type: %w", err)
+		return nil, fmt.Errorf("invalid dice notation %q: %w", notation, err)
 	}
-	if dieType <= 0 {
-		return nil, fmt.Errorf("die type must be positive")
+	expr, err := parseExpression(exprNotation)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dice notation %q: %w", notation, err)
 	}
+	if !containsDice(expr) {
+		return nil, fmt.Errorf("invalid dice notation %q: no dice to roll", notation)
+	}
+	dr := newDiceRoll(notation, expr)
+	dr.Target, dr.HasTarget = target, hasTarget
+	return dr, nil
+}
 
-	modifier := 0
-	if len(matches) > 3 && matches[3] != "" {
-		modValue, err := strconv.Atoi(matches[4])
-		if err != nil {
-			return nil, fmt.Errorf("invalid modifier value: %w", err)
+// MustParseDiceNotation is like ParseDiceNotation but panics if the notation
+// cannot be parsed. It is intended for fixed notations known to be valid.
+func MustParseDiceNotation(notation string) *DiceRoll {
+	dr, err := ParseDiceNotation(notation)
+	if err != nil {
+		panic(err)
+	}
+	return dr
+}
+
+// newDiceRoll builds a DiceRoll for expr, filling in the summary fields.
+func newDiceRoll(notation string, expr node) *DiceRoll {
+	dr := &DiceRoll{Notation: notation, expr: expr}
+	for _, t := range topLevelTerms(expr) {
+		switch operand := t.operand.(type) {
+		case *numberNode:
+			dr.Modifier += t.sign * operand.value
+		case *diceNode:
+			if dr.NumDice == 0 {
+				dr.NumDice = operand.count
+				dr.DieType = operand.sides
+			}
 		}
-		if matches[3] == "-" {
-			modifier = -modValue
-		} else {
-			modifier = modValue
+	}
+	return dr
+}
+
+// ErrNoSingleD20 is returned by WithAdvantage and WithDisadvantage for a
+// roll without a single d20, such as 4d6.
+var ErrNoSingleD20 = errors.New("no single d20")
+
+// WithAdvantage returns a copy of the roll in which the first single d20 is
+// rolled twice and the higher die kept, so "1d20+5" becomes "2d20kh1+5".
+func (dr *DiceRoll) WithAdvantage() (*DiceRoll, error) {
+	return dr.withD20Keep(keepHighest, "advantage")
+}
+
+// WithDisadvantage returns a copy of the roll in which the first single d20
+// is rolled twice and the lower die kept, so "1d20+5" becomes "2d20kl1+5".
+func (dr *DiceRoll) WithDisadvantage() (*DiceRoll, error) {
+	return dr.withD20Keep(keepLowest, "disadvantage")
+}
+
+func (dr *DiceRoll) withD20Keep(mode keepMode, label string) (*DiceRoll, error) {
+	found := false
+	expr := transformDice(dr.expr, func(dn *diceNode) node {
+		if found || dn.count != 1 || dn.sides != 20 || dn.keep.mode != keepAll {
+			return dn
 		}
+		found = true
+		return &diceNode{count: 2, sides: 20, keep: keepRule{mode: mode, n: 1}}
+	})
+	if !found {
+		return nil, fmt.Errorf("%s has %w to roll with %s", dr.Notation, ErrNoSingleD20, label)
 	}
+	return dr.derive(expr), nil
+}
 
-	return &DiceRoll{
-		NumDice:  numDice,
-		DieType:  dieType,
-		Modifier: modifier,
-		Notation: notation,
-	}, nil
+// derive returns a roll of expr that keeps dr's target and crit mode.
+func (dr *DiceRoll) derive(expr node) *DiceRoll {
+	notation := expr.String()
+	if dr.HasTarget {
+		notation += fmt.Sprintf(" vs %d", dr.Target)
+	}
+	derived := newDiceRoll(notation, expr)
+	derived.Crit, derived.Target, derived.HasTarget = dr.Crit, dr.Target, dr.HasTarget
+	return derived
 }
 
-// Roll performs the dice roll based on the DiceRoll struct.
-func (dr *DiceRoll) Roll() (int, []int) {
-	rolls := make([]int, dr.NumDice)
-	total := 0
+// Roll performs the dice roll with the default Roller and returns the total
+// along with a per-term breakdown of the individual dice.
+func (dr *DiceRoll) Roll() (int, []TermResult) {
+	return dr.RollWith(Default())
+}
 
-	for i := 0; i < dr.NumDice; i++ {
-		roll := rand.Intn(dr.DieType) + 1 // rand.Intn(n) returns [0, n-1], so add 1 for [1, n]
-		rolls[i] = roll
-		total += roll
+// RollWith performs the dice roll drawing from r.
+func (dr *DiceRoll) RollWith(r *Roller) (int, []TermResult) {
+	total := 0
+	var terms []TermResult
+	for _, t := range topLevelTerms(dr.expr) {
+		var groups []GroupResult
+		value := t.operand.eval(r, &groups)
+		total += t.sign * value
+		terms = append(terms, TermResult{
+			Sign:     t.sign,
+			Notation: t.operand.String(),
+			Value:    value,
+			Groups:   groups,
+		})
 	}
+	return total, terms
+}
 
-	total += dr.Modifier
-	return total, rolls
+// topLevelTerms splits an expression into the operands of its outermost
+// chain of additions and subtractions.
+func topLevelTerms(n node) []sumTerm {
+	if sum, ok := n.(*sumNode); ok {
+		return sum.terms
+	}
+	return []sumTerm{{sign: 1, operand: n}}
 }
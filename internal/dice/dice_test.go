@@ -1,6 +1,9 @@
 package dice
 
 import (
+	"errors"
+	"math"
+	"strings"
 	"testing"
 )
 
@@ -76,3 +79,429 @@ func TestRoll(t *testing.T) {
 		})
 	}
 }
+
+func TestParseDiceExpression(t *testing.T) {
+	tests := []struct {
+		name      string
+		notation  string
+		expectMin int
+		expectMax int
+		expectErr bool
+	}{
+		{name: "mixed-dice", notation: "1d8+2d6+3", expectMin: 6, expectMax: 23},
+		{name: "bless", notation: "1d20+1d4-1", expectMin: 1, expectMax: 23},
+		{name: "whitespace", notation: "1d20 + 1d4 - 1", expectMin: 1, expectMax: 23},
+		{name: "implicit-count", notation: "d6+1", expectMin: 2, expectMax: 7},
+		{name: "percentile", notation: "d%", expectMin: 1, expectMax: 100},
+		{name: "parentheses", notation: "(1d6+2)*2", expectMin: 6, expectMax: 16},
+		{name: "floor-division", notation: "1d6/2", expectMin: 0, expectMax: 3},
+		{name: "negative-floor-division", notation: "(1d1-4)/2", expectMin: -2, expectMax: -2},
+		{name: "unary-minus", notation: "-1d4+10", expectMin: 6, expectMax: 9},
+		{name: "zero-divisor", notation: "1d6/0", expectErr: true},
+		{name: "possible-zero-divisor", notation: "1d6/(1d2-1)", expectErr: true},
+		{name: "unbalanced-parentheses", notation: "(1d6+2", expectErr: true},
+		{name: "trailing-operator", notation: "1d6*", expectErr: true},
+		{name: "constants-only", notation: "2+3", expectErr: true},
+		{name: "too-many-dice", notation: "1001d6", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dr, err := ParseDiceNotation(tt.notation)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("ParseDiceNotation() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if tt.expectErr {
+				return
+			}
+			for i := 0; i < 100; i++ {
+				total, _ := dr.Roll()
+				if total < tt.expectMin || total > tt.expectMax {
+					t.Errorf("Roll() for %s returned %d, expected between %d and %d", tt.notation, total, tt.expectMin, tt.expectMax)
+				}
+			}
+		})
+	}
+}
+
+func TestRollBreakdown(t *testing.T) {
+	dr, err := ParseDiceNotation("1d8+2d6-3")
+	if err != nil {
+		t.Fatalf("ParseDiceNotation() failed: %v", err)
+	}
+	if dr.NumDice != 1 || dr.DieType != 8 || dr.Modifier != -3 {
+		t.Errorf("ParseDiceNotation() = %dd%d%+d, want 1d8-3", dr.NumDice, dr.DieType, dr.Modifier)
+	}
+
+	total, terms := dr.Roll()
+	if len(terms) != 3 {
+		t.Fatalf("Roll() returned %d terms, want 3", len(terms))
+	}
+	sum := 0
+	for _, term := range terms {
+		sum += term.Sign * term.Value
+	}
+	if sum != total {
+		t.Errorf("terms sum to %d, total is %d", sum, total)
+	}
+	if len(terms[1].Groups) != 1 || len(terms[1].Groups[0].Rolls) != 2 || terms[1].Groups[0].Sides != 6 {
+		t.Errorf("second term = %+v, want a single 2d6 group", terms[1])
+	}
+	if terms[2].Sign != -1 || terms[2].Value != 3 || len(terms[2].Groups) != 0 {
+		t.Errorf("third term = %+v, want constant -3", terms[2])
+	}
+}
+
+func TestKeepDrop(t *testing.T) {
+	tests := []struct {
+		name        string
+		notation    string
+		expectKept  int
+		keepHighest bool
+		expectErr   bool
+	}{
+		{name: "keep-highest", notation: "4d6kh3", expectKept: 3, keepHighest: true},
+		{name: "keep-shorthand", notation: "4d6k3", expectKept: 3, keepHighest: true},
+		{name: "keep-lowest", notation: "2d20kl1", expectKept: 1},
+		{name: "drop-lowest", notation: "4d6dl1", expectKept: 3, keepHighest: true},
+		{name: "drop-highest", notation: "3d8dh2", expectKept: 1},
+		{name: "keep-too-many", notation: "2d20kh3", expectErr: true},
+		{name: "drop-all", notation: "4d6dl4", expectErr: true},
+		{name: "missing-count", notation: "4d6kh", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dr, err := ParseDiceNotation(tt.notation)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("ParseDiceNotation() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if tt.expectErr {
+				return
+			}
+			for i := 0; i < 100; i++ {
+				total, terms := dr.Roll()
+				g := terms[0].Groups[0]
+				if len(g.Rolls) != tt.expectKept || len(g.Rolls)+len(g.Dropped) != dr.NumDice {
+					t.Fatalf("Roll() kept %v and dropped %v, want %d kept", g.Rolls, g.Dropped, tt.expectKept)
+				}
+				sum := 0
+				for _, kept := range g.Rolls {
+					sum += kept
+					for _, dropped := range g.Dropped {
+						if (tt.keepHighest && kept < dropped) || (!tt.keepHighest && kept > dropped) {
+							t.Errorf("Roll() kept %v but dropped %v", g.Rolls, g.Dropped)
+						}
+					}
+				}
+				if sum != total {
+					t.Errorf("kept dice sum to %d, total is %d", sum, total)
+				}
+			}
+		})
+	}
+}
+
+func TestAdvantage(t *testing.T) {
+	dr, err := ParseDiceNotation("1d20+5")
+	if err != nil {
+		t.Fatalf("ParseDiceNotation() failed: %v", err)
+	}
+
+	adv, err := dr.WithAdvantage()
+	if err != nil {
+		t.Fatalf("WithAdvantage() failed: %v", err)
+	}
+	if adv.Notation != "2d20kh1+5" {
+		t.Errorf("WithAdvantage() notation = %s, want 2d20kh1+5", adv.Notation)
+	}
+
+	dis, err := dr.WithDisadvantage()
+	if err != nil {
+		t.Fatalf("WithDisadvantage() failed: %v", err)
+	}
+	if dis.Notation != "2d20kl1+5" {
+		t.Errorf("WithDisadvantage() notation = %s, want 2d20kl1+5", dis.Notation)
+	}
+	if dr.Notation != "1d20+5" {
+		t.Errorf("WithAdvantage() modified the original roll: %s", dr.Notation)
+	}
+
+	noD20, _ := ParseDiceNotation("2d6")
+	if _, err := noD20.WithAdvantage(); !errors.Is(err, ErrNoSingleD20) {
+		t.Errorf("WithAdvantage() on 2d6 error = %v, want ErrNoSingleD20", err)
+	}
+}
+
+func TestRerollExplodeClamp(t *testing.T) {
+	gwf, err := ParseDiceNotation("2d6ro<3")
+	if err != nil {
+		t.Fatalf("ParseDiceNotation() failed: %v", err)
+	}
+	adept, err := ParseDiceNotation("8d6min2")
+	if err != nil {
+		t.Fatalf("ParseDiceNotation() failed: %v", err)
+	}
+	explode, err := ParseDiceNotation("4d6!")
+	if err != nil {
+		t.Fatalf("ParseDiceNotation() failed: %v", err)
+	}
+
+	for i := 0; i < 100; i++ {
+		_, terms := gwf.Roll()
+		for _, d := range terms[0].Groups[0].Dice {
+			if d.History[0] < 3 && (!d.Rerolled || len(d.History) != 2) {
+				t.Errorf("ro<3 die %v should have been rerolled exactly once", d.History)
+			}
+			if d.History[0] >= 3 && d.Rerolled {
+				t.Errorf("ro<3 die %v should not have been rerolled", d.History)
+			}
+		}
+
+		_, terms = adept.Roll()
+		for _, d := range terms[0].Groups[0].Dice {
+			if d.Value < 2 || d.Clamped != (d.History[0] == 1) {
+				t.Errorf("min2 die %+v not clamped correctly", d)
+			}
+		}
+
+		total, terms := explode.Roll()
+		g := terms[0].Groups[0]
+		exploded, sum := 0, 0
+		for _, d := range g.Dice {
+			if d.Exploded != (d.Value == 6) {
+				t.Errorf("4d6! die %+v exploded incorrectly", d)
+			}
+			if d.Exploded {
+				exploded++
+			}
+			sum += d.Value
+		}
+		if len(g.Dice) != 4+exploded || sum != total {
+			t.Errorf("4d6! rolled %d dice with %d explosions, sum %d, total %d", len(g.Dice), exploded, sum, total)
+		}
+	}
+}
+
+func TestModifierErrors(t *testing.T) {
+	for _, notation := range []string{
+		"1d1!",    // explodes forever
+		"1d6r<7",  // rerolls every face
+		"1d6min0", // clamp below 1
+		"1d6max7", // clamp beyond the die
+		"1d6min5max4",
+		"2d6!!",
+		"2d6r1r2",
+		"2d6r",
+	} {
+		if _, err := ParseDiceNotation(notation); err == nil {
+			t.Errorf("ParseDiceNotation(%q) expected error, got nil", notation)
+		}
+	}
+}
+
+func TestSeededRollerIsReproducible(t *testing.T) {
+	dr, err := ParseDiceNotation("4d6!kh3+1d20ro<3-2")
+	if err != nil {
+		t.Fatalf("ParseDiceNotation() failed: %v", err)
+	}
+
+	first, second := NewSeededRoller(42), NewSeededRoller(42)
+	for i := 0; i < 20; i++ {
+		total1, terms1 := dr.RollWith(first)
+		total2, terms2 := dr.RollWith(second)
+		if total1 != total2 || FormatTerms(terms1) != FormatTerms(terms2) {
+			t.Fatalf("seeded rolls diverged: %d (%s) vs %d (%s)", total1, FormatTerms(terms1), total2, FormatTerms(terms2))
+		}
+	}
+}
+
+func TestDistribution(t *testing.T) {
+	tests := []struct {
+		notation   string
+		expectMin  int
+		expectMax  int
+		expectMean float64
+		target     int
+		expectAtLe float64
+	}{
+		{notation: "2d6+3", expectMin: 5, expectMax: 15, expectMean: 10, target: 10, expectAtLe: 21.0 / 36},
+		{notation: "4d6kh3", expectMin: 3, expectMax: 18, expectMean: 15869.0 / 1296, target: 18, expectAtLe: 21.0 / 1296},
+		{notation: "2d20kh1", expectMin: 1, expectMax: 20, expectMean: 13.825, target: 20, expectAtLe: 39.0 / 400},
+		{notation: "2d20kl1", expectMin: 1, expectMax: 20, expectMean: 7.175, target: 20, expectAtLe: 1.0 / 400},
+		{notation: "2d6ro<3", expectMin: 2, expectMax: 12, expectMean: 25.0 / 3, target: 12, expectAtLe: 16.0 / 324},
+		{notation: "1d6min2", expectMin: 2, expectMax: 6, expectMean: 22.0 / 6, target: 2, expectAtLe: 1},
+		{notation: "1d6/2", expectMin: 0, expectMax: 3, expectMean: 1.5, target: 3, expectAtLe: 1.0 / 6},
+		{notation: "1d20-1d4", expectMin: -3, expectMax: 19, expectMean: 8, target: 19, expectAtLe: 1.0 / 80},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.notation, func(t *testing.T) {
+			dist, err := MustParseDiceNotation(tt.notation).Distribution()
+			if err != nil {
+				t.Fatalf("Distribution() failed: %v", err)
+			}
+			if dist.Min != tt.expectMin || dist.Max() != tt.expectMax {
+				t.Errorf("Distribution() range = %d..%d, want %d..%d", dist.Min, dist.Max(), tt.expectMin, tt.expectMax)
+			}
+			if math.Abs(dist.Mean()-tt.expectMean) > 1e-9 {
+				t.Errorf("Mean() = %v, want %v", dist.Mean(), tt.expectMean)
+			}
+			if got := dist.AtLeast(tt.target); math.Abs(got-tt.expectAtLe) > 1e-9 {
+				t.Errorf("AtLeast(%d) = %v, want %v", tt.target, got, tt.expectAtLe)
+			}
+		})
+	}
+}
+
+func TestExplodingDistribution(t *testing.T) {
+	dist, err := MustParseDiceNotation("1d6!").Distribution()
+	if err != nil {
+		t.Fatalf("Distribution() failed: %v", err)
+	}
+	// E[X] = 3.5 + E[X]/6, so E[X] = 4.2.
+	if math.Abs(dist.Mean()-4.2) > 1e-9 {
+		t.Errorf("Mean() = %v, want 4.2", dist.Mean())
+	}
+	if dist.P(6) != 0 || math.Abs(dist.P(7)-1.0/36) > 1e-12 {
+		t.Errorf("P(6) = %v, P(7) = %v, want 0 and 1/36", dist.P(6), dist.P(7))
+	}
+}
+
+func TestCrit(t *testing.T) {
+	tests := []struct {
+		mode       CritMode
+		expectNote string
+		expectMin  int
+		expectMax  int
+		expectMean float64
+	}{
+		{mode: CritDoubleDice, expectNote: "4d6+3", expectMin: 7, expectMax: 27, expectMean: 17},
+		{mode: CritMaxPlusRoll, expectNote: "2d6+3", expectMin: 17, expectMax: 27, expectMean: 22},
+		{mode: CritDoubleTotal, expectNote: "2d6+3", expectMin: 7, expectMax: 27, expectMean: 17},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode.String(), func(t *testing.T) {
+			crit, err := MustParseDiceNotation("2d6+3").WithCrit(tt.mode)
+			if err != nil {
+				t.Fatalf("WithCrit() failed: %v", err)
+			}
+			if crit.Notation != tt.expectNote || crit.Modifier != 3 {
+				t.Errorf("WithCrit() = %s with modifier %d, want %s with modifier 3", crit.Notation, crit.Modifier, tt.expectNote)
+			}
+			dist, err := crit.Distribution()
+			if err != nil {
+				t.Fatalf("Distribution() failed: %v", err)
+			}
+			if dist.Min != tt.expectMin || dist.Max() != tt.expectMax || math.Abs(dist.Mean()-tt.expectMean) > 1e-9 {
+				t.Errorf("Distribution() = %d..%d mean %v, want %d..%d mean %v", dist.Min, dist.Max(), dist.Mean(), tt.expectMin, tt.expectMax, tt.expectMean)
+			}
+			for i := 0; i < 50; i++ {
+				total, _ := crit.Roll()
+				if total < tt.expectMin || total > tt.expectMax {
+					t.Fatalf("Roll() = %d, outside %d..%d", total, tt.expectMin, tt.expectMax)
+				}
+			}
+		})
+	}
+
+	maxed, _ := MustParseDiceNotation("1d8").WithCrit(CritMaxPlusRoll)
+	_, terms := maxed.Roll()
+	g := terms[0].Groups[0]
+	if len(g.Dice) != 2 || !g.Dice[1].Maximized || g.Dice[1].Value != 8 {
+		t.Errorf("max-plus-roll dice = %+v, want a rolled die and a maximized 8", g.Dice)
+	}
+	if !strings.Contains(g.String(), "8(max)") || !strings.Contains(g.String(), "critical") {
+		t.Errorf("GroupResult.String() = %q, want the maximized die and crit marked", g.String())
+	}
+
+	if _, err := ParseCritMode("triple"); err == nil {
+		t.Errorf("ParseCritMode(triple) expected error, got nil")
+	}
+	if _, err := MustParseDiceNotation("600d6").WithCrit(CritDoubleDice); err == nil {
+		t.Errorf("WithCrit() on 600d6 expected error, got nil")
+	}
+}
+
+func TestDicePool(t *testing.T) {
+	tests := []struct {
+		notation   string
+		expectMin  int
+		expectMax  int
+		expectMean float64
+	}{
+		{notation: "10d6>=5", expectMin: 0, expectMax: 10, expectMean: 10.0 / 3},
+		{notation: "8d10f1", expectMin: 0, expectMax: 8, expectMean: 0.8},
+		{notation: "6d10>=8f1", expectMin: -6, expectMax: 6, expectMean: 6 * 0.2},
+		{notation: "4d6<3", expectMin: 0, expectMax: 4, expectMean: 4.0 / 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.notation, func(t *testing.T) {
+			dr := MustParseDiceNotation(tt.notation)
+			dist, err := dr.Distribution()
+			if err != nil {
+				t.Fatalf("Distribution() failed: %v", err)
+			}
+			if dist.Min != tt.expectMin || dist.Max() != tt.expectMax || math.Abs(dist.Mean()-tt.expectMean) > 1e-9 {
+				t.Errorf("Distribution() = %d..%d mean %v, want %d..%d mean %v", dist.Min, dist.Max(), dist.Mean(), tt.expectMin, tt.expectMax, tt.expectMean)
+			}
+
+			for i := 0; i < 50; i++ {
+				total, terms := dr.Roll()
+				g := terms[0].Groups[0]
+				if !g.Pool || total != g.Value {
+					t.Fatalf("Roll() = %d, group %+v", total, g)
+				}
+				if tt.notation == "8d10f1" && total != g.Failures {
+					t.Fatalf("failure pool total %d, want %d failures", total, g.Failures)
+				}
+				if !strings.Contains(tt.notation, "f") && total != g.Successes {
+					t.Fatalf("success pool total %d, want %d successes", total, g.Successes)
+				}
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	dr := MustParseDiceNotation("1d20+7 vs 15")
+	if !dr.HasTarget || dr.Target != 15 || dr.Modifier != 7 {
+		t.Fatalf("ParseDiceNotation() = target %d (%v), modifier %d, want target 15, modifier 7", dr.Target, dr.HasTarget, dr.Modifier)
+	}
+	adv, err := dr.WithAdvantage()
+	if err != nil {
+		t.Fatalf("WithAdvantage() failed: %v", err)
+	}
+	if adv.Notation != "2d20kh1+7 vs 15" || adv.Target != 15 {
+		t.Errorf("WithAdvantage() = %s, want 2d20kh1+7 vs 15", adv.Notation)
+	}
+
+	r := NewSeededRoller(1)
+	for i := 0; i < 200; i++ {
+		total, terms := dr.RollWith(r)
+		c := dr.Check(total, terms)
+		natural := terms[0].Groups[0].Rolls[0]
+		if c.Natural != natural || c.Margin != total-15 {
+			t.Fatalf("Check() = %+v for natural %d, total %d", c, natural, total)
+		}
+		want := total >= 15
+		if natural == 20 {
+			want = true
+		} else if natural == 1 {
+			want = false
+		}
+		if c.Hit != want {
+			t.Fatalf("Check() hit = %v for natural %d, total %d", c.Hit, natural, total)
+		}
+	}
+
+	_, terms := MustParseDiceNotation("2d20").Roll()
+	if c := MustParseDiceNotation("2d20").Check(0, terms); c.Natural != 0 || c.String() != "" {
+		t.Errorf("Check() on 2d20 = %+v, want nothing to report", c)
+	}
+	if _, err := ParseDiceNotation("1d20 vs ac"); err == nil {
+		t.Errorf("ParseDiceNotation(1d20 vs ac) expected error, got nil")
+	}
+}
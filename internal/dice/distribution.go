@@ -0,0 +1,403 @@
+package dice
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Limits that keep exact distributions tractable.
+const (
+	MaxDistributionWork = 50000000 // operations allowed for a single combination step
+	explodeEpsilon      = 1e-12    // probability below which explosion chains are truncated
+)
+
+// Distribution is the exact probability distribution of the totals of a
+// dice expression. Probs[i] is the probability of rolling a total of Min+i.
+type Distribution struct {
+	Min   int
+	Probs []float64
+}
+
+// Distribution computes the exact probability distribution of the roll's
+// total. Exploding dice chains are followed until further explosions become
+// negligibly unlikely.
+func (dr *DiceRoll) Distribution() (Distribution, error) {
+	d, err := distOf(dr.expr)
+	if err != nil {
+		return Distribution{}, fmt.Errorf("cannot compute distribution of %s: %w", dr.Notation, err)
+	}
+	return d, nil
+}
+
+// Max returns the largest possible total.
+func (d Distribution) Max() int { return d.Min + len(d.Probs) - 1 }
+
+// P returns the probability of rolling exactly v.
+func (d Distribution) P(v int) float64 {
+	if v < d.Min || v > d.Max() {
+		return 0
+	}
+	return d.Probs[v-d.Min]
+}
+
+// AtLeast returns the probability of rolling target or higher.
+func (d Distribution) AtLeast(target int) float64 {
+	p := 0.0
+	for v := max(target, d.Min); v <= d.Max(); v++ {
+		p += d.Probs[v-d.Min]
+	}
+	return math.Min(p, 1)
+}
+
+// Mean returns the expected total.
+func (d Distribution) Mean() float64 {
+	mean := 0.0
+	for i, p := range d.Probs {
+		mean += float64(d.Min+i) * p
+	}
+	return mean
+}
+
+// StdDev returns the standard deviation of the total.
+func (d Distribution) StdDev() float64 {
+	mean := d.Mean()
+	variance := 0.0
+	for i, p := range d.Probs {
+		diff := float64(d.Min+i) - mean
+		variance += diff * diff * p
+	}
+	return math.Sqrt(variance)
+}
+
+// Histogram renders the distribution as an ASCII bar chart with at most
+// maxRows rows; wide distributions are grouped into equal ranges of totals.
+// Bars are scaled so the most likely row is barWidth characters long.
+func (d Distribution) Histogram(maxRows, barWidth int) string {
+	bucket := (len(d.Probs) + maxRows - 1) / maxRows
+	type row struct {
+		label string
+		p     float64
+	}
+	var rows []row
+	peak := 0.0
+	for start := 0; start < len(d.Probs); start += bucket {
+		end := min(start+bucket, len(d.Probs))
+		r := row{label: fmt.Sprintf("%d", d.Min+start)}
+		if end-start > 1 {
+			r.label = fmt.Sprintf("%d-%d", d.Min+start, d.Min+end-1)
+		}
+		for _, p := range d.Probs[start:end] {
+			r.p += p
+		}
+		peak = math.Max(peak, r.p)
+		rows = append(rows, r)
+	}
+
+	labelWidth := 0
+	for _, r := range rows {
+		labelWidth = max(labelWidth, len(r.label))
+	}
+	var b strings.Builder
+	for _, r := range rows {
+		bar := 0
+		if peak > 0 {
+			bar = int(math.Round(r.p / peak * float64(barWidth)))
+		}
+		fmt.Fprintf(&b, "%*s | %-*s %6.2f%%\n", labelWidth, r.label, barWidth, strings.Repeat("#", bar), r.p*100)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// pointMass returns the distribution of a constant.
+func pointMass(v int) Distribution {
+	return Distribution{Min: v, Probs: []float64{1}}
+}
+
+// distOf computes the distribution of an expression node.
+func distOf(n node) (Distribution, error) {
+	switch n := n.(type) {
+	case *numberNode:
+		return pointMass(n.value), nil
+	case *diceNode:
+		return groupDist(n)
+	case *parenNode:
+		return distOf(n.inner)
+	case *negNode:
+		d, err := distOf(n.operand)
+		if err != nil {
+			return Distribution{}, err
+		}
+		return negate(d), nil
+	case *sumNode:
+		total := pointMass(0)
+		for _, t := range n.terms {
+			d, err := distOf(t.operand)
+			if err != nil {
+				return Distribution{}, err
+			}
+			if t.sign < 0 {
+				d = negate(d)
+			}
+			if total, err = convolve(total, d); err != nil {
+				return Distribution{}, err
+			}
+		}
+		return total, nil
+	case *productNode:
+		left, err := distOf(n.left)
+		if err != nil {
+			return Distribution{}, err
+		}
+		right, err := distOf(n.right)
+		if err != nil {
+			return Distribution{}, err
+		}
+		return combine(left, right, n.op)
+	}
+	return Distribution{}, fmt.Errorf("unsupported expression %s", n)
+}
+
+// negate returns the distribution of -X.
+func negate(d Distribution) Distribution {
+	probs := make([]float64, len(d.Probs))
+	for i, p := range d.Probs {
+		probs[len(probs)-1-i] = p
+	}
+	return Distribution{Min: -d.Max(), Probs: probs}
+}
+
+// convolve returns the distribution of X+Y for independent X and Y.
+func convolve(a, b Distribution) (Distribution, error) {
+	if len(a.Probs)*len(b.Probs) > MaxDistributionWork {
+		return Distribution{}, fmt.Errorf("expression is too large to analyse exactly")
+	}
+	probs := make([]float64, len(a.Probs)+len(b.Probs)-1)
+	for i, pa := range a.Probs {
+		if pa == 0 {
+			continue
+		}
+		for j, pb := range b.Probs {
+			probs[i+j] += pa * pb
+		}
+	}
+	return Distribution{Min: a.Min + b.Min, Probs: probs}, nil
+}
+
+// combine returns the distribution of X*Y or floor(X/Y).
+func combine(a, b Distribution, op byte) (Distribution, error) {
+	if len(a.Probs)*len(b.Probs) > MaxDistributionWork {
+		return Distribution{}, fmt.Errorf("expression is too large to analyse exactly")
+	}
+	outcomes := make(map[int]float64)
+	for i, pa := range a.Probs {
+		for j, pb := range b.Probs {
+			if pa*pb > 0 {
+				outcomes[applyOp(op, a.Min+i, b.Min+j)] += pa * pb
+			}
+		}
+	}
+	return fromMap(outcomes), nil
+}
+
+// fromMap builds a dense distribution from sparse outcome probabilities.
+func fromMap(outcomes map[int]float64) Distribution {
+	lo, hi := math.MaxInt, math.MinInt
+	for v := range outcomes {
+		lo = min(lo, v)
+		hi = max(hi, v)
+	}
+	probs := make([]float64, hi-lo+1)
+	for v, p := range outcomes {
+		probs[v-lo] = p
+	}
+	return Distribution{Min: lo, Probs: probs}
+}
+
+// faceProbs returns the probability of each face (index 1..sides) after the
+// group's reroll rule has been applied.
+func (n *diceNode) faceProbs() []float64 {
+	probs := make([]float64, n.sides+1)
+	base := 1 / float64(n.sides)
+	if n.reroll == nil {
+		for f := 1; f <= n.sides; f++ {
+			probs[f] = base
+		}
+		return probs
+	}
+	matching := 0
+	for f := 1; f <= n.sides; f++ {
+		if n.reroll.when.matches(f) {
+			matching++
+		}
+	}
+	q := float64(matching) / float64(n.sides)
+	for f := 1; f <= n.sides; f++ {
+		switch {
+		case n.reroll.once:
+			// Either the first roll stands, or it matched and was rerolled.
+			probs[f] = q * base
+			if !n.reroll.when.matches(f) {
+				probs[f] += base
+			}
+		case !n.reroll.when.matches(f):
+			// Rerolling until no match is uniform over the other faces.
+			probs[f] = 1 / float64(n.sides-matching)
+		}
+	}
+	return probs
+}
+
+// dieDist returns the distribution of a single die's contribution,
+// including any chain of explosions it triggers.
+func (n *diceNode) dieDist() Distribution {
+	faces := n.faceProbs()
+	values := make(map[int]float64)
+	explodeP := 0.0
+	for f := 1; f <= n.sides; f++ {
+		if n.explode != nil && n.explode.matches(f) {
+			explodeP += faces[f]
+			continue
+		}
+		values[n.faceValue(f)] += faces[f]
+	}
+	single := fromMap(values)
+	if explodeP == 0 {
+		return single
+	}
+
+	// An exploding face contributes its value plus a fresh die. Unroll the
+	// chain until the chance of reaching the next link is negligible.
+	chain := Distribution{Min: 0, Probs: []float64{1}} // accumulated value of earlier links
+	result := make(map[int]float64)
+	reach := 1.0
+	for depth := 0; reach > explodeEpsilon && depth <= MaxExplosions; depth++ {
+		for i, p := range chain.Probs {
+			for j, q := range single.Probs {
+				result[chain.Min+i+single.Min+j] += p * q
+			}
+		}
+		exploded := make(map[int]float64)
+		for f := 1; f <= n.sides; f++ {
+			if n.explode.matches(f) {
+				for i, p := range chain.Probs {
+					exploded[chain.Min+i+n.faceValue(f)] += p * faces[f]
+				}
+			}
+		}
+		chain = fromMap(exploded)
+		reach *= explodeP
+	}
+	return fromMap(result)
+}
+
+// groupDist returns the distribution of a dice group's value, including
+// any critical hit bonus.
+func groupDist(n *diceNode) (Distribution, error) {
+	d, err := rolledGroupDist(n)
+	if err != nil {
+		return Distribution{}, err
+	}
+	switch n.crit {
+	case CritMaxPlusRoll:
+		d.Min += n.keep.kept(n.count) * n.highFace()
+	case CritDoubleTotal:
+		return combine(d, pointMass(2), '*')
+	}
+	return d, nil
+}
+
+// rolledGroupDist returns the distribution of the dice a group rolls.
+func rolledGroupDist(n *diceNode) (Distribution, error) {
+	die := n.dieDist()
+	if n.keep.mode == keepAll {
+		return repeatConvolve(die, n.count)
+	}
+	if n.explode != nil {
+		return Distribution{}, fmt.Errorf("exploding dice combined with keep/drop are not supported")
+	}
+	if n.isPool() {
+		return Distribution{}, fmt.Errorf("dice pools combined with keep/drop are not supported")
+	}
+	highest := n.keep.mode == keepHighest || n.keep.mode == dropLowest
+	return keepDist(die, n.count, n.keep.kept(n.count), highest)
+}
+
+// repeatConvolve returns the distribution of the sum of count independent
+// copies of d, using repeated squaring.
+func repeatConvolve(d Distribution, count int) (Distribution, error) {
+	result := pointMass(0)
+	var err error
+	for count > 0 {
+		if count&1 == 1 {
+			if result, err = convolve(result, d); err != nil {
+				return Distribution{}, err
+			}
+		}
+		count >>= 1
+		if count > 0 {
+			if d, err = convolve(d, d); err != nil {
+				return Distribution{}, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// keepDist returns the distribution of the sum of the kept dice when count
+// independent dice distributed as die are rolled and the highest (or
+// lowest) kept are summed. Values are processed from best to worst, tracking
+// how many dice have been placed and the sum of those kept so far.
+func keepDist(die Distribution, count, kept int, highest bool) (Distribution, error) {
+	var values []int
+	for i, p := range die.Probs {
+		if p > 0 {
+			values = append(values, die.Min+i)
+		}
+	}
+	sort.Ints(values)
+	if highest {
+		for i, j := 0, len(values)-1; i < j; i, j = i+1, j-1 {
+			values[i], values[j] = values[j], values[i]
+		}
+	}
+	if work := len(values) * count * count * kept * len(die.Probs); work > MaxDistributionWork {
+		return Distribution{}, fmt.Errorf("expression is too large to analyse exactly")
+	}
+
+	// state[placed] maps a kept sum to its probability weight.
+	state := make([]map[int]float64, count+1)
+	state[0] = map[int]float64{0: 1}
+	for _, v := range values {
+		p := die.P(v)
+		next := make([]map[int]float64, count+1)
+		for placed, sums := range state {
+			for sum, w := range sums {
+				// Place c of the remaining dice on value v.
+				remaining := count - placed
+				for c := 0; c <= remaining; c++ {
+					weight := w * binomial(remaining, c) * math.Pow(p, float64(c))
+					if weight == 0 {
+						continue
+					}
+					take := max(0, min(c, kept-placed))
+					if next[placed+c] == nil {
+						next[placed+c] = make(map[int]float64)
+					}
+					next[placed+c][sum+take*v] += weight
+				}
+			}
+		}
+		state = next
+	}
+	return fromMap(state[count]), nil
+}
+
+// binomial returns n choose k as a float64.
+func binomial(n, k int) float64 {
+	lgN, _ := math.Lgamma(float64(n + 1))
+	lgK, _ := math.Lgamma(float64(k + 1))
+	lgNK, _ := math.Lgamma(float64(n - k + 1))
+	return math.Round(math.Exp(lgN - lgK - lgNK))
+}
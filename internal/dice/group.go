@@ -0,0 +1,501 @@
+package dice
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Limits that keep rerolling and exploding dice from running away.
+const (
+	MaxRerolls    = 100 // rerolls of a single die under "r"
+	MaxExplosions = 100 // extra dice a single group may gain from "!"
+)
+
+// Die is a single die of a GroupResult together with its roll history.
+type Die struct {
+	Value     int   // final value after rerolls and clamping
+	History   []int // every face rolled for this die, in order
+	Rerolled  bool  // the die was rerolled at least once
+	Exploded  bool  // the die exploded, adding another die to the group
+	Explosion bool  // the die was added by an explosion
+	Clamped   bool  // the value was raised by "min" or lowered by "max"
+	Dropped   bool  // the die was discarded by a keep/drop modifier
+	Maximized bool  // the die was counted at its maximum by a critical hit
+	Success   bool  // the die met the group's success target
+	Failure   bool  // the die met the group's failure target
+}
+
+// face returns the last face rolled, before any clamping.
+func (d Die) face() int { return d.History[len(d.History)-1] }
+
+// String renders the die with its history: "1→4" for a reroll, "6!" for an
+// explosion, "1↑2" or "6↓5" for a value raised by min or lowered by max and
+// "5✓" or "1✗" for a success or failure in a dice pool.
+func (d Die) String() string {
+	parts := make([]string, len(d.History))
+	for i, h := range d.History {
+		parts[i] = strconv.Itoa(h)
+	}
+	s := strings.Join(parts, "→")
+	if d.Clamped {
+		if d.Value > d.face() {
+			s += fmt.Sprintf("↑%d", d.Value)
+		} else {
+			s += fmt.Sprintf("↓%d", d.Value)
+		}
+	}
+	if d.Exploded {
+		s += "!"
+	}
+	if d.Maximized {
+		s += "(max)"
+	}
+	if d.Success {
+		s += "✓"
+	}
+	if d.Failure {
+		s += "✗"
+	}
+	return s
+}
+
+// comparePoint is a condition on a die face such as the "<3" in "r<3".
+type comparePoint struct {
+	op    string // "=", "<", ">", "<=" or ">="
+	value int
+}
+
+func (c comparePoint) matches(v int) bool {
+	switch c.op {
+	case "<":
+		return v < c.value
+	case ">":
+		return v > c.value
+	case "<=":
+		return v <= c.value
+	case ">=":
+		return v >= c.value
+	}
+	return v == c.value
+}
+
+// matchesAll reports whether every face of a die with the given sides matches.
+func (c comparePoint) matchesAll(sides int) bool {
+	for v := 1; v <= sides; v++ {
+		if !c.matches(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c comparePoint) String() string {
+	if c.op == "=" {
+		return strconv.Itoa(c.value)
+	}
+	return c.op + strconv.Itoa(c.value)
+}
+
+// keepMode selects which dice of a group count towards its value.
+type keepMode int
+
+const (
+	keepAll keepMode = iota
+	keepHighest
+	keepLowest
+	dropHighest
+	dropLowest
+)
+
+// keepRule is a keep/drop modifier such as the "kh3" in "4d6kh3".
+type keepRule struct {
+	mode keepMode
+	n    int
+}
+
+// kept returns how many of count dice the rule keeps.
+func (k keepRule) kept(count int) int {
+	switch k.mode {
+	case keepHighest, keepLowest:
+		return k.n
+	case dropHighest, dropLowest:
+		return count - k.n
+	}
+	return count
+}
+
+func (k keepRule) String() string {
+	switch k.mode {
+	case keepHighest:
+		return fmt.Sprintf("kh%d", k.n)
+	case keepLowest:
+		return fmt.Sprintf("kl%d", k.n)
+	case dropHighest:
+		return fmt.Sprintf("dh%d", k.n)
+	case dropLowest:
+		return fmt.Sprintf("dl%d", k.n)
+	}
+	return ""
+}
+
+// rerollRule is a reroll modifier such as "r1" or "ro<3".
+type rerollRule struct {
+	when comparePoint
+	once bool
+}
+
+func (r rerollRule) String() string {
+	if r.once {
+		return "ro" + r.when.String()
+	}
+	return "r" + r.when.String()
+}
+
+// diceNode is a group of identical dice such as "2d6" or "4d6kh3", together
+// with its modifiers.
+type diceNode struct {
+	count   int
+	sides   int
+	keep    keepRule
+	explode *comparePoint // explode when the face matches
+	reroll  *rerollRule
+	minimum int // 0 when unset
+	maximum int // 0 when unset
+	crit    CritMode
+	success *comparePoint // count dice meeting this target instead of summing
+	failure *comparePoint // count dice meeting this target as failures
+}
+
+func (n *diceNode) eval(r *Roller, groups *[]GroupResult) int {
+	var dice []Die
+	explosions := 0
+	for i := 0; i < n.count; i++ {
+		d := n.rollOne(r, false)
+		dice = append(dice, d)
+		for n.explode != nil && n.explode.matches(d.face()) && explosions < MaxExplosions {
+			dice[len(dice)-1].Exploded = true
+			d = n.rollOne(r, true)
+			dice = append(dice, d)
+			explosions++
+		}
+	}
+	n.applyKeep(dice)
+
+	if n.crit == CritMaxPlusRoll {
+		for i := 0; i < n.keep.kept(n.count); i++ {
+			high := n.highFace()
+			dice = append(dice, Die{Value: high, History: []int{high}, Maximized: true})
+		}
+	}
+
+	result := GroupResult{
+		Notation:        n.String(),
+		Sides:           n.sides,
+		Dice:            dice,
+		Crit:            n.crit,
+		Pool:            n.isPool(),
+		countsSuccesses: n.success != nil,
+		countsFailures:  n.failure != nil,
+	}
+	for i, d := range dice {
+		if d.Dropped {
+			result.Dropped = append(result.Dropped, d.Value)
+			continue
+		}
+		result.Rolls = append(result.Rolls, d.Value)
+		if !result.Pool {
+			result.Value += d.Value
+			continue
+		}
+		dice[i].Success = n.success != nil && n.success.matches(d.Value)
+		dice[i].Failure = n.failure != nil && n.failure.matches(d.Value)
+		if dice[i].Success {
+			result.Successes++
+		}
+		if dice[i].Failure {
+			result.Failures++
+		}
+		result.Value += n.score(d.Value)
+	}
+	if n.crit == CritDoubleTotal {
+		result.Value *= 2
+	}
+	*groups = append(*groups, result)
+	return result.Value
+}
+
+// rollOne rolls a single die, applying rerolls and clamping.
+func (n *diceNode) rollOne(r *Roller, explosion bool) Die {
+	face := r.Die(n.sides)
+	d := Die{History: []int{face}, Explosion: explosion}
+	if n.reroll != nil {
+		for tries := 0; n.reroll.when.matches(face) && tries < MaxRerolls; tries++ {
+			face = r.Die(n.sides)
+			d.History = append(d.History, face)
+			d.Rerolled = true
+			if n.reroll.once {
+				break
+			}
+		}
+	}
+	d.Value = n.clamp(face)
+	d.Clamped = d.Value != face
+	return d
+}
+
+// clamp applies the group's min/max modifiers to a face.
+func (n *diceNode) clamp(face int) int {
+	if n.minimum > 0 && face < n.minimum {
+		return n.minimum
+	}
+	if n.maximum > 0 && face > n.maximum {
+		return n.maximum
+	}
+	return face
+}
+
+// applyKeep marks the dice discarded by the group's keep/drop modifier.
+func (n *diceNode) applyKeep(dice []Die) {
+	if n.keep.mode == keepAll {
+		return
+	}
+	// Rank dice by value; ties are broken by roll order so results are stable.
+	order := make([]int, len(dice))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return dice[order[a]].Value < dice[order[b]].Value })
+
+	numDropped := len(dice) - n.keep.kept(len(dice))
+	if n.keep.mode == keepLowest || n.keep.mode == dropHighest {
+		order = order[len(order)-numDropped:]
+	} else {
+		order = order[:numDropped]
+	}
+	for _, i := range order {
+		dice[i].Dropped = true
+	}
+}
+
+// highFace returns the highest value a single die can show.
+func (n *diceNode) highFace() int {
+	if n.maximum > 0 {
+		return n.maximum
+	}
+	return n.sides
+}
+
+func (n *diceNode) bounds() (int, int) {
+	lowFace := 1
+	if n.minimum > 0 {
+		lowFace = n.minimum
+	}
+	mostDice := n.count
+	if n.explode != nil {
+		mostDice += MaxExplosions
+	}
+	lo, hi := n.keep.kept(n.count)*lowFace, n.keep.kept(mostDice)*n.highFace()
+	if n.isPool() {
+		lowScore, highScore := n.scoreBounds()
+		lo, hi = n.keep.kept(mostDice)*lowScore, n.keep.kept(mostDice)*highScore
+	}
+	switch n.crit {
+	case CritMaxPlusRoll:
+		bonus := n.keep.kept(n.count) * n.highFace()
+		lo, hi = lo+bonus, hi+bonus
+	case CritDoubleTotal:
+		lo, hi = lo*2, hi*2
+	}
+	return lo, hi
+}
+
+func (n *diceNode) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%dd%d", n.count, n.sides)
+	if n.explode != nil {
+		b.WriteString("!")
+		if n.explode.op != "=" || n.explode.value != n.sides {
+			b.WriteString(n.explode.String())
+		}
+	}
+	if n.reroll != nil {
+		b.WriteString(n.reroll.String())
+	}
+	if n.minimum > 0 {
+		fmt.Fprintf(&b, "min%d", n.minimum)
+	}
+	if n.maximum > 0 {
+		fmt.Fprintf(&b, "max%d", n.maximum)
+	}
+	b.WriteString(n.keep.String())
+	if n.success != nil {
+		b.WriteString(n.success.op + strconv.Itoa(n.success.value))
+	}
+	if n.failure != nil {
+		b.WriteString("f" + n.failure.String())
+	}
+	return b.String()
+}
+
+// parseModifiers parses the modifiers that may follow a dice group, in any
+// order, each at most once:
+//
+//	kh N, k N    keep the highest N dice
+//	kl N         keep the lowest N dice
+//	dh N, dl N   drop the highest or lowest N dice
+//	! [cmp]      explode: roll another die when the face matches (default: max face)
+//	r cmp        reroll while the face matches
+//	ro cmp       reroll once if the face matches
+//	min N        treat faces below N as N
+//	max N        treat faces above N as N
+//	=N, >N, <N, >=N, <=N
+//	             count dice meeting the target as successes (a dice pool)
+//	f cmp        count dice matching cmp as failures
+//
+// where cmp is a number optionally preceded by =, <, >, <= or >=.
+func (p *parser) parseModifiers(dn *diceNode) error {
+	for {
+		start := p.pos
+		switch {
+		case p.consume("min"):
+			if dn.minimum > 0 {
+				return fmt.Errorf("duplicate min modifier at position %d", start+1)
+			}
+			v, err := p.parseModifierValue("min")
+			if err != nil {
+				return err
+			}
+			dn.minimum = v
+		case p.consume("max"):
+			if dn.maximum > 0 {
+				return fmt.Errorf("duplicate max modifier at position %d", start+1)
+			}
+			v, err := p.parseModifierValue("max")
+			if err != nil {
+				return err
+			}
+			dn.maximum = v
+		case p.consume("!"):
+			if dn.explode != nil {
+				return fmt.Errorf("duplicate explode modifier at position %d", start+1)
+			}
+			cmp := &comparePoint{op: "=", value: dn.sides}
+			if isDigit(p.peek()) || strings.ContainsRune("<>=", rune(p.peek())) {
+				c, err := p.parseCompare()
+				if err != nil {
+					return err
+				}
+				cmp = &c
+			}
+			dn.explode = cmp
+		case p.consume("ro"), p.consume("r"):
+			if dn.reroll != nil {
+				return fmt.Errorf("duplicate reroll modifier at position %d", start+1)
+			}
+			c, err := p.parseCompare()
+			if err != nil {
+				return err
+			}
+			dn.reroll = &rerollRule{when: c, once: strings.HasPrefix(p.input[start:], "ro")}
+		case p.peek() != 0 && strings.ContainsRune("<>=", rune(p.peek())):
+			if dn.success != nil {
+				return fmt.Errorf("duplicate success target at position %d", start+1)
+			}
+			c, err := p.parseCompare()
+			if err != nil {
+				return err
+			}
+			dn.success = &c
+		case p.consume("f"):
+			if dn.failure != nil {
+				return fmt.Errorf("duplicate failure modifier at position %d", start+1)
+			}
+			c, err := p.parseCompare()
+			if err != nil {
+				return err
+			}
+			dn.failure = &c
+		case p.consume("kh"), p.consume("kl"), p.consume("k"), p.consume("dh"), p.consume("dl"):
+			if dn.keep.mode != keepAll {
+				return fmt.Errorf("duplicate keep/drop modifier at position %d", start+1)
+			}
+			if err := p.parseKeep(dn, p.input[start:p.pos]); err != nil {
+				return err
+			}
+		default:
+			return dn.validate()
+		}
+	}
+}
+
+// parseKeep parses the count of a keep/drop modifier whose prefix has
+// already been consumed.
+func (p *parser) parseKeep(dn *diceNode, prefix string) error {
+	modes := map[string]keepMode{"kh": keepHighest, "k": keepHighest, "kl": keepLowest, "dh": dropHighest, "dl": dropLowest}
+	if !isDigit(p.peek()) {
+		return fmt.Errorf("missing count for keep/drop modifier at position %d", p.pos+1)
+	}
+	n, err := p.parseNumber()
+	if err != nil {
+		return err
+	}
+	dn.keep = keepRule{mode: modes[prefix], n: n}
+	return nil
+}
+
+// parseModifierValue parses the number following a min or max modifier.
+func (p *parser) parseModifierValue(name string) (int, error) {
+	if !isDigit(p.peek()) {
+		return 0, fmt.Errorf("missing value for %s modifier at position %d", name, p.pos+1)
+	}
+	v, err := p.parseNumber()
+	if err != nil {
+		return 0, err
+	}
+	if v < 1 {
+		return 0, fmt.Errorf("%s modifier must be at least 1", name)
+	}
+	return v, nil
+}
+
+// parseCompare parses a compare point: a number optionally preceded by
+// =, <, >, <= or >=.
+func (p *parser) parseCompare() (comparePoint, error) {
+	op := "="
+	for _, candidate := range []string{"<=", ">=", "<", ">", "="} {
+		if p.consume(candidate) {
+			op = candidate
+			break
+		}
+	}
+	if !isDigit(p.peek()) {
+		return comparePoint{}, fmt.Errorf("missing comparison value at position %d", p.pos+1)
+	}
+	value, err := p.parseNumber()
+	if err != nil {
+		return comparePoint{}, err
+	}
+	return comparePoint{op: op, value: value}, nil
+}
+
+// validate checks that a group's modifiers are consistent with each other.
+func (n *diceNode) validate() error {
+	if kept := n.keep.kept(n.count); kept < 1 || kept > n.count {
+		return fmt.Errorf("%s must keep between 1 and %d dice", n, n.count)
+	}
+	if n.explode != nil && n.explode.matchesAll(n.sides) {
+		return fmt.Errorf("%s would explode on every roll", n)
+	}
+	if n.reroll != nil && n.reroll.when.matchesAll(n.sides) {
+		return fmt.Errorf("%s would reroll every face", n)
+	}
+	if n.minimum > n.sides || n.maximum > n.sides {
+		return fmt.Errorf("%s clamps beyond the die's %d sides", n, n.sides)
+	}
+	if n.maximum > 0 && n.minimum > n.maximum {
+		return fmt.Errorf("%s has min greater than max", n)
+	}
+	return nil
+}
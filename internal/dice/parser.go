@@ -0,0 +1,379 @@
+package dice
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// node is a single element of a parsed dice expression tree.
+type node interface {
+	// eval evaluates the node, appending the outcome of every dice group it
+	// contains to groups.
+	eval(r *Roller, groups *[]GroupResult) int
+	// bounds returns the smallest and largest values the node can produce.
+	bounds() (int, int)
+	String() string
+}
+
+// numberNode is a constant such as the "3" in "1d8+3".
+type numberNode struct {
+	value int
+}
+
+func (n *numberNode) eval(r *Roller, groups *[]GroupResult) int { return n.value }
+
+func (n *numberNode) bounds() (int, int) { return n.value, n.value }
+
+func (n *numberNode) String() string { return strconv.Itoa(n.value) }
+
+// parenNode wraps a parenthesised sub-expression.
+type parenNode struct {
+	inner node
+}
+
+func (n *parenNode) eval(r *Roller, groups *[]GroupResult) int { return n.inner.eval(r, groups) }
+
+func (n *parenNode) bounds() (int, int) { return n.inner.bounds() }
+
+func (n *parenNode) String() string { return "(" + n.inner.String() + ")" }
+
+// negNode is a unary minus.
+type negNode struct {
+	operand node
+}
+
+func (n *negNode) eval(r *Roller, groups *[]GroupResult) int { return -n.operand.eval(r, groups) }
+
+func (n *negNode) bounds() (int, int) {
+	lo, hi := n.operand.bounds()
+	return -hi, -lo
+}
+
+func (n *negNode) String() string { return "-" + n.operand.String() }
+
+// sumTerm is one signed operand of a sumNode.
+type sumTerm struct {
+	sign    int // +1 or -1
+	operand node
+}
+
+// sumNode is a chain of additions and subtractions.
+type sumNode struct {
+	terms []sumTerm
+}
+
+func (n *sumNode) eval(r *Roller, groups *[]GroupResult) int {
+	total := 0
+	for _, t := range n.terms {
+		total += t.sign * t.operand.eval(r, groups)
+	}
+	return total
+}
+
+func (n *sumNode) bounds() (int, int) {
+	lo, hi := 0, 0
+	for _, t := range n.terms {
+		tlo, thi := t.operand.bounds()
+		if t.sign < 0 {
+			tlo, thi = -thi, -tlo
+		}
+		lo += tlo
+		hi += thi
+	}
+	return lo, hi
+}
+
+func (n *sumNode) String() string {
+	var b strings.Builder
+	for i, t := range n.terms {
+		if t.sign < 0 {
+			b.WriteString("-")
+		} else if i > 0 {
+			b.WriteString("+")
+		}
+		b.WriteString(t.operand.String())
+	}
+	return b.String()
+}
+
+// productNode is a multiplication or floored division.
+type productNode struct {
+	op          byte // '*' or '/'
+	left, right node
+}
+
+func (n *productNode) eval(r *Roller, groups *[]GroupResult) int {
+	left := n.left.eval(r, groups)
+	right := n.right.eval(r, groups)
+	return applyOp(n.op, left, right)
+}
+
+func (n *productNode) bounds() (int, int) {
+	llo, lhi := n.left.bounds()
+	rlo, rhi := n.right.bounds()
+	corners := []int{
+		applyOp(n.op, llo, rlo),
+		applyOp(n.op, llo, rhi),
+		applyOp(n.op, lhi, rlo),
+		applyOp(n.op, lhi, rhi),
+	}
+	lo, hi := corners[0], corners[0]
+	for _, c := range corners[1:] {
+		lo = min(lo, c)
+		hi = max(hi, c)
+	}
+	return lo, hi
+}
+
+func (n *productNode) String() string {
+	return n.left.String() + string(n.op) + n.right.String()
+}
+
+// applyOp multiplies or floor-divides two values. The parser guarantees the
+// divisor of a division can never be zero.
+func applyOp(op byte, a, b int) int {
+	if op == '*' {
+		return a * b
+	}
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
+// parser is a recursive-descent parser for dice expressions:
+//
+//	expr    := term (('+' | '-') term)*
+//	term    := factor (('*' | '/') factor)*
+//	factor  := ('+' | '-') factor | primary
+//	primary := '(' expr ')' | dice | number
+//	dice    := [number] 'd' (number | '%') modifier*
+//
+// The dice group modifiers are described in group.go.
+type parser struct {
+	input string
+	pos   int
+}
+
+// parseExpression parses a complete dice expression. Whitespace is ignored.
+func parseExpression(input string) (node, error) {
+	p := &parser{input: strings.Join(strings.Fields(strings.ToLower(input)), "")}
+	if p.input == "" {
+		return nil, fmt.Errorf("empty dice expression")
+	}
+	n, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.input[p.pos], p.pos+1)
+	}
+	return n, nil
+}
+
+func (p *parser) done() bool { return p.pos >= len(p.input) }
+
+func (p *parser) peek() byte {
+	if p.done() {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *parser) parseExpr() (node, error) {
+	first, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	terms := []sumTerm{{sign: 1, operand: first}}
+	for c := p.peek(); c == '+' || c == '-'; c = p.peek() {
+		p.pos++
+		operand, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		sign := 1
+		if c == '-' {
+			sign = -1
+		}
+		terms = append(terms, sumTerm{sign: sign, operand: operand})
+	}
+	if len(terms) == 1 {
+		return first, nil
+	}
+	return &sumNode{terms: terms}, nil
+}
+
+func (p *parser) parseTerm() (node, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+	for c := p.peek(); c == '*' || c == '/'; c = p.peek() {
+		p.pos++
+		right, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		if c == '/' {
+			if lo, hi := right.bounds(); lo <= 0 && hi >= 0 {
+				return nil, fmt.Errorf("divisor %s can be zero", right)
+			}
+		}
+		left = &productNode{op: c, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseFactor() (node, error) {
+	switch p.peek() {
+	case '+':
+		p.pos++
+		return p.parseFactor()
+	case '-':
+		p.pos++
+		operand, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		return &negNode{operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	c := p.peek()
+	switch {
+	case c == 0:
+		return nil, fmt.Errorf("unexpected end of expression")
+	case c == '(':
+		p.pos++
+		inner, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ')' {
+			return nil, fmt.Errorf("missing closing parenthesis at position %d", p.pos+1)
+		}
+		p.pos++
+		return &parenNode{inner: inner}, nil
+	case c == 'd':
+		return p.parseDice(1)
+	case isDigit(c):
+		value, err := p.parseNumber()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() == 'd' {
+			return p.parseDice(value)
+		}
+		return &numberNode{value: value}, nil
+	}
+	return nil, fmt.Errorf("unexpected %q at position %d", c, p.pos+1)
+}
+
+// parseDice parses the "dM" part of a dice group whose count has already
+// been read.
+func (p *parser) parseDice(count int) (node, error) {
+	p.pos++ // consume 'd'
+	if count <= 0 {
+		return nil, fmt.Errorf("number of dice must be positive")
+	}
+	if count > MaxDice {
+		return nil, fmt.Errorf("cannot roll more than %d dice at once", MaxDice)
+	}
+	var sides int
+	if p.peek() == '%' {
+		p.pos++
+		sides = 100
+	} else {
+		if !isDigit(p.peek()) {
+			return nil, fmt.Errorf("missing die type at position %d", p.pos+1)
+		}
+		var err error
+		sides, err = p.parseNumber()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if sides <= 0 {
+		return nil, fmt.Errorf("die type must be positive")
+	}
+	if sides > MaxSides {
+		return nil, fmt.Errorf("die type cannot exceed %d", MaxSides)
+	}
+	dn := &diceNode{count: count, sides: sides}
+	if err := p.parseModifiers(dn); err != nil {
+		return nil, err
+	}
+	return dn, nil
+}
+
+// consume advances past prefix if the remaining input starts with it.
+func (p *parser) consume(prefix string) bool {
+	if strings.HasPrefix(p.input[p.pos:], prefix) {
+		p.pos += len(prefix)
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseNumber() (int, error) {
+	start := p.pos
+	for isDigit(p.peek()) {
+		p.pos++
+	}
+	value, err := strconv.Atoi(p.input[start:p.pos])
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q: %w", p.input[start:p.pos], err)
+	}
+	return value, nil
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+// transformDice returns a copy of the expression in which every dice group
+// has been replaced by the result of f. Groups are visited left to right.
+func transformDice(n node, f func(*diceNode) node) node {
+	switch n := n.(type) {
+	case *diceNode:
+		return f(n)
+	case *parenNode:
+		return &parenNode{inner: transformDice(n.inner, f)}
+	case *negNode:
+		return &negNode{operand: transformDice(n.operand, f)}
+	case *productNode:
+		left := transformDice(n.left, f)
+		return &productNode{op: n.op, left: left, right: transformDice(n.right, f)}
+	case *sumNode:
+		terms := make([]sumTerm, len(n.terms))
+		for i, t := range n.terms {
+			terms[i] = sumTerm{sign: t.sign, operand: transformDice(t.operand, f)}
+		}
+		return &sumNode{terms: terms}
+	}
+	return n
+}
+
+// containsDice reports whether the expression rolls at least one die.
+func containsDice(n node) bool {
+	switch n := n.(type) {
+	case *diceNode:
+		return true
+	case *parenNode:
+		return containsDice(n.inner)
+	case *negNode:
+		return containsDice(n.operand)
+	case *productNode:
+		return containsDice(n.left) || containsDice(n.right)
+	case *sumNode:
+		for _, t := range n.terms {
+			if containsDice(t.operand) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package dice
+
+// A dice group with a success or failure target is a dice pool: instead of
+// summing its dice it counts them. "10d6>=5" counts the dice showing 5 or
+// more, "8d10f1" counts the dice showing 1, and "6d10>=8f1" counts successes
+// less failures.
+
+// isPool reports whether the group counts successes or failures.
+func (n *diceNode) isPool() bool { return n.success != nil || n.failure != nil }
+
+// score returns what a kept die showing value contributes to a pool: +1 for
+// a success and -1 for a failure, or +1 per failure when the pool only
+// counts failures.
+func (n *diceNode) score(value int) int {
+	if n.success == nil {
+		if n.failure.matches(value) {
+			return 1
+		}
+		return 0
+	}
+	score := 0
+	if n.success.matches(value) {
+		score++
+	}
+	if n.failure != nil && n.failure.matches(value) {
+		score--
+	}
+	return score
+}
+
+// scoreBounds returns the smallest and largest score of a single die.
+func (n *diceNode) scoreBounds() (int, int) {
+	if n.success != nil && n.failure != nil {
+		return -1, 1
+	}
+	return 0, 1
+}
+
+// faceValue returns what a die showing face contributes to the group before
+// keep/drop: its clamped value, or its score in a dice pool.
+func (n *diceNode) faceValue(face int) int {
+	if n.isPool() {
+		return n.score(n.clamp(face))
+	}
+	return n.clamp(face)
+}
@@ -0,0 +1,57 @@
+package dice
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Roller is a source of random die rolls. Wrapping a rand.Source lets
+// callers inject a seeded source so that rolls can be reproduced. A Roller
+// is safe for concurrent use.
+type Roller struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// NewRoller returns a Roller drawing from src.
+func NewRoller(src rand.Source) *Roller {
+	return &Roller{rng: rand.New(src)}
+}
+
+// NewSeededRoller returns a Roller whose rolls are fully determined by seed.
+func NewSeededRoller(seed int64) *Roller {
+	return NewRoller(rand.NewSource(seed))
+}
+
+// Intn returns a random number in [0, n).
+func (r *Roller) Intn(n int) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rng.Intn(n)
+}
+
+// Die rolls a single die with the given number of sides, returning [1, sides].
+func (r *Roller) Die(sides int) int {
+	return r.Intn(sides) + 1
+}
+
+var (
+	defaultMu     sync.RWMutex
+	defaultRoller = NewSeededRoller(time.Now().UnixNano())
+)
+
+// Default returns the Roller used by DiceRoll.Roll and other callers that do
+// not inject their own.
+func Default() *Roller {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultRoller
+}
+
+// SetDefault replaces the default Roller, e.g. with a seeded one.
+func SetDefault(r *Roller) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultRoller = r
+}
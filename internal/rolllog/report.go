@@ -0,0 +1,256 @@
+package rolllog
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Report limits and thresholds.
+const (
+	MaxReportSides   = 100  // larger dice are too sparse to test face by face
+	MinExpected      = 5.0  // expected count per face needed for a meaningful test
+	SuspiciousPValue = 0.05 // below this a die looks suspicious
+	CursedPValue     = 0.01 // below this a die looks cursed
+)
+
+// FaceCount compares how often a face came up with how often it should have.
+type FaceCount struct {
+	Face     int     `json:"face"`
+	Observed int     `json:"observed"`
+	Expected float64 `json:"expected"`
+}
+
+// DieReport is the fairness analysis of every die of one type in the log.
+type DieReport struct {
+	Sides            int         `json:"sides"`
+	Rolls            int         `json:"rolls"`
+	Mean             float64     `json:"mean"`
+	ExpectedMean     float64     `json:"expected_mean"`
+	Faces            []FaceCount `json:"faces"`
+	ChiSquared       float64     `json:"chi_squared"`
+	DegreesOfFreedom int         `json:"degrees_of_freedom"`
+	PValue           float64     `json:"p_value"`
+	Verdict          string      `json:"verdict"`
+}
+
+// CharacterLuck summarises the dice rolled for one character. Luck is the
+// average of each die's position between its lowest and highest face, less
+// the expected 50%, so +5 means the dice ran 5 points hot.
+type CharacterLuck struct {
+	Character string  `json:"character"`
+	Rolls     int     `json:"rolls"`
+	Dice      int     `json:"dice"`
+	Nat20s    int     `json:"nat20s"`
+	Nat1s     int     `json:"nat1s"`
+	Luck      float64 `json:"luck"`
+}
+
+// Report aggregates a roll log.
+type Report struct {
+	Rolls      int             `json:"rolls"`
+	Nat20s     int             `json:"nat20s"`
+	Nat1s      int             `json:"nat1s"`
+	Dice       []DieReport     `json:"dice"`
+	Characters []CharacterLuck `json:"characters"`
+}
+
+// NoCharacter names the rolls that were not made for a character.
+const NoCharacter = "(none)"
+
+// BuildReport aggregates entries by die type and by character. Every face
+// rolled counts towards the fairness test, including rerolled and dropped
+// dice. Natural 20s and 1s count only the deciding d20 of each roll, the
+// one dice.Check flags, so 2d20 or a pool of d20s has none; entries logged
+// before the deciding face was recorded count none either.
+func BuildReport(entries []Entry) Report {
+	report := Report{Rolls: len(entries), Dice: []DieReport{}, Characters: []CharacterLuck{}}
+	faces := make(map[int]map[int]int) // sides -> face -> count
+	luck := make(map[string]*CharacterLuck)
+	luckSums := make(map[string]float64)
+
+	for _, e := range entries {
+		name := e.Character
+		if name == "" {
+			name = NoCharacter
+		}
+		c := luck[name]
+		if c == nil {
+			c = &CharacterLuck{Character: name}
+			luck[name] = c
+		}
+		c.Rolls++
+
+		for _, d := range e.Dice {
+			if d.Sides <= MaxReportSides {
+				if faces[d.Sides] == nil {
+					faces[d.Sides] = make(map[int]int)
+				}
+				for _, f := range d.Faces {
+					faces[d.Sides][f]++
+				}
+			}
+			if d.Sides > 1 && len(d.Faces) > 0 {
+				final := d.Faces[len(d.Faces)-1]
+				c.Dice++
+				luckSums[name] += float64(final-1) / float64(d.Sides-1)
+			}
+		}
+		switch e.Natural {
+		case 20:
+			c.Nat20s++
+			report.Nat20s++
+		case 1:
+			c.Nat1s++
+			report.Nat1s++
+		}
+	}
+
+	for sides, counts := range faces {
+		report.Dice = append(report.Dice, dieReport(sides, counts))
+	}
+	sort.Slice(report.Dice, func(i, j int) bool { return report.Dice[i].Sides < report.Dice[j].Sides })
+
+	for name, c := range luck {
+		if c.Dice > 0 {
+			c.Luck = (luckSums[name]/float64(c.Dice) - 0.5) * 100
+		}
+		report.Characters = append(report.Characters, *c)
+	}
+	sort.SliceStable(report.Characters, func(i, j int) bool {
+		a, b := report.Characters[i], report.Characters[j]
+		if a.Luck != b.Luck {
+			return a.Luck > b.Luck
+		}
+		return a.Character < b.Character
+	})
+	return report
+}
+
+// dieReport runs a chi-squared goodness-of-fit test of counts against a
+// fair die with the given sides.
+func dieReport(sides int, counts map[int]int) DieReport {
+	r := DieReport{Sides: sides, ExpectedMean: float64(sides+1) / 2}
+	for _, n := range counts {
+		r.Rolls += n
+	}
+	expected := float64(r.Rolls) / float64(sides)
+	sum := 0
+	for f := 1; f <= sides; f++ {
+		observed := counts[f]
+		sum += f * observed
+		r.Faces = append(r.Faces, FaceCount{Face: f, Observed: observed, Expected: expected})
+		diff := float64(observed) - expected
+		r.ChiSquared += diff * diff / expected
+	}
+	r.Mean = float64(sum) / float64(r.Rolls)
+	r.DegreesOfFreedom = sides - 1
+
+	switch {
+	case sides == 1:
+		r.PValue, r.Verdict = 1, "fair"
+	case expected < MinExpected:
+		r.PValue = chiSquaredPValue(r.ChiSquared, r.DegreesOfFreedom)
+		r.Verdict = "not enough rolls"
+	default:
+		r.PValue = chiSquaredPValue(r.ChiSquared, r.DegreesOfFreedom)
+		switch {
+		case r.PValue < CursedPValue:
+			r.Verdict = "cursed"
+		case r.PValue < SuspiciousPValue:
+			r.Verdict = "suspicious"
+		default:
+			r.Verdict = "fair"
+		}
+	}
+	return r
+}
+
+// chiSquaredPValue returns the probability that a chi-squared variable with
+// df degrees of freedom is at least x.
+func chiSquaredPValue(x float64, df int) float64 {
+	if x <= 0 {
+		return 1
+	}
+	return upperGamma(float64(df)/2, x/2)
+}
+
+// upperGamma returns the regularized upper incomplete gamma function Q(a, x),
+// using a series expansion for small x and a continued fraction otherwise.
+func upperGamma(a, x float64) float64 {
+	const (
+		maxIterations = 500
+		epsilon       = 1e-14
+		tiny          = 1e-300
+	)
+	lgA, _ := math.Lgamma(a)
+	prefix := math.Exp(-x + a*math.Log(x) - lgA)
+
+	if x < a+1 {
+		// P(a, x) = prefix * sum x^n / (a (a+1) ... (a+n))
+		term := 1 / a
+		sum := term
+		for n := 1; n < maxIterations; n++ {
+			term *= x / (a + float64(n))
+			sum += term
+			if math.Abs(term) < math.Abs(sum)*epsilon {
+				break
+			}
+		}
+		return math.Max(0, 1-prefix*sum)
+	}
+
+	// Lentz's method for the continued fraction of Q(a, x).
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for i := 1; i < maxIterations; i++ {
+		an := -float64(i) * (float64(i) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < epsilon {
+			break
+		}
+	}
+	return prefix * h
+}
+
+// String renders the report as plain-text tables.
+func (r Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Rolls: %d  Natural 20s: %d  Natural 1s: %d\n", r.Rolls, r.Nat20s, r.Nat1s)
+
+	for _, d := range r.Dice {
+		fmt.Fprintf(&b, "\nd%d: %d rolled, mean %.2f (expected %.2f), χ² = %.2f (df %d), p = %.3f: %s\n",
+			d.Sides, d.Rolls, d.Mean, d.ExpectedMean, d.ChiSquared, d.DegreesOfFreedom, d.PValue, d.Verdict)
+		fmt.Fprintf(&b, "  %4s  %8s  %8s  %7s\n", "Face", "Observed", "Expected", "Diff")
+		for _, f := range d.Faces {
+			diff := 0.0
+			if f.Expected > 0 {
+				diff = (float64(f.Observed)/f.Expected - 1) * 100
+			}
+			fmt.Fprintf(&b, "  %4d  %8d  %8.1f  %+6.1f%%\n", f.Face, f.Observed, f.Expected, diff)
+		}
+	}
+
+	if len(r.Characters) > 0 {
+		fmt.Fprintf(&b, "\nLuck by character:\n")
+		fmt.Fprintf(&b, "  %-20s  %5s  %5s  %5s  %5s  %6s\n", "Character", "Rolls", "Dice", "Nat20", "Nat1", "Luck")
+		for _, c := range r.Characters {
+			fmt.Fprintf(&b, "  %-20s  %5d  %5d  %5d  %5d  %+5.1f%%\n", c.Character, c.Rolls, c.Dice, c.Nat20s, c.Nat1s, c.Luck)
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
@@ -0,0 +1,288 @@
+// Package rolllog keeps a persistent, append-only log of dice rolls made
+// from the CLI and the TUI.
+package rolllog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"dnd-cli/internal/dice"
+)
+
+// SessionGap is how long the table may go without a roll before the next
+// roll starts a new session.
+const SessionGap = 4 * time.Hour
+
+// Die is a single die recorded in the log.
+type Die struct {
+	Sides   int   `json:"sides"`
+	Faces   []int `json:"faces"` // every face rolled, including rerolls
+	Value   int   `json:"value"`
+	Dropped bool  `json:"dropped,omitempty"`
+}
+
+// Entry is one logged roll.
+type Entry struct {
+	Time      time.Time `json:"time"`
+	Session   int       `json:"session"`
+	Notation  string    `json:"notation"`
+	Dice      []Die     `json:"dice"`
+	Total     int       `json:"total"`
+	Natural   int       `json:"natural,omitempty"` // face of the deciding d20, as dice.Check sees it
+	Label     string    `json:"label,omitempty"`
+	Character string    `json:"character,omitempty"`
+	Source    string    `json:"source,omitempty"` // "cli" or "tui"
+}
+
+// NewEntry records the outcome of rolling dr.
+func NewEntry(dr *dice.DiceRoll, total int, terms []dice.TermResult) Entry {
+	e := Entry{Time: time.Now(), Notation: dr.Notation, Total: total, Natural: dr.Check(total, terms).Natural}
+	for _, t := range terms {
+		for _, g := range t.Groups {
+			for _, d := range g.Dice {
+				if d.Maximized {
+					continue // not a rolled die
+				}
+				e.Dice = append(e.Dice, Die{Sides: g.Sides, Faces: d.History, Value: d.Value, Dropped: d.Dropped})
+			}
+		}
+	}
+	return e
+}
+
+// DiceString renders the entry's dice as e.g. "[14, 3] dropped [2]".
+func (e Entry) DiceString() string {
+	var kept, dropped []string
+	for _, d := range e.Dice {
+		if d.Dropped {
+			dropped = append(dropped, strconv.Itoa(d.Value))
+		} else {
+			kept = append(kept, strconv.Itoa(d.Value))
+		}
+	}
+	s := "[" + strings.Join(kept, ", ") + "]"
+	if len(dropped) > 0 {
+		s += " dropped [" + strings.Join(dropped, ", ") + "]"
+	}
+	return s
+}
+
+// String renders the entry as a single history line.
+func (e Entry) String() string {
+	s := fmt.Sprintf("%s  #%d  %s %s = %d", e.Time.Local().Format("2006-01-02 15:04"), e.Session, e.Notation, e.DiceString(), e.Total)
+	var who []string
+	if e.Character != "" {
+		who = append(who, e.Character)
+	}
+	if e.Label != "" {
+		who = append(who, e.Label)
+	}
+	if len(who) > 0 {
+		s += "  (" + strings.Join(who, ", ") + ")"
+	}
+	return s
+}
+
+// DefaultPath returns the standard location of the roll log.
+func DefaultPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".dnd-cli", "rolls.jsonl"), nil
+}
+
+// Append adds e to the log at path, creating it if needed. The entry joins
+// the session of the previous roll unless SessionGap has passed since.
+func Append(path string, e Entry) error {
+	last, ok, err := lastEntry(path)
+	if err != nil {
+		return err
+	}
+	e.Session = 1
+	if ok {
+		e.Session = last.Session
+		if e.Time.Sub(last.Time) > SessionGap {
+			e.Session++
+		}
+	}
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to marshal roll: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create roll log directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open roll log: %w", err)
+	}
+	defer file.Close()
+	// A line cut short, say by a crash, must not swallow this one.
+	if info, err := file.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := file.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+			line = append([]byte{'\n'}, line...)
+		}
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write roll log: %w", err)
+	}
+	return nil
+}
+
+// tailChunk is how much of the log lastEntry reads at a time.
+const tailChunk = 4096
+
+// lastEntry returns the most recent entry of the log at path, skipping
+// lines that don't parse. It reads the log backwards from its end, so
+// appending to a long log stays cheap. ok is false if the log has no
+// entries.
+func lastEntry(path string) (Entry, bool, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return Entry{}, false, nil
+	}
+	if err != nil {
+		return Entry{}, false, fmt.Errorf("failed to open roll log: %w", err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return Entry{}, false, fmt.Errorf("failed to read roll log: %w", err)
+	}
+
+	var tail []byte // the unread end of the log, starting with a partial line
+	for end := info.Size(); end > 0; {
+		n := min(int64(tailChunk), end)
+		end -= n
+		chunk := make([]byte, n, n+int64(len(tail)))
+		if _, err := file.ReadAt(chunk, end); err != nil {
+			return Entry{}, false, fmt.Errorf("failed to read roll log: %w", err)
+		}
+		tail = append(chunk, tail...)
+		for {
+			i := bytes.LastIndexByte(tail, '\n')
+			if i < 0 && end > 0 {
+				break // the line starts before this chunk
+			}
+			var e Entry
+			if line := bytes.TrimSpace(tail[i+1:]); len(line) > 0 && json.Unmarshal(line, &e) == nil {
+				return e, true, nil
+			}
+			if i < 0 {
+				break
+			}
+			tail = tail[:i]
+		}
+	}
+	return Entry{}, false, nil
+}
+
+// LineError is a line of the log that doesn't parse.
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e LineError) Error() string { return fmt.Sprintf("roll log line %d: %v", e.Line, e.Err) }
+
+// Load reads every entry of the log at path, oldest first. A missing log
+// has no entries. Lines that don't parse, such as one cut short by a
+// crash, are skipped and returned as bad.
+func Load(path string) (entries []Entry, bad []LineError, err error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open roll log: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			bad = append(bad, LineError{lineNo, err})
+			continue
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read roll log: %w", err)
+	}
+	return entries, bad, nil
+}
+
+// Filter selects entries from a log. Zero fields match everything.
+type Filter struct {
+	Session   int       // only this session
+	Since     time.Time // only entries at or after this time
+	Until     time.Time // only entries before this time
+	Character string    // only this character (case-insensitive)
+	Last      int       // only the most recent entries that match
+}
+
+// Apply returns the entries matching f, oldest first.
+func (f Filter) Apply(entries []Entry) []Entry {
+	var matched []Entry
+	for _, e := range entries {
+		switch {
+		case f.Session != 0 && e.Session != f.Session:
+		case !f.Since.IsZero() && e.Time.Before(f.Since):
+		case !f.Until.IsZero() && !e.Time.Before(f.Until):
+		case f.Character != "" && !strings.EqualFold(e.Character, f.Character):
+		default:
+			matched = append(matched, e)
+		}
+	}
+	if f.Last > 0 && len(matched) > f.Last {
+		matched = matched[len(matched)-f.Last:]
+	}
+	return matched
+}
+
+// ParseSession reads a session filter: a session number, or "last" (or
+// "current") for the session of the most recent of entries.
+func ParseSession(s string, entries []Entry) (int, error) {
+	switch s {
+	case "last", "current":
+		return LatestSession(entries), nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid session %q; use a session number or 'last'", s)
+	}
+	return n, nil
+}
+
+// ParseDay returns the start of the local day date, given as YYYY-MM-DD,
+// and the start of the next.
+func ParseDay(date string) (since, until time.Time, err error) {
+	day, err := time.ParseInLocation("2006-01-02", date, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid date %q; use YYYY-MM-DD", date)
+	}
+	return day, day.AddDate(0, 0, 1), nil
+}
+
+// LatestSession returns the session number of the most recent entry, or 0.
+func LatestSession(entries []Entry) int {
+	if len(entries) == 0 {
+		return 0
+	}
+	return entries[len(entries)-1].Session
+}
@@ -0,0 +1,243 @@
+package rolllog
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"dnd-cli/internal/dice"
+)
+
+func TestAppendLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rolls.jsonl")
+	start := time.Date(2025, 6, 14, 19, 0, 0, 0, time.UTC)
+
+	dr := dice.MustParseDiceNotation("4d6kh3+2")
+	total, terms := dr.RollWith(dice.NewSeededRoller(1))
+	first := NewEntry(dr, total, terms)
+	first.Time, first.Character = start, "Thorin"
+	if len(first.Dice) != 4 {
+		t.Fatalf("NewEntry() recorded %d dice, want 4", len(first.Dice))
+	}
+
+	second := first
+	second.Time, second.Character = start.Add(time.Hour), "Mira"
+	third := first
+	third.Time = start.Add(time.Hour + SessionGap + time.Minute)
+	for _, e := range []Entry{first, second, third} {
+		if err := Append(path, e); err != nil {
+			t.Fatalf("Append() failed: %v", err)
+		}
+	}
+
+	entries, bad, err := Load(path)
+	if err != nil || len(bad) != 0 {
+		t.Fatalf("Load() failed: %v, bad lines %v", err, bad)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("Load() returned %d entries, want 3", len(entries))
+	}
+	if got := []int{entries[0].Session, entries[1].Session, entries[2].Session}; got[0] != 1 || got[1] != 1 || got[2] != 2 {
+		t.Errorf("sessions = %v, want [1 1 2]", got)
+	}
+	if entries[0].Total != total || entries[0].Notation != "4d6kh3+2" {
+		t.Errorf("Load() entry = %+v, want total %d of 4d6kh3+2", entries[0], total)
+	}
+
+	if got := (Filter{Session: 1}).Apply(entries); len(got) != 2 {
+		t.Errorf("Filter{Session: 1} matched %d entries, want 2", len(got))
+	}
+	if got := (Filter{Character: "thorin"}).Apply(entries); len(got) != 2 {
+		t.Errorf("Filter{Character: thorin} matched %d entries, want 2", len(got))
+	}
+	day := Filter{Since: start, Until: start.Add(2 * time.Hour)}
+	if got := day.Apply(entries); len(got) != 2 {
+		t.Errorf("Filter{Since, Until} matched %d entries, want 2", len(got))
+	}
+	if got := (Filter{Last: 1}).Apply(entries); len(got) != 1 || got[0].Session != 2 {
+		t.Errorf("Filter{Last: 1} = %+v, want the latest entry", got)
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	entries, _, err := Load(filepath.Join(t.TempDir(), "none.jsonl"))
+	if err != nil || len(entries) != 0 {
+		t.Errorf("Load() of a missing log = %v, %v; want no entries", entries, err)
+	}
+}
+
+func TestMalformedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rolls.jsonl")
+	start := time.Date(2025, 6, 14, 19, 0, 0, 0, time.UTC)
+	// A label longer than tailChunk makes the last good line span chunks.
+	long := Entry{Time: start, Session: 3, Notation: "1d20", Total: 12, Label: strings.Repeat("x", 3*tailChunk)}
+	line, err := json.Marshal(long)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := `{"time":"2025-06-14T18:00:00Z","session":3,"notation":"1d6","total":4}
+not json
+` + string(line) + "\n\n" + `{"time":"2025-06-14T19:05:00Z","sess`
+	if err := os.WriteFile(path, []byte(log), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, bad, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if len(entries) != 2 || entries[1].Label != long.Label {
+		t.Errorf("Load() returned %d entries, want the 2 that parse", len(entries))
+	}
+	if len(bad) != 2 || bad[0].Line != 2 || bad[1].Line != 5 {
+		t.Errorf("Load() bad lines = %v, want lines 2 and 5", bad)
+	}
+
+	next := Entry{Time: start.Add(time.Minute), Notation: "1d4", Total: 2}
+	if err := Append(path, next); err != nil {
+		t.Fatalf("Append() failed: %v", err)
+	}
+	entries, _, _ = Load(path)
+	if got := entries[len(entries)-1]; got.Notation != "1d4" || got.Session != 3 {
+		t.Errorf("Append() after a cut-off line = %+v, want session 3", got)
+	}
+}
+
+func TestChiSquaredPValue(t *testing.T) {
+	tests := []struct {
+		x      float64
+		df     int
+		expect float64
+	}{
+		{x: 3.841459, df: 1, expect: 0.05},
+		{x: 11.070498, df: 5, expect: 0.05},
+		{x: 36.190869, df: 19, expect: 0.01},
+		{x: 2, df: 2, expect: math.Exp(-1)},
+		{x: 0, df: 5, expect: 1},
+	}
+	for _, tt := range tests {
+		if got := chiSquaredPValue(tt.x, tt.df); math.Abs(got-tt.expect) > 1e-5 {
+			t.Errorf("chiSquaredPValue(%v, %d) = %v, want %v", tt.x, tt.df, got, tt.expect)
+		}
+	}
+}
+
+func TestBuildReport(t *testing.T) {
+	// A d6 that rolled each face 10 times, and a d20 that only rolls 20s.
+	var fair, loaded []Die
+	for i := 0; i < 60; i++ {
+		fair = append(fair, Die{Sides: 6, Faces: []int{i%6 + 1}, Value: i%6 + 1})
+	}
+	for i := 0; i < 40; i++ {
+		loaded = append(loaded, Die{Sides: 20, Faces: []int{20}, Value: 20})
+	}
+	loaded = append(loaded, Die{Sides: 20, Faces: []int{1}, Value: 1, Dropped: true})
+	report := BuildReport([]Entry{
+		{Character: "Mira", Dice: fair},
+		{Character: "Thorin", Dice: loaded},
+	})
+
+	if report.Rolls != 2 || report.Nat20s != 0 || report.Nat1s != 0 {
+		t.Errorf("BuildReport() = %d rolls, %d nat 20s, %d nat 1s; want 2, 0, 0", report.Rolls, report.Nat20s, report.Nat1s)
+	}
+	if len(report.Dice) != 2 || report.Dice[0].Sides != 6 || report.Dice[1].Sides != 20 {
+		t.Fatalf("BuildReport() dice = %+v, want d6 and d20", report.Dice)
+	}
+	if d6 := report.Dice[0]; d6.ChiSquared != 0 || d6.Verdict != "fair" {
+		t.Errorf("d6 report = %+v, want a fair die", d6)
+	}
+	if d20 := report.Dice[1]; d20.Verdict != "not enough rolls" || d20.Rolls != 41 {
+		t.Errorf("d20 report = %+v, want 41 rolls without a verdict", d20)
+	}
+	if len(report.Characters) != 2 || report.Characters[0].Character != "Thorin" || report.Characters[1].Luck != 0 {
+		t.Errorf("BuildReport() characters = %+v, want Thorin luckiest and Mira even", report.Characters)
+	}
+
+	// Only the deciding d20 of a single-d20 roll is a natural 20 or 1.
+	var rolls []Entry
+	for _, notation := range []string{"1d20+5", "2d20kh1", "2d20", "4d20kh1", "3d20>=10"} {
+		dr := dice.MustParseDiceNotation(notation)
+		for seed := int64(1); seed <= 200; seed++ {
+			total, terms := dr.RollWith(dice.NewSeededRoller(seed))
+			e := NewEntry(dr, total, terms)
+			if want := dr.Check(total, terms).Natural; e.Natural != want {
+				t.Fatalf("NewEntry(%s) natural = %d, want %d", notation, e.Natural, want)
+			}
+			rolls = append(rolls, e)
+		}
+	}
+	var nat20s, nat1s int
+	for _, e := range rolls {
+		if e.Notation == "2d20" || e.Notation == "3d20>=10" {
+			if e.Natural != 0 {
+				t.Errorf("%s entry has natural %d, want none", e.Notation, e.Natural)
+			}
+		}
+		switch e.Natural {
+		case 20:
+			nat20s++
+		case 1:
+			nat1s++
+		}
+	}
+	report = BuildReport(rolls)
+	if report.Nat20s != nat20s || report.Nat1s != nat1s || nat20s == 0 || nat1s == 0 {
+		t.Errorf("BuildReport() = %d nat 20s, %d nat 1s; want %d and %d", report.Nat20s, report.Nat1s, nat20s, nat1s)
+	}
+	double := []Die{{Sides: 20, Faces: []int{20}, Value: 20}, {Sides: 20, Faces: []int{20}, Value: 20}}
+	if report := BuildReport([]Entry{{Notation: "2d20", Dice: double, Total: 40}}); report.Nat20s != 0 {
+		t.Errorf("BuildReport() of 2d20 showing two 20s = %d nat 20s, want 0", report.Nat20s)
+	}
+
+	// Characters as lucky as each other are listed by name.
+	even := []Die{{Sides: 6, Faces: []int{1}, Value: 1}, {Sides: 6, Faces: []int{6}, Value: 6}}
+	report = BuildReport([]Entry{{Character: "Zed", Dice: even}, {Character: "Ash", Dice: even}, {Character: "Mira", Dice: even}})
+	var names []string
+	for _, c := range report.Characters {
+		names = append(names, c.Character)
+	}
+	if strings.Join(names, ",") != "Ash,Mira,Zed" {
+		t.Errorf("BuildReport() characters = %v, want Ash, Mira, Zed", names)
+	}
+
+	raw, err := json.Marshal(BuildReport(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"rolls":0,"nat20s":0,"nat1s":0,"dice":[],"characters":[]}`; string(raw) != want {
+		t.Errorf("empty report = %s, want %s", raw, want)
+	}
+}
+
+func TestParseSessionAndDay(t *testing.T) {
+	entries := []Entry{{Session: 1}, {Session: 3}}
+	tests := []struct {
+		session   string
+		expect    int
+		expectErr bool
+	}{
+		{session: "2", expect: 2},
+		{session: "last", expect: 3},
+		{session: "current", expect: 3},
+		{session: "0", expectErr: true},
+		{session: "first", expectErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSession(tt.session, entries)
+		if (err != nil) != tt.expectErr || got != tt.expect {
+			t.Errorf("ParseSession(%q) = %d, %v; want %d, error %v", tt.session, got, err, tt.expect, tt.expectErr)
+		}
+	}
+
+	since, until, err := ParseDay("2025-06-14")
+	if err != nil || since.Format("2006-01-02 15:04") != "2025-06-14 00:00" || until.Sub(since) != 24*time.Hour {
+		t.Errorf("ParseDay() = %v, %v, %v", since, until, err)
+	}
+	if _, _, err := ParseDay("14/06/2025"); err == nil {
+		t.Error("ParseDay() should reject a date not in YYYY-MM-DD")
+	}
+}
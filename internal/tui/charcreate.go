@@ -2,14 +2,13 @@ package tui
 
 import (
 	"fmt"
-	"math/rand"
 	"os"
-	"sort"
 	"strconv"
 	"strings"
 
 	"dnd-cli/internal/character"
 	"dnd-cli/internal/data"
+	"dnd-cli/internal/dice"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
@@ -17,6 +16,9 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 )
 
+// abilityScoreRoll rolls a single ability score: 4d6, dropping the lowest die.
+var abilityScoreRoll = dice.MustParseDiceNotation("4d6dl1")
+
 // charCreateModel handles the character creation mode.
 type charCreateModel struct {
 	step          int // 0: name, 1: alignment, 2: player, 3: level, 4: score method, 5: scores, 6: species, 7: species info, 8: class, 9: class info, 10: background, 11: background info, 12: proficiencies, 13: equipment, 14: spellcasting, 15: confirm
@@ -36,9 +38,11 @@ type charCreateModel struct {
 	list          list.Model
 	width         int
 	height        int
+	roller        *dice.Roller
+	store         *data.Store
 }
 
-func newCharCreateModel(width, height int) charCreateModel {
+func newCharCreateModel(width, height int, store *data.Store, roller *dice.Roller) charCreateModel {
 	ti := textinput.New()
 	ti.Placeholder = "Enter character name"
 	ti.Focus()
@@ -53,6 +57,8 @@ func newCharCreateModel(width, height int) charCreateModel {
 		proficiencies: []string{},
 		equipment:     []string{},
 		spells:        []string{},
+		roller:        roller,
+		store:         store,
 	}
 }
 
@@ -265,14 +271,7 @@ func (m *charCreateModel) generateScores() {
 		m.scores = [6]int{15, 14, 13, 12, 10, 8}
 	} else if m.scoreMethod == "Roll 4d6 Drop Lowest" {
 		for i := range m.scores {
-			rolls := make([]int, 4)
-			for j := range rolls {
-				rolls[j] = rand.Intn(6) + 1
-			}
-			// sort and drop lowest
-			sort.Ints(rolls)
-			sum := rolls[1] + rolls[2] + rolls[3]
-			m.scores[i] = sum
+			m.scores[i], _ = abilityScoreRoll.RollWith(m.roller)
 		}
 	}
 	// Validate scores are reasonable (3-18)
@@ -284,7 +283,7 @@ func (m *charCreateModel) generateScores() {
 }
 
 func (m *charCreateModel) setupSpeciesList() {
-	titles := getUniqueTitles(data.AllSpecies, func(s data.Species) string { return s.Name })
+	titles := getUniqueTitles(m.store.Species(), func(s data.Species) string { return s.Name })
 	items := createListItems(titles)
 	l := list.New(items, customDelegate{}, m.width, m.height-ListHeightPadding)
 	l.KeyMap.Quit = key.NewBinding(key.WithDisabled())
@@ -298,7 +297,7 @@ func (m *charCreateModel) setupSpeciesList() {
 }
 
 func (m *charCreateModel) setupClassList() {
-	titles := getUniqueTitles(data.AllClasses, func(c data.Class) string { return c.Name })
+	titles := getUniqueTitles(m.store.Classes(), func(c data.Class) string { return c.Name })
 	items := createListItems(titles)
 	l := list.New(items, customDelegate{}, m.width, m.height-ListHeightPadding)
 	l.KeyMap.Quit = key.NewBinding(key.WithDisabled())
@@ -312,7 +311,7 @@ func (m *charCreateModel) setupClassList() {
 }
 
 func (m *charCreateModel) setupBackgroundList() {
-	titles := getUniqueTitles(data.AllBackgrounds, func(b data.Background) string { return b.Name })
+	titles := getUniqueTitles(m.store.Backgrounds(), func(b data.Background) string { return b.Name })
 	items := createListItems(titles)
 	l := list.New(items, customDelegate{}, m.width, m.height-ListHeightPadding)
 	l.KeyMap.Quit = key.NewBinding(key.WithDisabled())
@@ -513,17 +512,20 @@ func (m *charCreateModel) applyBackgroundProficiencies() {
 	}
 }
 
-func getClassDescription(className string) string {
-	for _, c := range data.AllClasses {
+func getClassDescription(store *data.Store, className string) string {
+	for _, c := range store.Classes() {
 		if c.Name == className {
+			if len(c.Spells) > 0 {
+				return c.Description + "\n\nSpells: " + strings.Join(c.Spells, ", ")
+			}
 			return c.Description
 		}
 	}
 	return "Description not found."
 }
 
-func getSpeciesDescription(speciesName string) string {
-	for _, s := range data.AllSpecies {
+func getSpeciesDescription(store *data.Store, speciesName string) string {
+	for _, s := range store.Species() {
 		if s.Name == speciesName {
 			return s.Description
 		}
@@ -531,8 +533,8 @@ func getSpeciesDescription(speciesName string) string {
 	return "Description not found."
 }
 
-func getBackgroundDescription(backgroundName string) string {
-	for _, b := range data.AllBackgrounds {
+func getBackgroundDescription(store *data.Store, backgroundName string) string {
+	for _, b := range store.Backgrounds() {
 		if b.Name == backgroundName {
 			return b.Description
 		}
@@ -575,17 +577,17 @@ func (m charCreateModel) View() string {
 	case StepSpecies:
 		return viewStyle.Render(fmt.Sprintf("Character Creation - Select Species\n\n%s\n\nType / to search, ↑↓ or jk to navigate, Enter to select, Esc to go back.", m.list.View()))
 	case StepSpeciesInfo:
-		desc := getSpeciesDescription(m.species)
+		desc := getSpeciesDescription(m.store, m.species)
 		return viewStyle.Render(fmt.Sprintf("Character Creation - Species: %s\n\n%s\n\nRacial ASIs applied temporarily.\n\nPress Enter to continue, Esc to go back.", m.species, desc))
 	case StepClass:
 		return viewStyle.Render(fmt.Sprintf("Character Creation - Select Class\n\n%s\n\nType / to search, ↑↓ or jk to navigate, Enter to select, Esc to go back.", m.list.View()))
 	case StepClassInfo:
-		desc := getClassDescription(m.class)
+		desc := getClassDescription(m.store, m.class)
 		return viewStyle.Render(fmt.Sprintf("Character Creation - Class: %s\n\n%s\n\nPress Enter to continue, Esc to go back.", m.class, desc))
 	case StepBackground:
 		return viewStyle.Render(fmt.Sprintf("Character Creation - Select Background\n\n%s\n\nType / to search, ↑↓ or jk to navigate, Enter to select, Esc to go back.", m.list.View()))
 	case StepBackgroundInfo:
-		desc := getBackgroundDescription(m.background)
+		desc := getBackgroundDescription(m.store, m.background)
 		return viewStyle.Render(fmt.Sprintf("Character Creation - Background: %s\n\n%s\n\nBackground proficiencies applied.\n\nPress Enter to continue, Esc to go back.", m.background, desc))
 	case StepProficiencies:
 		profStr := strings.Join(m.proficiencies, ", ")
@@ -10,6 +10,8 @@ const (
 	TextInputCharLimit    = 156
 	TextInputWidth        = 40
 	HistoryLimit          = 100
+	StatsHistogramRows    = 20
+	RollLogPanelSize      = 10
 )
 
 // Step constants for charCreateModel
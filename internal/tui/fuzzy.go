@@ -46,33 +46,43 @@ func (d customDelegate) Render(w io.Writer, m list.Model, index int, item list.I
 	}
 }
 
-// newFuzzyModel creates a fuzzy finder model for the given mode.
-func newFuzzyModel(mode string) fuzzyModel {
+// newFuzzyModel creates a fuzzy finder model for the given mode, listing
+// the store's entries.
+func newFuzzyModel(store *data.Store, mode string) fuzzyModel {
 	var titles []string
 
 	switch mode {
 	case "spell":
-		titles = getUniqueTitles(data.AllSpells, func(s data.Spell) string { return s.Name })
+		titles = getUniqueTitles(store.Spells(), func(s data.Spell) string { return s.Name })
 	case "monster":
-		titles = getUniqueTitles(data.AllMonsters, func(m data.Monster) string { return m.Name })
+		titles = getUniqueTitles(store.Monsters(), func(m data.Monster) string { return m.Name })
 	case "item":
-		titles = getUniqueTitles(data.AllItems, func(i data.Item) string { return i.Name })
+		titles = getUniqueTitles(store.Items(), func(i data.Item) string { return i.Name })
 	case "race":
-		titles = getUniqueTitles(data.AllSpecies, func(s data.Species) string { return s.Name })
+		titles = getUniqueTitles(store.Species(), func(s data.Species) string { return s.Name })
 	case "background":
-		titles = getUniqueTitles(data.AllBackgrounds, func(b data.Background) string { return b.Name })
+		titles = getUniqueTitles(store.Backgrounds(), func(b data.Background) string { return b.Name })
 	case "class":
-		titles = getUniqueTitles(data.AllClasses, func(c data.Class) string { return c.Name })
+		titles = getUniqueTitles(store.Classes(), func(c data.Class) string { return c.Name })
+	case "subclass":
+		titles = getUniqueTitles(store.Subclasses(), func(s data.Subclass) string { return s.Name })
+	case "feat":
+		titles = getUniqueTitles(store.Feats(), func(f data.Feat) string { return f.Name })
+	case "condition":
+		titles = getUniqueTitles(store.Conditions(), func(c data.Condition) string { return c.Name })
 	case "rules":
-		titles = []string{"combat", "conditions", "ability checks", "initiative", "actions"}
+		titles = getUniqueTitles(store.Rules(), func(r data.Rule) string { return r.Name })
 	case "global":
-		titles = append(titles, getUniqueTitles(data.AllSpells, func(s data.Spell) string { return "Spell: " + s.Name })...)
-		titles = append(titles, getUniqueTitles(data.AllMonsters, func(m data.Monster) string { return "Monster: " + m.Name })...)
-		titles = append(titles, getUniqueTitles(data.AllItems, func(i data.Item) string { return "Item: " + i.Name })...)
-		titles = append(titles, getUniqueTitles(data.AllSpecies, func(s data.Species) string { return "Race: " + s.Name })...)
-		titles = append(titles, getUniqueTitles(data.AllBackgrounds, func(b data.Background) string { return "Background: " + b.Name })...)
-		titles = append(titles, getUniqueTitles(data.AllClasses, func(c data.Class) string { return "Class: " + c.Name })...)
-		titles = append(titles, "Rules: combat", "Rules: conditions", "Rules: ability checks", "Rules: initiative", "Rules: actions")
+		titles = append(titles, getUniqueTitles(store.Spells(), func(s data.Spell) string { return "Spell: " + s.Name })...)
+		titles = append(titles, getUniqueTitles(store.Monsters(), func(m data.Monster) string { return "Monster: " + m.Name })...)
+		titles = append(titles, getUniqueTitles(store.Items(), func(i data.Item) string { return "Item: " + i.Name })...)
+		titles = append(titles, getUniqueTitles(store.Species(), func(s data.Species) string { return "Race: " + s.Name })...)
+		titles = append(titles, getUniqueTitles(store.Backgrounds(), func(b data.Background) string { return "Background: " + b.Name })...)
+		titles = append(titles, getUniqueTitles(store.Classes(), func(c data.Class) string { return "Class: " + c.Name })...)
+		titles = append(titles, getUniqueTitles(store.Subclasses(), func(s data.Subclass) string { return "Subclass: " + s.Name })...)
+		titles = append(titles, getUniqueTitles(store.Feats(), func(f data.Feat) string { return "Feat: " + f.Name })...)
+		titles = append(titles, getUniqueTitles(store.Conditions(), func(c data.Condition) string { return "Condition: " + c.Name })...)
+		titles = append(titles, getUniqueTitles(store.Rules(), func(r data.Rule) string { return "Rules: " + r.Name })...)
 	}
 
 	items := createListItems(titles)
@@ -85,9 +95,126 @@ func newFuzzyModel(mode string) fuzzyModel {
 	l.Styles.Title = headerStyle
 	l.Styles.FilterPrompt = focusedStyle
 	l.Styles.FilterCursor = cursorStyle
+	switch mode {
+	case "spell":
+		l.Title = "Select spell (filter: fire class:wizard level:1-3 school:evoc ritual conc:no)"
+		l.Filter = spellFilter(store.Spells())
+	case "monster":
+		l.Title = "Select monster (filter: cr:1/4..2 type:undead size:medium env:swamp)"
+		l.Filter = monsterFilter(store.Monsters())
+	case "global":
+		l.Title = "Search everything"
+		l.Filter = globalFilter(store)
+	}
 	return fuzzyModel{list: l, mode: mode, width: DefaultWidth}
 }
 
+// spellFilter filters the spell list with the same queries as 'dnd spells
+// list'.
+func spellFilter(spells []data.Spell) list.FilterFunc {
+	byName := indexByName(spells, func(s *data.Spell) string { return s.Name })
+	return queryFilter(func(term string) (string, func(string) bool, error) {
+		query, err := data.ParseSpellQuery(term)
+		text := query.Text
+		query.Text = ""
+		return text, func(name string) bool { return byName[name] != nil && query.Matches(byName[name]) }, err
+	})
+}
+
+// monsterFilter filters the monster list with the same queries as 'dnd
+// monsters list'.
+func monsterFilter(monsters []data.Monster) list.FilterFunc {
+	byName := indexByName(monsters, func(m *data.Monster) string { return m.Name })
+	return queryFilter(func(term string) (string, func(string) bool, error) {
+		query, err := data.ParseMonsterQuery(term)
+		text := query.Text
+		query.Text = ""
+		return text, func(name string) bool { return byName[name] != nil && query.Matches(byName[name]) }, err
+	})
+}
+
+// globalLabels are the title prefixes of each kind of content in the
+// global search list.
+var globalLabels = map[data.Kind]string{
+	data.KindSpell:      "Spell",
+	data.KindMonster:    "Monster",
+	data.KindItem:       "Item",
+	data.KindSpecies:    "Race",
+	data.KindBackground: "Background",
+	data.KindClass:      "Class",
+	data.KindSubclass:   "Subclass",
+	data.KindFeat:       "Feat",
+	data.KindCondition:  "Condition",
+	data.KindRule:       "Rules",
+}
+
+// globalFilter ranks the global search list with the store's full-text
+// index, best matches first, followed by any other titles that
+// fuzzy-match the filter.
+func globalFilter(store *data.Store) list.FilterFunc {
+	return func(term string, targets []string) []list.Rank {
+		position := make(map[string]int, len(targets))
+		for i, title := range targets {
+			position[title] = i
+		}
+		var ranks []list.Rank
+		seen := make(map[int]bool)
+		for _, hit := range store.Search(term) {
+			if i, ok := position[globalLabels[hit.Kind]+": "+hit.Name]; ok && !seen[i] {
+				ranks = append(ranks, list.Rank{Index: i})
+				seen[i] = true
+			}
+		}
+		for _, r := range list.DefaultFilter(term, targets) {
+			if !seen[r.Index] {
+				ranks = append(ranks, r)
+				seen[r.Index] = true
+			}
+		}
+		return ranks
+	}
+}
+
+// indexByName maps each name to the first entry with that name, matching
+// the titles of getUniqueTitles.
+func indexByName[T any](entries []T, name func(*T) string) map[string]*T {
+	byName := make(map[string]*T, len(entries))
+	for i := range entries {
+		if _, ok := byName[name(&entries[i])]; !ok {
+			byName[name(&entries[i])] = &entries[i]
+		}
+	}
+	return byName
+}
+
+// queryFilter builds a list filter from a query parser. The parser splits
+// the filter text into free words, which fuzzy-match titles, and a
+// condition the titled entry must meet. A filter that does not parse, such
+// as one still being typed, matches nothing.
+func queryFilter(parse func(term string) (string, func(title string) bool, error)) list.FilterFunc {
+	return func(term string, targets []string) []list.Rank {
+		text, matches, err := parse(term)
+		if err != nil {
+			return nil
+		}
+		var ranks []list.Rank
+		if text == "" {
+			for i := range targets {
+				ranks = append(ranks, list.Rank{Index: i})
+			}
+		} else {
+			ranks = list.DefaultFilter(text, targets)
+		}
+		var matched []list.Rank
+		for _, r := range ranks {
+			if matches(targets[r.Index]) {
+				matched = append(matched, r)
+			}
+		}
+		return matched
+	}
+}
+
 func (m fuzzyModel) Init() tea.Cmd {
 	return nil
 }
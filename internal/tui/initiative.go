@@ -2,11 +2,12 @@ package tui
 
 import (
 	"fmt"
-	"math/rand"
 	"sort"
 	"strconv"
 	"strings"
 
+	"dnd-cli/internal/dice"
+
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/textinput"
@@ -31,9 +32,13 @@ type initiativeTracker struct {
 	inputMode  string // "add_name", "add_init", "hp_damage", etc.
 	textInput  textinput.Model
 	selected   int
+	roller     *dice.Roller
 }
 
-func newInitiativeTracker(width, height int) initiativeTracker {
+// initiativeRoll is the d20 rolled when a combatant's initiative is "roll".
+var initiativeRoll = dice.MustParseDiceNotation("1d20")
+
+func newInitiativeTracker(width, height int, roller *dice.Roller) initiativeTracker {
 	ti := textinput.New()
 	ti.Placeholder = "Enter name"
 	ti.Focus()
@@ -51,6 +56,7 @@ func newInitiativeTracker(width, height int) initiativeTracker {
 		inputMode:  InputModeAddName,
 		textInput:  ti,
 		selected:   0,
+		roller:     roller,
 	}
 }
 
@@ -76,7 +82,7 @@ func (m initiativeTracker) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			} else if m.inputMode == InputModeAddInit {
 				input := m.textInput.Value()
 				if input == "roll" {
-					m.combatants[len(m.combatants)-1].init = rand.Intn(20) + 1
+					m.combatants[len(m.combatants)-1].init, _ = initiativeRoll.RollWith(m.roller)
 				} else if init, err := strconv.Atoi(input); err == nil {
 					m.combatants[len(m.combatants)-1].init = init
 				}
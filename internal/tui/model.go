@@ -2,13 +2,13 @@ package tui
 
 import (
 	"fmt"
-	"math/rand"
 	"os"
 	"strings"
-	"time"
 
+	"dnd-cli/internal/config"
 	"dnd-cli/internal/data"
 	"dnd-cli/internal/dice"
+	"dnd-cli/internal/rolllog"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
@@ -17,10 +17,6 @@ import (
 	"github.com/charmbracelet/x/term"
 )
 
-func init() {
-	rand.Seed(time.Now().UnixNano())
-}
-
 // mainModel represents the main command-line interface model.
 // mainModel represents the main command-line interface model.
 type mainModel struct {
@@ -36,6 +32,9 @@ type mainModel struct {
 	status        string
 	currentPrompt string
 	fullScreen    bool
+	roller        *dice.Roller
+	store         *data.Store
+	autoSelect    bool // show the closest match of a lookup with a small typo
 }
 
 // topModel is the top-level model that manages switching between different sub-models.
@@ -43,6 +42,9 @@ type topModel struct {
 	current tea.Model
 	width   int
 	height  int
+	roller     *dice.Roller
+	store      *data.Store
+	autoSelect bool
 }
 
 // setWrappedContent sets the viewport content with word wrapping and optional styling.
@@ -67,7 +69,7 @@ func (m *mainModel) setWrappedContent(content string, style ...lipgloss.Style) {
 }
 
 // newMainModel creates a new instance of the main TUI model with the given dimensions.
-func newMainModel(width, height int) mainModel {
+func newMainModel(width, height int, store *data.Store, roller *dice.Roller, autoSelect bool) mainModel {
 	ti := textinput.New()
 	ti.Placeholder = "Type something..."
 	ti.Focus()
@@ -105,12 +107,18 @@ func newMainModel(width, height int) mainModel {
 		status:        "Ready. Type 'help' or '?' for commands.",
 		currentPrompt: getRandomPrompt(),
 		fullScreen:    false,
+		roller:        roller,
+		store:         store,
+		autoSelect:    autoSelect,
 	}
 }
 
 // NewModel creates the top-level TUI model with initial dimensions.
-func NewModel(width, height int) topModel {
-	return topModel{current: newMainModel(width, height), width: width, height: height}
+// Content is looked up in store, and all dice rolls made in the TUI draw
+// from roller. With autoSelect, a lookup with a small typo shows the
+// closest match instead of suggestions.
+func NewModel(width, height int, store *data.Store, roller *dice.Roller, autoSelect bool) topModel {
+	return topModel{current: newMainModel(width, height, store, roller, autoSelect), width: width, height: height, roller: roller, store: store, autoSelect: autoSelect}
 }
 
 // getHelpText returns a formatted help text for the TUI.
@@ -118,18 +126,32 @@ func getHelpText() string {
 	return `Available Commands:
 
 Core Commands:
-   roll <notation>     - Roll dice (e.g., roll 1d20, roll 2d6+3)
+   roll <notation> [crit]
+                       - Roll dice (e.g., roll 1d20+7 vs 15, roll 10d6>=5, roll 2d6+3 crit)
+   roll @<macro>       - Roll a saved macro (see 'dnd macro add')
+                         Add --label <label> or --char <name> to record them with
+                         the roll (e.g., roll 1d20+5 --label stealth --char Vex);
+                         macros take --char only
+   rolls [n] [--session <n|last>] [--date <YYYY-MM-DD>] [--char <name>]
+                       - Show the last n logged rolls (default 10), filtered
+                         as 'dnd roll history' does (e.g., rolls 20 --session last)
+   stats <notation> [adv|dis] [dc <n>]
+                       - Show the odds of a roll (e.g., stats 1d20+5 adv dc 15)
 
  Lookup Commands:
-     search [query]      - Global fuzzy search across all categories
-                           (spells, monsters, items, races, backgrounds, classes, rules)
+     search [query]      - Full-text search across all categories
+                           (spells, monsters, items, races, backgrounds, classes,
+                            subclasses, feats, conditions, rules)
      spell [name]        - Browse/filter spell list or look up specific spell
      monster [name]      - Browse/filter monster list or look up specific monster
      item [name]         - Browse/filter item list or look up specific item
      race [name]         - Browse/filter race list or look up specific race
      background [name]   - Browse/filter background list or look up specific background
      class [name]        - Browse/filter class list or look up specific class
-     rules [topic]       - Look up PHB rules (combat, conditions, ability checks, etc.)
+     subclass [name]     - Browse/filter subclass list or look up specific subclass
+     feat [name]         - Browse/filter feat list or look up specific feat
+     condition [name]    - Browse/filter condition list or look up specific condition
+     rules [section]     - Look up rules sections (combat, cover, resting, etc.)
 
  Character Management (Full PHB Support):
     char create         - Create a new character interactively in TUI
@@ -235,124 +257,138 @@ func (m mainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				cmd := args[0]
 				switch cmd {
 				case "roll":
-					if len(args) < 2 {
-						m.setWrappedContent("Usage: roll <notation> (e.g., roll 1d20, roll 2d6+3)")
+					rollArgs, label, char, tagErr := splitRollTags(args[1:])
+					if tagErr != nil {
+						m.setWrappedContent(fmt.Sprintf("Error: %v", tagErr), errorStyle)
+					} else if len(rollArgs) < 1 {
+						m.setWrappedContent("Usage: roll <notation> [crit] [--label <label>] [--char <name>] (e.g., roll 1d20, roll 2d6+3 crit)")
+					} else if strings.HasPrefix(rollArgs[0], "@") && label != "" {
+						m.setWrappedContent("Error: macro rolls are labelled by the macro; only --char can be added", errorStyle)
+					} else if strings.HasPrefix(rollArgs[0], "@") {
+						name := strings.ToLower(strings.TrimPrefix(rollArgs[0], "@"))
+						if cfg, err := config.Load(); err != nil {
+							m.setWrappedContent(fmt.Sprintf("Error: %v", err), errorStyle)
+						} else if macro, ok := cfg.Macros[name]; !ok {
+							m.setWrappedContent(fmt.Sprintf("Error: no macro named '%s' (add one with 'dnd macro add')", name), errorStyle)
+						} else if content, entries, err := macro.Roll(name, m.roller); err != nil {
+							m.setWrappedContent(fmt.Sprintf("Error: %v", err), errorStyle)
+						} else {
+							m.setWrappedContent(content, rollStyle)
+							for i := range entries {
+								entries[i].Character = char
+							}
+							if err := recordRolls(entries...); err != nil {
+								m.status = fmt.Sprintf("Could not record rolls: %v", err)
+							}
+						}
 					} else {
-						notation := args[1]
-						dr, err := dice.ParseDiceNotation(notation)
+						isCrit := len(rollArgs) > 1 && strings.EqualFold(rollArgs[len(rollArgs)-1], "crit")
+						if isCrit {
+							rollArgs = rollArgs[:len(rollArgs)-1]
+						}
+						dr, err := dice.ParseDiceNotation(strings.Join(rollArgs, " "))
+						if err == nil && isCrit {
+							dr, err = dr.WithCrit(dice.CritDoubleDice)
+						}
 						if err != nil {
 							m.setWrappedContent(fmt.Sprintf("Error: %v", err), errorStyle)
 						} else {
-							total, rolls := dr.Roll()
-							content := fmt.Sprintf("Rolling %s\n\nRolls: %v\n\nTotal: %d", dr.Notation, rolls, total)
+							total, terms := dr.RollWith(m.roller)
+							content := fmt.Sprintf("Rolling %s\n\n%s\n\nTotal: %d", dr.Notation, dice.FormatTerms(terms), total)
+							if check := dr.Check(total, terms).String(); check != "" {
+								content += "\n" + check
+							}
 							m.setWrappedContent(content, rollStyle)
+							entry := rolllog.NewEntry(dr, total, terms)
+							entry.Label, entry.Character = label, char
+							if err := recordRolls(entry); err != nil {
+								m.status = fmt.Sprintf("Could not record roll: %v", err)
+							}
 						}
 					}
+				case "rolls":
+					if content, err := getRollLogContent(args[1:]); err != nil {
+						m.setWrappedContent(fmt.Sprintf("Error: %v", err), errorStyle)
+					} else {
+						m.setWrappedContent(content, rollStyle)
+					}
+				case "stats":
+					if len(args) < 2 {
+						m.setWrappedContent("Usage: stats <notation> [adv|dis] [dc <n>] (e.g., stats 1d20+5 adv dc 15)")
+					} else if content, err := getStatsContent(args[1:], m.viewport.Width); err != nil {
+						m.setWrappedContent(fmt.Sprintf("Error: %v", err), errorStyle)
+					} else {
+						m.setWrappedContent(content, rollStyle)
+					}
 				case "spell":
 					if len(args) < 2 {
 						m.textInput.SetValue("")
 						return m, func() tea.Msg { return switchModeMsg{"fuzzy_spell"} }
 					} else {
-						name := strings.Join(args[1:], " ")
-						spell, err := data.GetSpellByName(name)
-						if err != nil {
-							m.setWrappedContent(getRandomSpellErrorMessage(name), errorStyle)
-						} else {
-							content := fmt.Sprintf("--- %s ---\n\n", spell.Name)
-							order := []string{"Level", "School", "Casting Time", "Range", "Components", "Duration"}
-							content += renderPropertiesTable(spell.Properties, order)
-							content += fmt.Sprintf("\n\nDescription:\n%s\n\n", formatDescription(spell.Description))
-							content += fmt.Sprintf("Source: %s (%s)\n", spell.Book, spell.Publisher)
-							m.setWrappedContent(content, infoCardStyle)
-						}
+						displayItem(&m, "spell", strings.Join(args[1:], " "))
 					}
 				case "monster":
 					if len(args) < 2 {
 						m.textInput.SetValue("")
 						return m, func() tea.Msg { return switchModeMsg{"fuzzy_monster"} }
 					} else {
-						name := strings.Join(args[1:], " ")
-						monster, err := data.GetMonsterByName(name)
-						if err != nil {
-							m.setWrappedContent(getRandomMonsterErrorMessage(name), errorStyle)
-						} else {
-							content := fmt.Sprintf("--- %s ---\n\n", monster.Name)
-							content += fmt.Sprintf("Description:\n%s\n", formatDescription(monster.Description))
-							m.setWrappedContent(content, infoCardStyle)
-						}
+						displayItem(&m, "monster", strings.Join(args[1:], " "))
 					}
 				case "item":
 					if len(args) < 2 {
 						m.textInput.SetValue("")
 						return m, func() tea.Msg { return switchModeMsg{"fuzzy_item"} }
 					} else {
-						name := strings.Join(args[1:], " ")
-						it, err := data.GetItemByName(name)
-						if err != nil {
-							m.setWrappedContent(getRandomItemErrorMessage(name), errorStyle)
-						} else {
-							content := fmt.Sprintf("--- %s ---\n\n", it.Name)
-							content += fmt.Sprintf("Description:\n%s\n", formatDescription(it.Description))
-							m.setWrappedContent(content, infoCardStyle)
-						}
+						displayItem(&m, "item", strings.Join(args[1:], " "))
 					}
 				case "race":
 					if len(args) < 2 {
 						m.textInput.SetValue("")
 						return m, func() tea.Msg { return switchModeMsg{"fuzzy_race"} }
 					} else {
-						name := strings.Join(args[1:], " ")
-						species, err := data.GetSpeciesByName(name)
-						if err != nil {
-							m.setWrappedContent(getRandomSpeciesErrorMessage(name), errorStyle)
-						} else {
-							content := fmt.Sprintf("--- %s ---\n\n", species.Name)
-							content += fmt.Sprintf("Description:\n%s\n", formatDescription(species.Description))
-							m.setWrappedContent(content, infoCardStyle)
-						}
+						displayItem(&m, "race", strings.Join(args[1:], " "))
 					}
 				case "background":
 					if len(args) < 2 {
 						m.textInput.SetValue("")
 						return m, func() tea.Msg { return switchModeMsg{"fuzzy_background"} }
 					} else {
-						name := strings.Join(args[1:], " ")
-						background, err := data.GetBackgroundByName(name)
-						if err != nil {
-							m.setWrappedContent(getRandomBackgroundErrorMessage(name), errorStyle)
-						} else {
-							content := fmt.Sprintf("--- %s ---\n\n", background.Name)
-							content += fmt.Sprintf("Description:\n%s\n", formatDescription(background.Description))
-							m.setWrappedContent(content, infoCardStyle)
-						}
+						displayItem(&m, "background", strings.Join(args[1:], " "))
 					}
 				case "class":
 					if len(args) < 2 {
 						m.textInput.SetValue("")
 						return m, func() tea.Msg { return switchModeMsg{"fuzzy_class"} }
 					} else {
-						name := strings.Join(args[1:], " ")
-						class, err := data.GetClassByName(name)
-						if err != nil {
-							m.setWrappedContent(getRandomClassErrorMessage(name), errorStyle)
-						} else {
-							content := fmt.Sprintf("--- %s ---\n\n", class.Name)
-							content += fmt.Sprintf("Description:\n%s\n", formatDescription(class.Description))
-							m.setWrappedContent(content, infoCardStyle)
-						}
+						displayItem(&m, "class", strings.Join(args[1:], " "))
+					}
+				case "subclass":
+					if len(args) < 2 {
+						m.textInput.SetValue("")
+						return m, func() tea.Msg { return switchModeMsg{"fuzzy_subclass"} }
+					} else {
+						displayItem(&m, "subclass", strings.Join(args[1:], " "))
+					}
+				case "feat":
+					if len(args) < 2 {
+						m.textInput.SetValue("")
+						return m, func() tea.Msg { return switchModeMsg{"fuzzy_feat"} }
+					} else {
+						displayItem(&m, "feat", strings.Join(args[1:], " "))
+					}
+				case "condition":
+					if len(args) < 2 {
+						m.textInput.SetValue("")
+						return m, func() tea.Msg { return switchModeMsg{"fuzzy_condition"} }
+					} else {
+						displayItem(&m, "condition", strings.Join(args[1:], " "))
 					}
-				case "rules":
+				case "rules", "rule":
 					if len(args) < 2 {
 						m.textInput.SetValue("")
 						return m, func() tea.Msg { return switchModeMsg{"fuzzy_rules"} }
 					} else {
-						topic := strings.ToLower(strings.Join(args[1:], " "))
-						if desc, ok := rules[topic]; ok {
-							content := fmt.Sprintf("--- %s ---\n\n%s", strings.Title(topic), formatDescription(desc))
-							m.setWrappedContent(content, infoCardStyle)
-						} else {
-							m.setWrappedContent(fmt.Sprintf("Hark! No rules for '%s'. Available: combat, conditions, ability checks, initiative, actions.", topic), errorStyle)
-						}
+						displayItem(&m, "rules", strings.Join(args[1:], " "))
 					}
 				case "search":
 					m.textInput.SetValue("")
@@ -362,7 +398,7 @@ func (m mainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return m, func() tea.Msg { return switchModeMsg{"initiative_tracker"} }
 				case "npc":
 					if len(args) < 2 || args[1] == "generate" {
-						npc := data.GenerateNPC()
+						npc := m.store.GenerateNPCWith(m.roller)
 						content := fmt.Sprintf("--- Generated NPC ---\n\nName: %s\nSpecies: %s\nBackground: %s\n\nPersonality Trait: %s\n\nIdeal: %s\n\nBond: %s\n\nFlaw: %s\n\nBackstory: %s\n", npc.Name, npc.Species, npc.Background, npc.PersonalityTrait, npc.Ideal, npc.Bond, npc.Flaw, npc.Backstory)
 						m.setWrappedContent(infoCardStyle.Render(content))
 					} else {
@@ -461,49 +497,61 @@ func (m topModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case switchModeMsg:
 		switch msg.mode {
 		case "main":
-			mm := newMainModel(m.width, m.height)
+			mm := newMainModel(m.width, m.height, m.store, m.roller, m.autoSelect)
 			m.current = mm
 		case "char_create":
-			m.current = newCharCreateModel(m.width, m.height)
+			m.current = newCharCreateModel(m.width, m.height, m.store, m.roller)
 		case "fuzzy_spell":
-			fm := newFuzzyModel("spell")
+			fm := newFuzzyModel(m.store, "spell")
 			fm.list.SetSize(m.width, m.height-2)
 			m.current = fm
 		case "fuzzy_monster":
-			fm := newFuzzyModel("monster")
+			fm := newFuzzyModel(m.store, "monster")
 			fm.list.SetSize(m.width, m.height-2)
 			m.current = fm
 		case "fuzzy_item":
-			fm := newFuzzyModel("item")
+			fm := newFuzzyModel(m.store, "item")
 			fm.list.SetSize(m.width, m.height-2)
 			m.current = fm
 		case "fuzzy_race":
-			fm := newFuzzyModel("race")
+			fm := newFuzzyModel(m.store, "race")
 			fm.list.SetSize(m.width, m.height-2)
 			m.current = fm
 		case "fuzzy_background":
-			fm := newFuzzyModel("background")
+			fm := newFuzzyModel(m.store, "background")
 			fm.list.SetSize(m.width, m.height-2)
 			m.current = fm
 		case "fuzzy_class":
-			fm := newFuzzyModel("class")
+			fm := newFuzzyModel(m.store, "class")
+			fm.list.SetSize(m.width, m.height-2)
+			m.current = fm
+		case "fuzzy_subclass":
+			fm := newFuzzyModel(m.store, "subclass")
+			fm.list.SetSize(m.width, m.height-2)
+			m.current = fm
+		case "fuzzy_feat":
+			fm := newFuzzyModel(m.store, "feat")
+			fm.list.SetSize(m.width, m.height-2)
+			m.current = fm
+		case "fuzzy_condition":
+			fm := newFuzzyModel(m.store, "condition")
 			fm.list.SetSize(m.width, m.height-2)
 			m.current = fm
 		case "fuzzy_rules":
-			fm := newFuzzyModel("rules")
+			fm := newFuzzyModel(m.store, "rules")
 			fm.list.SetSize(m.width, m.height-2)
 			m.current = fm
 		case "fuzzy_global":
-			fm := newFuzzyModel("global")
+			fm := newFuzzyModel(m.store, "global")
 			fm.list.SetSize(m.width, m.height-2)
 			m.current = fm
 		case "initiative_tracker":
-			it := newInitiativeTracker(m.width, m.height)
+			it := newInitiativeTracker(m.width, m.height, m.roller)
 			m.current = it
 		}
 		return m, nil
 	case selectedMsg:
-		mm := newMainModel(m.width, m.height)
+		mm := newMainModel(m.width, m.height, m.store, m.roller, m.autoSelect)
 		if msg.mode == "global" {
 			// Parse "Category: Name"
 			parts := strings.SplitN(msg.name, ": ", 2)
@@ -596,72 +644,129 @@ type selectedMsg struct {
 }
 
 // displayItem displays the content for a given category and name in the main model.
-// It handles fetching data and formatting for spells, monsters, items, races, backgrounds, classes, and rules.
+// It handles fetching data and formatting for spells, monsters, items, races, backgrounds, classes,
+// subclasses, feats, conditions and rules.
 func displayItem(mm *mainModel, category, name string) {
 	switch category {
 	case "spell":
-		spell, err := data.GetSpellByName(name)
+		spell, err := mm.store.GetSpellByName(name)
+		if closest, ok := mm.closestName(err); ok {
+			spell, err = mm.store.GetSpellByName(closest)
+		}
 		if err != nil {
-			mm.setWrappedContent(getRandomSpellErrorMessage(name), errorStyle)
+			mm.setWrappedContent(getRandomSpellErrorMessage(name, err), errorStyle)
 		} else {
 			content := fmt.Sprintf("--- %s ---\n\n", spell.Name)
-			order := []string{"Level", "School", "Casting Time", "Range", "Components", "Duration"}
-			content += renderPropertiesTable(spell.Properties, order)
-			content += fmt.Sprintf("\n\nDescription:\n%s\n\n", formatDescription(spell.Description))
-			content += fmt.Sprintf("Source: %s (%s)\n", spell.Book, spell.Publisher)
+			content += spell.Card()
 			mm.setWrappedContent(content, infoCardStyle)
 		}
 	case "monster":
-		monster, err := data.GetMonsterByName(name)
+		monster, err := mm.store.GetMonsterByName(name)
+		if closest, ok := mm.closestName(err); ok {
+			monster, err = mm.store.GetMonsterByName(closest)
+		}
 		if err != nil {
-			mm.setWrappedContent(getRandomMonsterErrorMessage(name), errorStyle)
+			mm.setWrappedContent(getRandomMonsterErrorMessage(name, err), errorStyle)
 		} else {
 			content := fmt.Sprintf("--- %s ---\n\n", monster.Name)
-			content += fmt.Sprintf("Description:\n%s\n", formatDescription(monster.Description))
+			content += monster.StatBlock()
 			mm.setWrappedContent(content, infoCardStyle)
 		}
 	case "item":
-		it, err := data.GetItemByName(name)
+		it, err := mm.store.GetItemByName(name)
+		if closest, ok := mm.closestName(err); ok {
+			it, err = mm.store.GetItemByName(closest)
+		}
 		if err != nil {
-			mm.setWrappedContent(getRandomItemErrorMessage(name), errorStyle)
+			mm.setWrappedContent(getRandomItemErrorMessage(name, err), errorStyle)
 		} else {
 			content := fmt.Sprintf("--- %s ---\n\n", it.Name)
-			content += fmt.Sprintf("Description:\n%s\n", formatDescription(it.Description))
+			content += it.Card()
 			mm.setWrappedContent(content, infoCardStyle)
 		}
 	case "race":
-		species, err := data.GetSpeciesByName(name)
+		species, err := mm.store.GetSpeciesByName(name)
+		if closest, ok := mm.closestName(err); ok {
+			species, err = mm.store.GetSpeciesByName(closest)
+		}
 		if err != nil {
-			mm.setWrappedContent(getRandomSpeciesErrorMessage(name), errorStyle)
+			mm.setWrappedContent(getRandomSpeciesErrorMessage(name, err), errorStyle)
 		} else {
 			content := fmt.Sprintf("--- %s ---\n\n", species.Name)
 			content += fmt.Sprintf("Description:\n%s\n", formatDescription(species.Description))
 			mm.setWrappedContent(content, infoCardStyle)
 		}
 	case "background":
-		background, err := data.GetBackgroundByName(name)
+		background, err := mm.store.GetBackgroundByName(name)
+		if closest, ok := mm.closestName(err); ok {
+			background, err = mm.store.GetBackgroundByName(closest)
+		}
 		if err != nil {
-			mm.setWrappedContent(getRandomBackgroundErrorMessage(name), errorStyle)
+			mm.setWrappedContent(getRandomBackgroundErrorMessage(name, err), errorStyle)
 		} else {
 			content := fmt.Sprintf("--- %s ---\n\n", background.Name)
 			content += fmt.Sprintf("Description:\n%s\n", formatDescription(background.Description))
 			mm.setWrappedContent(content, infoCardStyle)
 		}
 	case "class":
-		class, err := data.GetClassByName(name)
+		class, err := mm.store.GetClassByName(name)
+		if closest, ok := mm.closestName(err); ok {
+			class, err = mm.store.GetClassByName(closest)
+		}
 		if err != nil {
-			mm.setWrappedContent(getRandomClassErrorMessage(name), errorStyle)
+			mm.setWrappedContent(getRandomClassErrorMessage(name, err), errorStyle)
 		} else {
 			content := fmt.Sprintf("--- %s ---\n\n", class.Name)
 			content += fmt.Sprintf("Description:\n%s\n", formatDescription(class.Description))
 			mm.setWrappedContent(content, infoCardStyle)
 		}
-	case "rules":
-		if desc, ok := rules[name]; ok {
-			content := fmt.Sprintf("--- %s ---\n\n%s", strings.Title(name), formatDescription(desc))
+	case "subclass":
+		subclass, err := mm.store.GetSubclassByName(name)
+		if closest, ok := mm.closestName(err); ok {
+			subclass, err = mm.store.GetSubclassByName(closest)
+		}
+		if err != nil {
+			mm.setWrappedContent(getLookupErrorMessage(data.KindSubclass, name, err), errorStyle)
+		} else {
+			content := fmt.Sprintf("--- %s ---\n\n", subclass.Name)
+			content += subclass.Card()
+			mm.setWrappedContent(content, infoCardStyle)
+		}
+	case "feat":
+		feat, err := mm.store.GetFeatByName(name)
+		if closest, ok := mm.closestName(err); ok {
+			feat, err = mm.store.GetFeatByName(closest)
+		}
+		if err != nil {
+			mm.setWrappedContent(getLookupErrorMessage(data.KindFeat, name, err), errorStyle)
+		} else {
+			content := fmt.Sprintf("--- %s ---\n\n", feat.Name)
+			content += feat.Card()
+			mm.setWrappedContent(content, infoCardStyle)
+		}
+	case "condition":
+		condition, err := mm.store.GetConditionByName(name)
+		if closest, ok := mm.closestName(err); ok {
+			condition, err = mm.store.GetConditionByName(closest)
+		}
+		if err != nil {
+			mm.setWrappedContent(getLookupErrorMessage(data.KindCondition, name, err), errorStyle)
+		} else {
+			content := fmt.Sprintf("--- %s ---\n\n", condition.Name)
+			content += condition.Card()
 			mm.setWrappedContent(content, infoCardStyle)
+		}
+	case "rules":
+		rule, err := mm.store.GetRuleByName(name)
+		if closest, ok := mm.closestName(err); ok {
+			rule, err = mm.store.GetRuleByName(closest)
+		}
+		if err != nil {
+			mm.setWrappedContent(getLookupErrorMessage(data.KindRule, name, err), errorStyle)
 		} else {
-			mm.setWrappedContent("Unknown rules topic.", errorStyle)
+			content := fmt.Sprintf("--- %s ---\n\n", rule.Name)
+			content += rule.Card()
+			mm.setWrappedContent(content, infoCardStyle)
 		}
 	default:
 		mm.setWrappedContent("Unknown category.", errorStyle)
@@ -671,17 +776,21 @@ func displayItem(mm *mainModel, category, name string) {
 // errMsg is a custom error type for our TUI (currently unused).
 type errMsg error
 
-// StartTUI runs the Bubble Tea application for the D&D CLI TUI.
-func StartTUI() {
+// StartTUI runs the Bubble Tea application for the D&D CLI TUI, looking
+// content up in store and rolling dice with the given roller.
+func StartTUI(store *data.Store, roller *dice.Roller) {
 	width, height, err := term.GetSize(uintptr(os.Stdout.Fd()))
 	if err != nil {
 		width = DefaultWidth
 		height = DefaultHeight
 	}
 
-	config := LoadConfig()
-	ApplyTheme(config.Theme)
-	p := tea.NewProgram(NewModel(width, height))
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Hark! Thy tome of settings cannot be read, so the defaults shall serve: %v\n", err)
+	}
+	ApplyTheme(cfg.Theme)
+	p := tea.NewProgram(NewModel(width, height, store, roller, cfg.AutoSelect))
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Alas, there's been an error: %v", err)
 		os.Exit(1)
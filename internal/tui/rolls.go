@@ -0,0 +1,126 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"dnd-cli/internal/rolllog"
+)
+
+// recordRolls appends rolls made in the TUI to the roll log.
+func recordRolls(entries ...rolllog.Entry) error {
+	path, err := rolllog.DefaultPath()
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		e.Source = "tui"
+		if err := rolllog.Append(path, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitRollTags takes the label and character options of a TUI roll,
+// "--label <label>" and "--char <name>" (or -l and -c) as in 'dnd roll', off
+// its arguments.
+func splitRollTags(args []string) (rest []string, label, char string, err error) {
+	for i := 0; i < len(args); i++ {
+		var tag *string
+		switch args[i] {
+		case "--label", "-l":
+			tag = &label
+		case "--char", "-c":
+			tag = &char
+		default:
+			rest = append(rest, args[i])
+			continue
+		}
+		if i+1 == len(args) {
+			return nil, "", "", fmt.Errorf("%s needs a value", args[i])
+		}
+		i++
+		*tag = args[i]
+	}
+	return rest, label, char, nil
+}
+
+// getRollLogContent renders the panel for "rolls [n] [--session <n|last>]
+// [--date <YYYY-MM-DD>] [--char <name>]": the last n rolls of the roll log
+// that match the filters, as 'dnd roll history' shows them.
+func getRollLogContent(args []string) (string, error) {
+	n := RollLogPanelSize
+	var session, date, char string
+	for i := 0; i < len(args); i++ {
+		var option *string
+		switch args[i] {
+		case "--session", "-s":
+			option = &session
+		case "--date":
+			option = &date
+		case "--char", "-c":
+			option = &char
+		default:
+			v, err := strconv.Atoi(args[i])
+			if err != nil || v < 1 {
+				return "", fmt.Errorf("invalid number of rolls %q", args[i])
+			}
+			n = v
+			continue
+		}
+		if i+1 == len(args) {
+			return "", fmt.Errorf("%s needs a value", args[i])
+		}
+		i++
+		*option = args[i]
+	}
+
+	path, err := rolllog.DefaultPath()
+	if err != nil {
+		return "", err
+	}
+	entries, bad, err := rolllog.Load(path)
+	if err != nil {
+		return "", err
+	}
+	filter := rolllog.Filter{Character: char, Last: n}
+	var scope []string
+	if session != "" {
+		if filter.Session, err = rolllog.ParseSession(session, entries); err != nil {
+			return "", err
+		}
+		scope = append(scope, fmt.Sprintf("session %d", filter.Session))
+	}
+	if date != "" {
+		if filter.Since, filter.Until, err = rolllog.ParseDay(date); err != nil {
+			return "", err
+		}
+		scope = append(scope, date)
+	}
+	if char != "" {
+		scope = append(scope, char)
+	}
+	entries = filter.Apply(entries)
+	if len(entries) == 0 {
+		if len(scope) > 0 {
+			return "No rolls recorded yet that match.", nil
+		}
+		return "No rolls recorded yet.", nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Last %d rolls", len(entries))
+	if len(scope) > 0 {
+		fmt.Fprintf(&b, " (%s)", strings.Join(scope, ", "))
+	}
+	b.WriteString("\n\n")
+	for i := len(entries) - 1; i >= 0; i-- {
+		fmt.Fprintln(&b, entries[i])
+	}
+	if len(bad) > 0 {
+		fmt.Fprintf(&b, "\n%d unreadable lines of the roll log were skipped.\n", len(bad))
+	}
+	return strings.TrimRight(b.String(), "\n"), nil
+}
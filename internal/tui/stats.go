@@ -0,0 +1,61 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"dnd-cli/internal/dice"
+)
+
+// getStatsContent renders the odds panel for "stats <notation> [adv|dis] [dc N]".
+func getStatsContent(args []string, width int) (string, error) {
+	dc, hasDC := 0, false
+	if n := len(args); n >= 2 && strings.EqualFold(args[n-2], "dc") {
+		v, err := strconv.Atoi(args[n-1])
+		if err != nil {
+			return "", fmt.Errorf("invalid DC %q", args[n-1])
+		}
+		dc, hasDC = v, true
+		args = args[:n-2]
+	}
+	mode := ""
+	if n := len(args); n >= 1 && (strings.EqualFold(args[n-1], "adv") || strings.EqualFold(args[n-1], "dis")) {
+		mode = strings.ToLower(args[n-1])
+		args = args[:n-1]
+	}
+	if len(args) == 0 {
+		return "", fmt.Errorf("missing dice notation")
+	}
+
+	dr, err := dice.ParseDiceNotation(strings.Join(args, " "))
+	if err != nil {
+		return "", err
+	}
+	switch mode {
+	case "adv":
+		dr, err = dr.WithAdvantage()
+	case "dis":
+		dr, err = dr.WithDisadvantage()
+	}
+	if err != nil {
+		return "", err
+	}
+	dist, err := dr.Distribution()
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Odds for %s\n\n", dr.Notation)
+	fmt.Fprintf(&b, "Min: %d  Max: %d  Mean: %.2f  Std Dev: %.2f\n", dist.Min, dist.Max(), dist.Mean(), dist.StdDev())
+	if hasDC {
+		fmt.Fprintf(&b, "P(≥ %d): %.2f%%\n", dc, dist.AtLeast(dc)*100)
+	}
+	barWidth := width - 30
+	if barWidth < 10 {
+		barWidth = 10
+	}
+	fmt.Fprintf(&b, "\n%s", dist.Histogram(StatsHistogramRows, barWidth))
+	return b.String(), nil
+}
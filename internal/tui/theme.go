@@ -0,0 +1,24 @@
+package tui
+
+import (
+	"dnd-cli/internal/config"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ApplyTheme applies the theme to global styles.
+func ApplyTheme(theme config.Theme) {
+	focusedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(theme.FocusedColor))
+	blurStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(theme.BlurColor))
+	cursorStyle = focusedStyle
+	promptStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(theme.PromptColor)).Bold(true)
+	quitStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(theme.QuitColor)).Italic(true)
+	outputStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(theme.OutputColor)).Padding(0, 1)
+	errorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(theme.ErrorColor)).Padding(0, 1)
+	selectedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(theme.SelectedColor)).Bold(true)
+	unselectedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(theme.UnselectedColor))
+	infoCardStyle = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(1).BorderForeground(lipgloss.Color(theme.InfoCardColor))
+	rollStyle = lipgloss.NewStyle().Border(lipgloss.NormalBorder()).Padding(1).BorderForeground(lipgloss.Color(theme.RollColor))
+	headerStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(theme.HeaderColor)).Background(lipgloss.Color(theme.HeaderColor)).Padding(0, 1).Bold(true)
+	viewStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(theme.OutputColor))
+}
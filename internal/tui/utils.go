@@ -1,12 +1,14 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
 	"math/rand"
 	"strings"
 
+	"dnd-cli/internal/data"
+
 	"github.com/charmbracelet/bubbles/list"
-	"github.com/charmbracelet/lipgloss"
 )
 
 // getRandomMessage returns a random message from the given slice.
@@ -19,34 +21,65 @@ func getRandomErrorMessage() string {
 	return getRandomMessage(errorMessages)
 }
 
-// getRandomSpellErrorMessage returns a random spell error message.
-func getRandomSpellErrorMessage(name string) string {
-	return fmt.Sprintf(getRandomMessage(spellErrorMessages), name)
+// getRandomSpellErrorMessage returns a random spell error message, followed by
+// the suggestions of the lookup error.
+func getRandomSpellErrorMessage(name string, err error) string {
+	return withSuggestions(fmt.Sprintf(getRandomMessage(spellErrorMessages), name), err)
+}
+
+// getRandomMonsterErrorMessage returns a random monster error message, followed by
+// the suggestions of the lookup error.
+func getRandomMonsterErrorMessage(name string, err error) string {
+	return withSuggestions(fmt.Sprintf(getRandomMessage(monsterErrorMessages), name), err)
 }
 
-// getRandomMonsterErrorMessage returns a random monster error message.
-func getRandomMonsterErrorMessage(name string) string {
-	return fmt.Sprintf(getRandomMessage(monsterErrorMessages), name)
+// getRandomItemErrorMessage returns a random item error message, followed by
+// the suggestions of the lookup error.
+func getRandomItemErrorMessage(name string, err error) string {
+	return withSuggestions(fmt.Sprintf(getRandomMessage(itemErrorMessages), name), err)
 }
 
-// getRandomItemErrorMessage returns a random item error message.
-func getRandomItemErrorMessage(name string) string {
-	return fmt.Sprintf(getRandomMessage(itemErrorMessages), name)
+// getRandomSpeciesErrorMessage returns a random species error message, followed by
+// the suggestions of the lookup error.
+func getRandomSpeciesErrorMessage(name string, err error) string {
+	return withSuggestions(fmt.Sprintf(getRandomMessage(speciesErrorMessages), name), err)
 }
 
-// getRandomSpeciesErrorMessage returns a random species error message.
-func getRandomSpeciesErrorMessage(name string) string {
-	return fmt.Sprintf(getRandomMessage(speciesErrorMessages), name)
+// getRandomBackgroundErrorMessage returns a random background error message, followed by
+// the suggestions of the lookup error.
+func getRandomBackgroundErrorMessage(name string, err error) string {
+	return withSuggestions(fmt.Sprintf(getRandomMessage(backgroundErrorMessages), name), err)
 }
 
-// getRandomBackgroundErrorMessage returns a random background error message.
-func getRandomBackgroundErrorMessage(name string) string {
-	return fmt.Sprintf(getRandomMessage(backgroundErrorMessages), name)
+// getRandomClassErrorMessage returns a random class error message, followed by
+// the suggestions of the lookup error.
+func getRandomClassErrorMessage(name string, err error) string {
+	return withSuggestions(fmt.Sprintf(getRandomMessage(classErrorMessages), name), err)
 }
 
-// getRandomClassErrorMessage returns a random class error message.
-func getRandomClassErrorMessage(name string) string {
-	return fmt.Sprintf(getRandomMessage(classErrorMessages), name)
+// getLookupErrorMessage returns the error message of a failed lookup of a
+// kind of content without messages of its own, followed by the suggestions
+// of the lookup error.
+func getLookupErrorMessage(kind data.Kind, name string, err error) string {
+	return withSuggestions(fmt.Sprintf("Hark! No %s named '%s' is written in my tomes.", kind, name), err)
+}
+
+// withSuggestions appends the "did you mean" line of a failed lookup to msg.
+func withSuggestions(msg string, err error) string {
+	var notFound *data.NotFoundError
+	if errors.As(err, &notFound) && len(notFound.Suggestions) > 0 {
+		return msg + "\n\n" + notFound.DidYouMean()
+	}
+	return msg
+}
+
+// closestName returns the name to retry a failed lookup with when the
+// auto_select setting is on.
+func (m *mainModel) closestName(err error) (string, bool) {
+	if !m.autoSelect {
+		return "", false
+	}
+	return data.ClosestMatch(err)
 }
 
 // getRandomPrompt returns a random prompt message.
@@ -94,17 +127,3 @@ func formatDescription(desc string) string {
 	}
 	return desc
 }
-
-// renderPropertiesTable renders a simple table for key-value pairs.
-func renderPropertiesTable(properties map[string]interface{}, order []string) string {
-	var rows []string
-	for _, key := range order {
-		if v, ok := properties[key]; ok {
-			keyStyled := lipgloss.NewStyle().Bold(true).Render(key + ":")
-			valueStyled := outputStyle.Render(fmt.Sprintf("%v", v))
-			row := lipgloss.JoinHorizontal(lipgloss.Top, keyStyled, valueStyled)
-			rows = append(rows, row)
-		}
-	}
-	return lipgloss.JoinVertical(lipgloss.Left, rows...)
-}
@@ -3,8 +3,11 @@ Copyright © 2025
 */
 package main
 
-import "dnd-cli/cmd"
+import (
+	"dnd-cli/cmd"
+	"dnd-cli/internal/data"
+)
 
 func main() {
-	cmd.Execute()
+	cmd.Execute(data.NewStore())
 }